@@ -0,0 +1,322 @@
+/*
+Package builder builds Avro schemas in code.
+
+Schemas are described with chained calls and built in one step, which reports all
+errors found at once:
+
+	schema, err := builder.Record("User").
+		Namespace("org.hamba").
+		Doc("A user.").
+		Field("id", builder.Long()).
+		OptionalField("email", builder.String()).
+		Field("status", builder.Enum("Status", "ACTIVE", "INACTIVE"), builder.WithDefault("ACTIVE")).
+		Build()
+
+The built schemas are created with the avro schema constructors, so they are the same
+types Parse returns for the equivalent JSON schema. Named types declared without a
+namespace inherit the namespace of the enclosing named type, and can be used again
+either by passing the same builder or with Ref.
+*/
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// Type describes a schema to build.
+type Type interface {
+	build(st *state, namespace string) (avro.Schema, error)
+}
+
+// Errors holds the errors found while building a schema.
+type Errors []error
+
+// Error returns the messages of all errors.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Build builds the schema described by typ.
+//
+// If any errors are found, an Errors holding all of them is returned.
+func Build(typ Type) (avro.Schema, error) {
+	st := &state{named: map[string]namedEntry{}}
+	schema, err := typ.build(st, "")
+	if err != nil {
+		return nil, appendErr(nil, err)
+	}
+	return schema, nil
+}
+
+// Schema returns a type using an existing schema as is. A named schema can then also be used with Ref.
+func Schema(schema avro.Schema) Type {
+	return existing{schema: schema}
+}
+
+// Ref returns a type referencing a named type built earlier or being built, such as the enclosing
+// record of a recursive schema. The name is resolved in the namespace of the enclosing named
+// type, then as a full name.
+func Ref(name string) Type {
+	return ref{name: name}
+}
+
+// Null returns a null type.
+func Null() Type { return primitive{typ: avro.Null} }
+
+// Boolean returns a boolean type.
+func Boolean() Type { return primitive{typ: avro.Boolean} }
+
+// Int returns an int type.
+func Int() Type { return primitive{typ: avro.Int} }
+
+// Long returns a long type.
+func Long() Type { return primitive{typ: avro.Long} }
+
+// Float returns a float type.
+func Float() Type { return primitive{typ: avro.Float} }
+
+// Double returns a double type.
+func Double() Type { return primitive{typ: avro.Double} }
+
+// Bytes returns a bytes type.
+func Bytes() Type { return primitive{typ: avro.Bytes} }
+
+// String returns a string type.
+func String() Type { return primitive{typ: avro.String} }
+
+// Decimal returns a bytes type with a decimal logical type.
+func Decimal(precision, scale int) Type {
+	logical, err := decimal(precision, scale, -1)
+	return primitive{typ: avro.Bytes, logical: logical, err: err}
+}
+
+// UUID returns a string type with a uuid logical type.
+func UUID() Type { return logicalPrimitive(avro.String, avro.UUID) }
+
+// Date returns an int type with a date logical type.
+func Date() Type { return logicalPrimitive(avro.Int, avro.Date) }
+
+// TimeMillis returns an int type with a time-millis logical type.
+func TimeMillis() Type { return logicalPrimitive(avro.Int, avro.TimeMillis) }
+
+// TimeMicros returns a long type with a time-micros logical type.
+func TimeMicros() Type { return logicalPrimitive(avro.Long, avro.TimeMicros) }
+
+// TimestampMillis returns a long type with a timestamp-millis logical type.
+func TimestampMillis() Type { return logicalPrimitive(avro.Long, avro.TimestampMillis) }
+
+// TimestampMicros returns a long type with a timestamp-micros logical type.
+func TimestampMicros() Type { return logicalPrimitive(avro.Long, avro.TimestampMicros) }
+
+func logicalPrimitive(typ avro.Type, l avro.LogicalType) Type {
+	return primitive{typ: typ, logical: avro.NewPrimitiveLogicalSchema(l)}
+}
+
+// Array returns an array type with the given items.
+func Array(items Type) Type {
+	return array{items: items}
+}
+
+// Map returns a map type with the given values.
+func Map(values Type) Type {
+	return mapType{values: values}
+}
+
+// Union returns a union of the given types.
+func Union(types ...Type) Type {
+	return union{types: types}
+}
+
+// Optional returns a union of null and the given type.
+func Optional(typ Type) Type {
+	return union{types: []Type{Null(), typ}}
+}
+
+type state struct {
+	// named holds the named types built so far by full name.
+	named map[string]namedEntry
+}
+
+type namedEntry struct {
+	src    interface{}
+	schema avro.NamedSchema
+}
+
+// lookup returns the schema built for src with the given full name if one exists.
+func (st *state) lookup(src interface{}, full string) (avro.Schema, bool, error) {
+	e, ok := st.named[full]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.src != src {
+		return nil, false, fmt.Errorf("avro: %s is already defined", full)
+	}
+	return reference(e.schema), true, nil
+}
+
+func (st *state) add(src interface{}, schema avro.NamedSchema) {
+	st.named[schema.FullName()] = namedEntry{src: src, schema: schema}
+}
+
+// reference returns the schema to use for a named type that is used again.
+func reference(schema avro.NamedSchema) avro.Schema {
+	if rec, ok := schema.(*avro.RecordSchema); ok {
+		return avro.NewRefSchema(rec)
+	}
+	return schema
+}
+
+func fullName(namespace, name string) string {
+	if namespace == "" || strings.ContainsRune(name, '.') {
+		return name
+	}
+	return namespace + "." + name
+}
+
+type existing struct {
+	schema avro.Schema
+}
+
+func (t existing) build(st *state, _ string) (avro.Schema, error) {
+	if t.schema == nil {
+		return nil, errors.New("avro: schema is nil")
+	}
+
+	if named, ok := t.schema.(avro.NamedSchema); ok {
+		if schema, found, err := st.lookup(t, named.FullName()); found || err != nil {
+			return schema, err
+		}
+		st.add(t, named)
+	}
+	return t.schema, nil
+}
+
+type ref struct {
+	name string
+}
+
+func (t ref) build(st *state, namespace string) (avro.Schema, error) {
+	if e, ok := st.named[fullName(namespace, t.name)]; ok {
+		return reference(e.schema), nil
+	}
+	if e, ok := st.named[t.name]; ok {
+		return reference(e.schema), nil
+	}
+	return nil, fmt.Errorf("avro: unknown type: %s", t.name)
+}
+
+type primitive struct {
+	typ     avro.Type
+	logical avro.LogicalSchema
+	err     error
+}
+
+func (t primitive) build(*state, string) (avro.Schema, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.typ == avro.Null {
+		return &avro.NullSchema{}, nil
+	}
+	return avro.NewPrimitiveSchema(t.typ, t.logical), nil
+}
+
+type array struct {
+	items Type
+}
+
+func (t array) build(st *state, namespace string) (avro.Schema, error) {
+	if t.items == nil {
+		return nil, errors.New("avro: array has no items type")
+	}
+
+	items, err := t.items.build(st, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return avro.NewArraySchema(items), nil
+}
+
+type mapType struct {
+	values Type
+}
+
+func (t mapType) build(st *state, namespace string) (avro.Schema, error) {
+	if t.values == nil {
+		return nil, errors.New("avro: map has no values type")
+	}
+
+	values, err := t.values.build(st, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return avro.NewMapSchema(values), nil
+}
+
+type union struct {
+	types []Type
+}
+
+func (t union) build(st *state, namespace string) (avro.Schema, error) {
+	var errs Errors
+	types := make([]avro.Schema, 0, len(t.types))
+	for _, typ := range t.types {
+		if typ == nil {
+			errs = append(errs, errors.New("avro: union type is nil"))
+			continue
+		}
+
+		schema, err := typ.build(st, namespace)
+		if err != nil {
+			errs = appendErr(errs, err)
+			continue
+		}
+		types = append(types, schema)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return avro.NewUnionSchema(types)
+}
+
+// appendErr appends the error, flattening Errors.
+func appendErr(errs Errors, err error) Errors {
+	if e, ok := err.(Errors); ok {
+		return append(errs, e...)
+	}
+	return append(errs, err)
+}
+
+// appendErrAt appends the error, or each error of Errors, prefixed with the path it was found at.
+func appendErrAt(errs Errors, path string, err error) Errors {
+	for _, e := range appendErr(nil, err) {
+		errs = append(errs, fmt.Errorf("avro: %s: %w", path, e))
+	}
+	return errs
+}
+
+// decimal returns a decimal logical type, checking the precision fits in size bytes if size is positive.
+func decimal(precision, scale, size int) (avro.LogicalSchema, error) {
+	if precision <= 0 {
+		return nil, fmt.Errorf("avro: decimal precision must be positive, got %d", precision)
+	}
+	if scale < 0 || scale > precision {
+		return nil, fmt.Errorf("avro: decimal scale must be between 0 and the precision %d, got %d", precision, scale)
+	}
+	if size > 0 {
+		if max := int(math.Floor(math.Log10(2) * float64(8*size-1))); precision > max {
+			return nil, fmt.Errorf("avro: decimal precision %d does not fit in %d bytes", precision, size)
+		}
+	}
+	return avro.NewDecimalLogicalSchema(precision, scale), nil
+}
@@ -0,0 +1,306 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/builder"
+)
+
+func mustParse(t *testing.T, schema string) avro.Schema {
+	t.Helper()
+
+	s, err := avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+	require.NoError(t, err)
+	return s
+}
+
+func TestRecordBuilder_Build(t *testing.T) {
+	status := builder.Enum("Status", "ACTIVE", "INACTIVE").Doc("The status.")
+
+	got, err := builder.Record("User").
+		Namespace("org.hamba").
+		Doc("A user.").
+		Aliases("Person").
+		Prop("owner", "team").
+		Field("id", builder.Long(), builder.WithDoc("The id.")).
+		OptionalField("email", builder.String()).
+		Field("status", status, builder.WithDefault("ACTIVE")).
+		Field("previous", builder.Optional(status), builder.WithDefault(nil)).
+		Field("tags", builder.Map(builder.Array(builder.String())), builder.WithAliases("labels"), builder.WithProp("pii", true)).
+		Field("balance", builder.Decimal(10, 2)).
+		Field("hash", builder.Fixed("Hash", 16)).
+		Field("created", builder.TimestampMillis()).
+		Build()
+
+	require.NoError(t, err)
+	want := mustParse(t, `{
+		"type":"record","name":"User","namespace":"org.hamba","doc":"A user.","aliases":["Person"],"owner":"team",
+		"fields":[
+			{"name":"id","type":"long","doc":"The id."},
+			{"name":"email","type":["null","string"],"default":null},
+			{"name":"status","type":{"type":"enum","name":"Status","doc":"The status.","symbols":["ACTIVE","INACTIVE"]},"default":"ACTIVE"},
+			{"name":"previous","type":["null","Status"],"default":null},
+			{"name":"tags","type":{"type":"map","values":{"type":"array","items":"string"}},"aliases":["labels"],"pii":true},
+			{"name":"balance","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}},
+			{"name":"hash","type":{"type":"fixed","name":"Hash","size":16}},
+			{"name":"created","type":{"type":"long","logicalType":"timestamp-millis"}}
+		]
+	}`)
+	assert.Equal(t, want.String(), got.String())
+	assert.Equal(t, want.Fingerprint(), got.Fingerprint())
+
+	wantJSON, err := want.(*avro.RecordSchema).MarshalJSON()
+	require.NoError(t, err)
+	gotJSON, err := got.MarshalJSON()
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+
+	assert.Equal(t, "A user.", got.Doc())
+	assert.Equal(t, []string{"org.hamba.Person"}, got.Aliases())
+	assert.Equal(t, "The id.", got.Fields()[0].Doc())
+	assert.Equal(t, []string{"labels"}, got.Fields()[4].Aliases())
+	assert.Equal(t, true, got.Fields()[4].Prop("pii"))
+	assert.Equal(t, "org.hamba.Status", got.Fields()[2].Type().(*avro.EnumSchema).FullName())
+	assert.Same(t, got.Fields()[2].Type(), got.Fields()[3].Type().(*avro.UnionSchema).Types()[1])
+	assert.Equal(t, "ACTIVE", got.Fields()[2].Default())
+	assert.Nil(t, got.Fields()[3].Default())
+	assert.True(t, got.Fields()[3].HasDefault())
+}
+
+func TestRecordBuilder_BuildRecursive(t *testing.T) {
+	got, err := builder.Record("Node").
+		Namespace("org.hamba").
+		Field("value", builder.Int()).
+		OptionalField("next", builder.Ref("Node")).
+		Build()
+
+	require.NoError(t, err)
+	want := mustParse(t, `{"type":"record","name":"org.hamba.Node","fields":[
+		{"name":"value","type":"int"},
+		{"name":"next","type":["null","Node"],"default":null}
+	]}`)
+	assert.Equal(t, want.String(), got.String())
+	ref, ok := got.Fields()[1].Type().(*avro.UnionSchema).Types()[1].(*avro.RefSchema)
+	require.True(t, ok)
+	assert.Same(t, got, ref.Schema())
+}
+
+func TestRecordBuilder_BuildReusedRecord(t *testing.T) {
+	address := builder.Record("Address").Field("street", builder.String())
+
+	got, err := builder.Record("org.hamba.Order").
+		Field("billing", address).
+		Field("shipping", address).
+		Build()
+
+	require.NoError(t, err)
+	billing, ok := got.Fields()[0].Type().(*avro.RecordSchema)
+	require.True(t, ok)
+	assert.Equal(t, "org.hamba.Address", billing.FullName())
+	shipping, ok := got.Fields()[1].Type().(*avro.RefSchema)
+	require.True(t, ok)
+	assert.Same(t, billing, shipping.Schema())
+}
+
+func TestRecordBuilder_BuildExistingSchema(t *testing.T) {
+	enum := mustParse(t, `{"type":"enum","name":"org.hamba.Color","symbols":["RED","BLUE"]}`)
+
+	got, err := builder.Record("org.hamba.Paint").
+		Field("color", builder.Schema(enum)).
+		Field("colors", builder.Array(builder.Ref("Color"))).
+		Build()
+
+	require.NoError(t, err)
+	assert.Same(t, enum, got.Fields()[0].Type())
+	assert.Same(t, enum, got.Fields()[1].Type().(*avro.ArraySchema).Items())
+}
+
+func TestRecordBuilder_BuildErrorRecord(t *testing.T) {
+	got, err := builder.ErrorRecord("Failure").Field("message", builder.String()).Build()
+
+	require.NoError(t, err)
+	assert.True(t, got.IsError())
+	assert.Equal(t, avro.Record, got.Type())
+}
+
+func TestRecordBuilder_BuildAccumulatesErrors(t *testing.T) {
+	_, err := builder.Record("User").
+		Field("id", builder.Long(), builder.WithDefault("one")).
+		Field("id", builder.Int()).
+		Field("amount", builder.Decimal(2, 4)).
+		Field("kind", builder.Ref("Unknown")).
+		Field("nested", builder.Record("Nested").Field("bad-name", builder.Int())).
+		Build()
+
+	require.Error(t, err)
+	errs, ok := err.(builder.Errors)
+	require.True(t, ok)
+	assert.Len(t, errs, 5)
+	assert.Contains(t, err.Error(), "avro: duplicate field id")
+	assert.Contains(t, err.Error(), "avro: User.id: ")
+	assert.Contains(t, err.Error(), "avro: User.amount: avro: decimal scale")
+	assert.Contains(t, err.Error(), "avro: User.kind: avro: unknown type: Unknown")
+	assert.Contains(t, err.Error(), "avro: User.nested: avro: Nested.bad-name: ")
+}
+
+func TestRecordBuilder_BuildConflictingNamedTypes(t *testing.T) {
+	_, err := builder.Record("Test").
+		Field("a", builder.Enum("Kind", "A")).
+		Field("b", builder.Enum("Kind", "B")).
+		Build()
+
+	assert.EqualError(t, err, "avro: Test.b: avro: Kind is already defined")
+}
+
+func TestEnumBuilder_Build(t *testing.T) {
+	got, err := builder.Enum("Suit", "SPADES").
+		Namespace("org.hamba").
+		Doc("A suit.").
+		Aliases("Card").
+		Prop("owner", "team").
+		Symbols("HEARTS").
+		Build()
+
+	require.NoError(t, err)
+	want := mustParse(t, `{"type":"enum","name":"Suit","namespace":"org.hamba","doc":"A suit.","symbols":["SPADES","HEARTS"]}`)
+	assert.Equal(t, want.String(), got.String())
+	assert.Equal(t, "A suit.", got.Doc())
+	assert.Equal(t, []string{"org.hamba.Card"}, got.Aliases())
+	assert.Equal(t, "team", got.Prop("owner"))
+}
+
+func TestEnumBuilder_BuildWithoutSymbols(t *testing.T) {
+	_, err := builder.Enum("Suit").Build()
+
+	assert.Error(t, err)
+}
+
+func TestFixedBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixed   *builder.FixedBuilder
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "Valid",
+			fixed: builder.Fixed("Hash", 16).Namespace("org.hamba").Doc("A hash."),
+			want:  `{"type":"fixed","name":"org.hamba.Hash","size":16}`,
+		},
+		{
+			name:  "Decimal",
+			fixed: builder.Fixed("Amount", 8).Decimal(18, 2),
+			want:  `{"type":"fixed","name":"Amount","size":8,"logicalType":"decimal","precision":18,"scale":2}`,
+		},
+		{
+			name:  "Duration",
+			fixed: builder.Fixed("Period", 12).Duration(),
+			want:  `{"type":"fixed","name":"Period","size":12,"logicalType":"duration"}`,
+		},
+		{
+			name:    "Decimal Too Large",
+			fixed:   builder.Fixed("Amount", 8).Decimal(19, 2),
+			wantErr: true,
+		},
+		{
+			name:    "Duration Invalid Size",
+			fixed:   builder.Fixed("Period", 8).Duration(),
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Size",
+			fixed:   builder.Fixed("Hash", 0),
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Name",
+			fixed:   builder.Fixed("1Hash", 16),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.fixed.Build()
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			want := mustParse(t, test.want).(*avro.FixedSchema)
+			assert.Equal(t, want.String(), got.String())
+			assert.Equal(t, want.Logical(), got.Logical())
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     builder.Type
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Primitives",
+			typ: builder.Union(builder.Null(), builder.Boolean(), builder.Int(), builder.Long(), builder.Float(),
+				builder.Double(), builder.Bytes(), builder.String()),
+			want: `["null","boolean","int","long","float","double","bytes","string"]`,
+		},
+		{
+			name: "Logical Types",
+			typ: builder.Array(builder.Union(builder.UUID(), builder.Date(), builder.TimeMicros(),
+				builder.TimestampMicros())),
+			want: `{"type":"array","items":[{"type":"string","logicalType":"uuid"},{"type":"int","logicalType":"date"},{"type":"long","logicalType":"time-micros"},{"type":"long","logicalType":"timestamp-micros"}]}`,
+		},
+		{
+			name: "Time Millis",
+			typ:  builder.TimeMillis(),
+			want: `{"type":"int","logicalType":"time-millis"}`,
+		},
+		{
+			name: "Map",
+			typ:  builder.Map(builder.Optional(builder.Record("Item").Field("a", builder.Int()))),
+			want: `{"type":"map","values":["null",{"type":"record","name":"Item","fields":[{"name":"a","type":"int"}]}]}`,
+		},
+		{
+			name:    "Duplicate Union Types",
+			typ:     builder.Union(builder.Int(), builder.Int()),
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Decimal",
+			typ:     builder.Decimal(0, 0),
+			wantErr: true,
+		},
+		{
+			name:    "Nil Items",
+			typ:     builder.Array(nil),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := builder.Build(test.typ)
+
+			if test.wantErr {
+				require.Error(t, err)
+				assert.IsType(t, builder.Errors{}, err)
+				return
+			}
+
+			require.NoError(t, err)
+			want := mustParse(t, test.want)
+			assert.Equal(t, want.String(), got.String())
+			assert.Equal(t, want.Fingerprint(), got.Fingerprint())
+		})
+	}
+}
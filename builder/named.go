@@ -0,0 +1,386 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// FieldFunc represents a configuration function for a record field.
+type FieldFunc func(f *field)
+
+// WithDefault sets the default of a field. The default is given as it would be in JSON,
+// for example a string for an enum symbol or nil for null.
+func WithDefault(def interface{}) FieldFunc {
+	return func(f *field) {
+		f.def = def
+	}
+}
+
+// WithDoc sets the documentation of a field.
+func WithDoc(doc string) FieldFunc {
+	return func(f *field) {
+		f.doc = doc
+	}
+}
+
+// WithAliases sets the aliases of a field.
+func WithAliases(aliases ...string) FieldFunc {
+	return func(f *field) {
+		f.aliases = aliases
+	}
+}
+
+// WithProp adds a property to a field.
+func WithProp(name string, value interface{}) FieldFunc {
+	return func(f *field) {
+		if f.props == nil {
+			f.props = map[string]interface{}{}
+		}
+		f.props[name] = value
+	}
+}
+
+type field struct {
+	name    string
+	typ     Type
+	def     interface{}
+	doc     string
+	aliases []string
+	props   map[string]interface{}
+}
+
+// named holds the attributes shared by named types.
+type named struct {
+	name      string
+	namespace string
+	doc       string
+	aliases   []string
+	props     map[string]interface{}
+	errs      Errors
+}
+
+func (n *named) opts() []avro.SchemaFunc {
+	return []avro.SchemaFunc{avro.WithDoc(n.doc), avro.WithAliases(n.aliases)}
+}
+
+func (n *named) addProp(name string, value interface{}) {
+	if n.props == nil {
+		n.props = map[string]interface{}{}
+	}
+	n.props[name] = value
+}
+
+// space returns the namespace of the type when declared in the given enclosing namespace.
+func (n *named) space(namespace string) string {
+	if n.namespace != "" {
+		return n.namespace
+	}
+	return namespace
+}
+
+// RecordBuilder builds a record schema.
+type RecordBuilder struct {
+	named
+
+	isError bool
+	fields  []*field
+}
+
+// Record returns a builder for a record with the given name.
+func Record(name string) *RecordBuilder {
+	return &RecordBuilder{named: named{name: name}}
+}
+
+// ErrorRecord returns a builder for an error record with the given name.
+func ErrorRecord(name string) *RecordBuilder {
+	return &RecordBuilder{named: named{name: name}, isError: true}
+}
+
+// Namespace sets the namespace of the record.
+func (b *RecordBuilder) Namespace(namespace string) *RecordBuilder {
+	b.namespace = namespace
+	return b
+}
+
+// Doc sets the documentation of the record.
+func (b *RecordBuilder) Doc(doc string) *RecordBuilder {
+	b.doc = doc
+	return b
+}
+
+// Aliases sets the aliases of the record.
+func (b *RecordBuilder) Aliases(aliases ...string) *RecordBuilder {
+	b.aliases = aliases
+	return b
+}
+
+// Prop adds a property to the record.
+func (b *RecordBuilder) Prop(name string, value interface{}) *RecordBuilder {
+	b.addProp(name, value)
+	return b
+}
+
+// Field adds a field with the given type to the record.
+func (b *RecordBuilder) Field(name string, typ Type, opts ...FieldFunc) *RecordBuilder {
+	for _, f := range b.fields {
+		if f.name == name {
+			b.errs = append(b.errs, fmt.Errorf("avro: duplicate field %s", name))
+			return b
+		}
+	}
+	if typ == nil {
+		b.errs = append(b.errs, fmt.Errorf("avro: field %s has no type", name))
+		return b
+	}
+
+	f := &field{name: name, typ: typ, def: avro.NoDefault}
+	for _, opt := range opts {
+		opt(f)
+	}
+	b.fields = append(b.fields, f)
+	return b
+}
+
+// OptionalField adds a field with a union of null and the given type to the record,
+// defaulting to null.
+func (b *RecordBuilder) OptionalField(name string, typ Type, opts ...FieldFunc) *RecordBuilder {
+	opts = append([]FieldFunc{WithDefault(nil)}, opts...)
+	return b.Field(name, Optional(typ), opts...)
+}
+
+// Build builds the record schema.
+//
+// If any errors are found, an Errors holding all of them is returned.
+func (b *RecordBuilder) Build() (*avro.RecordSchema, error) {
+	schema, err := Build(b)
+	if err != nil {
+		return nil, err
+	}
+	return schema.(*avro.RecordSchema), nil
+}
+
+func (b *RecordBuilder) build(st *state, namespace string) (avro.Schema, error) {
+	errs := append(Errors{}, b.errs...)
+	space := b.space(namespace)
+
+	if schema, found, err := st.lookup(b, fullName(space, b.name)); found || err != nil {
+		return schema, err
+	}
+
+	fields := make([]*avro.Field, len(b.fields))
+	var rec *avro.RecordSchema
+	var err error
+	if b.isError {
+		rec, err = avro.NewErrorRecordSchema(b.name, space, fields, b.opts()...)
+	} else {
+		rec, err = avro.NewRecordSchema(b.name, space, fields, b.opts()...)
+	}
+	if err != nil {
+		return nil, append(errs, fmt.Errorf("avro: record %s: %w", b.name, err))
+	}
+	for k, v := range b.props {
+		rec.AddProp(k, v)
+	}
+
+	// The record is known before its fields are built, so recursive references resolve to it.
+	st.add(b, rec)
+
+	for i, f := range b.fields {
+		// A full name sets the namespace of the fields.
+		path := rec.FullName() + "." + f.name
+		typ, err := f.typ.build(st, rec.Namespace())
+		if err != nil {
+			errs = appendErrAt(errs, path, err)
+			continue
+		}
+
+		af, err := avro.NewField(f.name, typ, f.def, avro.WithDoc(f.doc), avro.WithAliases(f.aliases))
+		if err != nil {
+			errs = appendErrAt(errs, path, err)
+			continue
+		}
+		for k, v := range f.props {
+			af.AddProp(k, v)
+		}
+		fields[i] = af
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return rec, nil
+}
+
+// EnumBuilder builds an enum schema.
+type EnumBuilder struct {
+	named
+
+	symbols []string
+}
+
+// Enum returns a builder for an enum with the given name and symbols.
+func Enum(name string, symbols ...string) *EnumBuilder {
+	return &EnumBuilder{named: named{name: name}, symbols: symbols}
+}
+
+// Namespace sets the namespace of the enum.
+func (b *EnumBuilder) Namespace(namespace string) *EnumBuilder {
+	b.namespace = namespace
+	return b
+}
+
+// Doc sets the documentation of the enum.
+func (b *EnumBuilder) Doc(doc string) *EnumBuilder {
+	b.doc = doc
+	return b
+}
+
+// Aliases sets the aliases of the enum.
+func (b *EnumBuilder) Aliases(aliases ...string) *EnumBuilder {
+	b.aliases = aliases
+	return b
+}
+
+// Prop adds a property to the enum.
+func (b *EnumBuilder) Prop(name string, value interface{}) *EnumBuilder {
+	b.addProp(name, value)
+	return b
+}
+
+// Symbols adds symbols to the enum.
+func (b *EnumBuilder) Symbols(symbols ...string) *EnumBuilder {
+	b.symbols = append(b.symbols, symbols...)
+	return b
+}
+
+// Build builds the enum schema.
+//
+// If any errors are found, an Errors holding all of them is returned.
+func (b *EnumBuilder) Build() (*avro.EnumSchema, error) {
+	schema, err := Build(b)
+	if err != nil {
+		return nil, err
+	}
+	return schema.(*avro.EnumSchema), nil
+}
+
+func (b *EnumBuilder) build(st *state, namespace string) (avro.Schema, error) {
+	space := b.space(namespace)
+
+	if schema, found, err := st.lookup(b, fullName(space, b.name)); found || err != nil {
+		return schema, err
+	}
+
+	errs := append(Errors{}, b.errs...)
+	enum, err := avro.NewEnumSchema(b.name, space, b.symbols, b.opts()...)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("avro: enum %s: %w", b.name, err))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for k, v := range b.props {
+		enum.AddProp(k, v)
+	}
+
+	st.add(b, enum)
+	return enum, nil
+}
+
+// FixedBuilder builds a fixed schema.
+type FixedBuilder struct {
+	named
+
+	size    int
+	logical avro.LogicalSchema
+}
+
+// Fixed returns a builder for a fixed with the given name and size.
+func Fixed(name string, size int) *FixedBuilder {
+	return &FixedBuilder{named: named{name: name}, size: size}
+}
+
+// Namespace sets the namespace of the fixed.
+func (b *FixedBuilder) Namespace(namespace string) *FixedBuilder {
+	b.namespace = namespace
+	return b
+}
+
+// Doc sets the documentation of the fixed.
+func (b *FixedBuilder) Doc(doc string) *FixedBuilder {
+	b.doc = doc
+	return b
+}
+
+// Aliases sets the aliases of the fixed.
+func (b *FixedBuilder) Aliases(aliases ...string) *FixedBuilder {
+	b.aliases = aliases
+	return b
+}
+
+// Prop adds a property to the fixed.
+func (b *FixedBuilder) Prop(name string, value interface{}) *FixedBuilder {
+	b.addProp(name, value)
+	return b
+}
+
+// Decimal sets the logical type of the fixed to a decimal.
+func (b *FixedBuilder) Decimal(precision, scale int) *FixedBuilder {
+	logical, err := decimal(precision, scale, b.size)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	b.logical = logical
+	return b
+}
+
+// Duration sets the logical type of the fixed to a duration. The size of the fixed must be 12.
+func (b *FixedBuilder) Duration() *FixedBuilder {
+	if b.size != 12 {
+		b.errs = append(b.errs, errors.New("avro: duration must be a fixed of size 12"))
+		return b
+	}
+	b.logical = avro.NewPrimitiveLogicalSchema(avro.Duration)
+	return b
+}
+
+// Build builds the fixed schema.
+//
+// If any errors are found, an Errors holding all of them is returned.
+func (b *FixedBuilder) Build() (*avro.FixedSchema, error) {
+	schema, err := Build(b)
+	if err != nil {
+		return nil, err
+	}
+	return schema.(*avro.FixedSchema), nil
+}
+
+func (b *FixedBuilder) build(st *state, namespace string) (avro.Schema, error) {
+	space := b.space(namespace)
+
+	if schema, found, err := st.lookup(b, fullName(space, b.name)); found || err != nil {
+		return schema, err
+	}
+
+	errs := append(Errors{}, b.errs...)
+	if b.size <= 0 {
+		errs = append(errs, fmt.Errorf("avro: fixed %s must have a positive size", b.name))
+	}
+
+	fixed, err := avro.NewFixedSchema(b.name, space, b.size, b.logical, b.opts()...)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("avro: fixed %s: %w", b.name, err))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	for k, v := range b.props {
+		fixed.AddProp(k, v)
+	}
+
+	st.add(b, fixed)
+	return fixed, nil
+}
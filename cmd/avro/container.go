@@ -0,0 +1,480 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/ocf"
+)
+
+const (
+	schemaKey = "avro.schema"
+	codecKey  = "avro.codec"
+)
+
+var magicBytes = [4]byte{'O', 'b', 'j', 1}
+
+func init() {
+	register("getschema", command{
+		usage: "FILE",
+		help:  "Prints the schema of a container file",
+		run:   runGetSchema,
+	})
+	register("getmeta", command{
+		usage: "[-key KEY] FILE",
+		help:  "Prints the metadata of a container file",
+		run:   runGetMeta,
+	})
+	register("count", command{
+		usage: "FILE",
+		help:  "Prints the number of records in a container file",
+		run:   runCount,
+	})
+	register("cat", command{
+		usage: "[-offset N] [-limit N] [-codec CODEC] FILE...",
+		help:  "Writes a range of records from container files to a new container file on stdout",
+		run:   runCat,
+	})
+	register("concat", command{
+		usage: "[-codec CODEC] FILE...",
+		help:  "Concatenates container files with the same schema into a container file on stdout",
+		run:   runConcat,
+	})
+	register("recodec", command{
+		usage: "-codec CODEC FILE",
+		help:  "Rewrites a container file with a different codec to stdout",
+		run:   runRecodec,
+	})
+	register("repair", command{
+		usage: "FILE",
+		help:  "Writes the readable blocks of a corrupt container file to stdout",
+		run:   runRepair,
+	})
+}
+
+// withDecoder opens the named container file and calls fn with a decoder for it.
+func withDecoder(env env, name string, fn func(dec *ocf.Decoder, schema avro.Schema) error) error {
+	f, err := open(env, name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	dec, err := ocf.NewDecoder(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+
+	schema, err := avro.Parse(string(dec.Metadata()[schemaKey]))
+	if err != nil {
+		return err
+	}
+
+	return fn(dec, schema)
+}
+
+// eachDatum calls fn with the plain form of each value in the decoder.
+func eachDatum(dec *ocf.Decoder, schema avro.Schema, fn func(v interface{}) error) error {
+	for dec.HasNext() {
+		v, err := decodeDatum(dec, schema)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(v); err != nil {
+			return err
+		}
+	}
+
+	return dec.Error()
+}
+
+func decodeDatum(dec *ocf.Decoder, schema avro.Schema) (interface{}, error) {
+	var v interface{}
+	if resolveRef(schema).Type() == avro.Union {
+		// Unions are only decoded with their type name into a map.
+		var m map[string]interface{}
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+		if len(m) > 0 {
+			v = m
+		}
+	} else if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return normalize(schema, v)
+}
+
+// datumWriter writes plain form values to a container file.
+type datumWriter struct {
+	schema avro.Schema
+	enc    *ocf.Encoder
+	w      *avro.Writer
+}
+
+func newDatumWriter(out io.Writer, schema avro.Schema, codec ocf.CodecName, meta map[string][]byte) (*datumWriter, error) {
+	md := map[string][]byte{}
+	for k, v := range meta {
+		if k == schemaKey || k == codecKey {
+			continue
+		}
+		md[k] = v
+	}
+
+	// The schema is passed in full, so the field orders are kept.
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := ocf.NewEncoder(string(schemaJSON), out, ocf.WithCodec(codec), ocf.WithMetadata(md))
+	if err != nil {
+		return nil, err
+	}
+
+	return &datumWriter{
+		schema: schema,
+		enc:    enc,
+		w:      avro.NewWriter(nil, 512),
+	}, nil
+}
+
+func (w *datumWriter) Write(v interface{}) error {
+	w.w.Reset(nil)
+	if err := writeDatum(w.w, w.schema, v); err != nil {
+		return err
+	}
+
+	_, err := w.enc.Write(w.w.Buffer())
+	return err
+}
+
+func (w *datumWriter) Close() error {
+	return w.enc.Close()
+}
+
+func runGetSchema(env env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	return withDecoder(env, args[0], func(dec *ocf.Decoder, _ avro.Schema) error {
+		_, err := fmt.Fprintln(env.stdout, string(dec.Metadata()[schemaKey]))
+		return err
+	})
+}
+
+func runGetMeta(env env, args []string) error {
+	fs := newFlagSet("getmeta", env)
+	key := fs.String("key", "", "Only print the value of the given key")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	return withDecoder(env, fs.Arg(0), func(dec *ocf.Decoder, _ avro.Schema) error {
+		meta := dec.Metadata()
+		if *key != "" {
+			v, ok := meta[*key]
+			if !ok {
+				return fmt.Errorf("key %q not found", *key)
+			}
+			_, err := fmt.Fprintln(env.stdout, string(v))
+			return err
+		}
+
+		keys := make([]string, 0, len(meta))
+		for k := range meta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if _, err := fmt.Fprintf(env.stdout, "%s\t%s\n", k, meta[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func runCount(env env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	return withDecoder(env, args[0], func(dec *ocf.Decoder, _ avro.Schema) error {
+		var count int64
+		for dec.HasNext() {
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := dec.Error(); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintln(env.stdout, count)
+		return err
+	})
+}
+
+func runCat(env env, args []string) error {
+	fs := newFlagSet("cat", env)
+	offset := fs.Int64("offset", 0, "The number of records to skip")
+	limit := fs.Int64("limit", -1, "The maximum number of records to write, or -1 for all records")
+	codec := fs.String("codec", "", "The output codec, defaults to the codec of the first file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+
+	return copyRecords(env, fs.Args(), ocf.CodecName(*codec), *offset, *limit)
+}
+
+func runConcat(env env, args []string) error {
+	fs := newFlagSet("concat", env)
+	codec := fs.String("codec", "", "The output codec, defaults to the codec of the first file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+
+	c := &blockCopier{w: avro.NewWriter(env.stdout, 512), codec: ocf.CodecName(*codec)}
+	for _, name := range fs.Args() {
+		if err := c.copyFile(env, name); err != nil {
+			return err
+		}
+	}
+	return c.w.Flush()
+}
+
+// blockCopier writes the data blocks of container files with the same schema to a single
+// container file, without decoding their records. Blocks are copied unchanged if their
+// codec is the output codec, and recompressed otherwise.
+type blockCopier struct {
+	w     *avro.Writer
+	codec ocf.CodecName
+
+	// first, schema and sync are set from the first file.
+	first  string
+	schema avro.Schema
+	sync   [16]byte
+}
+
+func (c *blockCopier) copyFile(env env, name string) error {
+	f, err := open(env, name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := avro.NewReader(bufio.NewReader(f), 1024)
+
+	var h ocf.Header
+	r.ReadVal(ocf.HeaderSchema, &h)
+	if r.Error != nil {
+		return fmt.Errorf("%s: invalid container file: %w", name, r.Error)
+	}
+	if h.Magic != magicBytes {
+		return fmt.Errorf("%s: invalid container file", name)
+	}
+
+	schema, err := avro.Parse(string(h.Meta[schemaKey]))
+	if err != nil {
+		return err
+	}
+	codec := ocf.CodecName(h.Meta[codecKey])
+	if c.schema == nil {
+		if err = c.writeHeader(name, schema, h); err != nil {
+			return err
+		}
+	} else if schema.String() != c.schema.String() {
+		return fmt.Errorf("%s: schema does not match the schema of %s", name, c.first)
+	}
+
+	in, err := codecOf(codec)
+	if err != nil {
+		return err
+	}
+	out, _ := codecOf(c.codec)
+	recompress := normalizeCodec(codec) != c.codec
+
+	for {
+		count := r.ReadLong()
+		if errors.Is(r.Error, io.EOF) {
+			return nil
+		}
+		size := r.ReadLong()
+		if size < 0 {
+			return fmt.Errorf("%s: invalid block size", name)
+		}
+		data := make([]byte, size)
+		r.Read(data)
+		var sync [16]byte
+		r.Read(sync[:])
+		if r.Error != nil {
+			return fmt.Errorf("%s: invalid block: %w", name, r.Error)
+		}
+		if sync != h.Sync {
+			return fmt.Errorf("%s: invalid block", name)
+		}
+
+		if recompress {
+			b, err := in.Decode(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			data = out.Encode(b)
+		}
+
+		c.w.WriteLong(count)
+		c.w.WriteLong(int64(len(data)))
+		c.w.Write(data)
+		c.w.Write(c.sync[:])
+		if err = c.w.Flush(); err != nil {
+			return err
+		}
+	}
+}
+
+// writeHeader writes the header of the output file from the header of the first file.
+func (c *blockCopier) writeHeader(name string, schema avro.Schema, h ocf.Header) error {
+	if c.codec == "" {
+		c.codec = ocf.CodecName(h.Meta[codecKey])
+	}
+	c.codec = normalizeCodec(c.codec)
+	if _, err := codecOf(c.codec); err != nil {
+		return err
+	}
+
+	c.first = name
+	c.schema = schema
+	c.sync = h.Sync
+
+	meta := make(map[string][]byte, len(h.Meta))
+	for k, v := range h.Meta {
+		meta[k] = v
+	}
+	meta[codecKey] = []byte(c.codec)
+
+	c.w.WriteVal(ocf.HeaderSchema, ocf.Header{Magic: h.Magic, Meta: meta, Sync: c.sync})
+	return c.w.Error
+}
+
+// normalizeCodec returns the name of the codec, a missing codec being the null codec.
+func normalizeCodec(name ocf.CodecName) ocf.CodecName {
+	if name == "" {
+		return ocf.Null
+	}
+	return name
+}
+
+// codecOf returns the codec with the given name.
+func codecOf(name ocf.CodecName) (ocf.Codec, error) {
+	switch normalizeCodec(name) {
+	case ocf.Null:
+		return &ocf.NullCodec{}, nil
+	case ocf.Deflate:
+		return &ocf.DeflateCodec{}, nil
+	case ocf.Snappy:
+		return &ocf.SnappyCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec %s", name)
+	}
+}
+
+func runRecodec(env env, args []string) error {
+	fs := newFlagSet("recodec", env)
+	codec := fs.String("codec", "", "The output codec")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *codec == "" {
+		return errUsage
+	}
+
+	return copyRecords(env, fs.Args(), ocf.CodecName(*codec), 0, -1)
+}
+
+// copyRecords writes the records of the files to a single container file on stdout.
+// All files must have the same schema.
+func copyRecords(env env, files []string, codec ocf.CodecName, offset, limit int64) error {
+	var (
+		out  *datumWriter
+		n    int64
+		done = errors.New("done")
+	)
+	for _, name := range files {
+		err := withDecoder(env, name, func(dec *ocf.Decoder, schema avro.Schema) error {
+			if out == nil {
+				if codec == "" {
+					codec = ocf.CodecName(dec.Metadata()[codecKey])
+				}
+
+				var err error
+				out, err = newDatumWriter(env.stdout, schema, codec, dec.Metadata())
+				if err != nil {
+					return err
+				}
+			} else if out.schema.String() != schema.String() {
+				return fmt.Errorf("%s: schema does not match the schema of %s", name, files[0])
+			}
+
+			return eachDatum(dec, schema, func(v interface{}) error {
+				n++
+				if n <= offset {
+					return nil
+				}
+				if limit >= 0 && n > offset+limit {
+					return done
+				}
+				return out.Write(v)
+			})
+		})
+		if errors.Is(err, done) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return out.Close()
+}
+
+func runRepair(env env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	f, err := open(env, args[0])
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	skipped, err := ocf.Repair(env.stdout, bufio.NewReader(f))
+	for _, region := range skipped {
+		_, _ = fmt.Fprintf(env.stderr, "skipped %d bytes at offset %d: %v\n", region.Length, region.Offset, region.Err)
+	}
+	return err
+}
@@ -0,0 +1,717 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xl4hub/hamba-avro"
+)
+
+// Datums are handled in a plain form that mirrors the Avro binary encoding:
+// logical types are represented by their underlying type, bytes and fixed
+// by []byte, enums by their symbol, records and maps by map[string]interface{}
+// and unions by a unionValue.
+
+// unionValue is a value in a union along with the index of its type.
+type unionValue struct {
+	index int
+	value interface{}
+}
+
+func resolveRef(schema avro.Schema) avro.Schema {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		return ref.Schema()
+	}
+
+	return schema
+}
+
+func logicalTypeOf(schema avro.Schema) avro.LogicalSchema {
+	if lts, ok := schema.(avro.LogicalTypeSchema); ok {
+		return lts.Logical()
+	}
+
+	return nil
+}
+
+// normalize converts a value returned by avro.Reader.ReadNext into its plain form.
+func normalize(schema avro.Schema, v interface{}) (interface{}, error) {
+	schema = resolveRef(schema)
+
+	switch schema.Type() {
+	case avro.Null:
+		return nil, nil
+
+	case avro.Int:
+		switch val := v.(type) {
+		case int:
+			return int32(val), nil
+		case time.Time:
+			return int32(val.UnixNano() / int64(24*time.Hour)), nil
+		case time.Duration:
+			return int32(val / time.Millisecond), nil
+		}
+
+	case avro.Long:
+		switch val := v.(type) {
+		case int64:
+			return val, nil
+		case time.Duration:
+			return int64(val / time.Microsecond), nil
+		case time.Time:
+			if ls := logicalTypeOf(schema); ls != nil && ls.Type() == avro.TimestampMicros {
+				return val.UnixNano() / int64(time.Microsecond), nil
+			}
+			return val.UnixNano() / int64(time.Millisecond), nil
+		}
+
+	case avro.Bytes, avro.Fixed:
+		size := -1
+		if fixed, ok := schema.(*avro.FixedSchema); ok {
+			size = fixed.Size()
+		}
+
+		switch val := v.(type) {
+		case []byte:
+			return val, nil
+		case *big.Rat:
+			dec, ok := logicalTypeOf(schema).(*avro.DecimalLogicalSchema)
+			if !ok {
+				break
+			}
+			return ratToBytes(val, dec.Scale(), size), nil
+		}
+
+	case avro.Array:
+		items := schema.(*avro.ArraySchema).Items()
+		arr, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		out := make([]interface{}, len(arr))
+		for i, elem := range arr {
+			n, err := normalize(items, elem)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return out, nil
+
+	case avro.Map:
+		values := schema.(*avro.MapSchema).Values()
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, elem := range m {
+			n, err := normalize(values, elem)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = n
+		}
+		return out, nil
+
+	case avro.Record:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		out := make(map[string]interface{}, len(m))
+		for _, field := range schema.(*avro.RecordSchema).Fields() {
+			n, err := normalize(field.Type(), m[field.Name()])
+			if err != nil {
+				return nil, err
+			}
+			out[field.Name()] = n
+		}
+		return out, nil
+
+	case avro.Union:
+		types := schema.(*avro.UnionSchema).Types()
+		if v == nil {
+			_, idx := types.Get(string(avro.Null))
+			if idx < 0 {
+				break
+			}
+			return unionValue{index: idx}, nil
+		}
+
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			break
+		}
+		for name, elem := range m {
+			typ, idx := types.Get(name)
+			if typ == nil {
+				return nil, fmt.Errorf("unknown union type %s", name)
+			}
+			n, err := normalize(typ, elem)
+			if err != nil {
+				return nil, err
+			}
+			return unionValue{index: idx, value: n}, nil
+		}
+
+	default:
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("unexpected value %v for %s", v, schema.Type())
+}
+
+// ratToBytes returns the two's complement big endian unscaled value of r.
+// If size is positive the result is padded to size bytes.
+func ratToBytes(r *big.Rat, scale, size int) []byte {
+	i := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	i.Mul(i, r.Num())
+	i.Quo(i, r.Denom())
+
+	n := len(i.Bytes()) + 1
+	if size > 0 {
+		n = size
+	}
+	if i.Sign() < 0 {
+		i.Add(i, new(big.Int).Lsh(big.NewInt(1), uint(8*n)))
+	}
+
+	b := make([]byte, n)
+	ib := i.Bytes()
+	if len(ib) > n {
+		ib = ib[len(ib)-n:]
+	}
+	copy(b[n-len(ib):], ib)
+
+	if size > 0 {
+		return b
+	}
+
+	// Trim redundant sign bytes.
+	for len(b) > 1 && (b[0] == 0x00 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0) {
+		b = b[1:]
+	}
+	return b
+}
+
+// writeDatum writes the plain form value v to w.
+func writeDatum(w *avro.Writer, schema avro.Schema, v interface{}) error {
+	schema = resolveRef(schema)
+
+	switch schema.Type() {
+	case avro.Null:
+		return nil
+
+	case avro.Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			break
+		}
+		w.WriteBool(b)
+		return nil
+
+	case avro.Int:
+		i, ok := v.(int32)
+		if !ok {
+			break
+		}
+		w.WriteInt(i)
+		return nil
+
+	case avro.Long:
+		i, ok := v.(int64)
+		if !ok {
+			break
+		}
+		w.WriteLong(i)
+		return nil
+
+	case avro.Float:
+		f, ok := v.(float32)
+		if !ok {
+			break
+		}
+		w.WriteFloat(f)
+		return nil
+
+	case avro.Double:
+		f, ok := v.(float64)
+		if !ok {
+			break
+		}
+		w.WriteDouble(f)
+		return nil
+
+	case avro.String:
+		s, ok := v.(string)
+		if !ok {
+			break
+		}
+		w.WriteString(s)
+		return nil
+
+	case avro.Bytes:
+		b, ok := v.([]byte)
+		if !ok {
+			break
+		}
+		w.WriteBytes(b)
+		return nil
+
+	case avro.Fixed:
+		b, ok := v.([]byte)
+		if !ok || len(b) != schema.(*avro.FixedSchema).Size() {
+			break
+		}
+		w.Write(b)
+		return nil
+
+	case avro.Enum:
+		sym, ok := v.(string)
+		if !ok {
+			break
+		}
+		for i, s := range schema.(*avro.EnumSchema).Symbols() {
+			if s == sym {
+				w.WriteInt(int32(i))
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown enum symbol %s", sym)
+
+	case avro.Array:
+		arr, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		if len(arr) > 0 {
+			w.WriteBlockHeader(int64(len(arr)), 0)
+			for _, elem := range arr {
+				if err := writeDatum(w, schema.(*avro.ArraySchema).Items(), elem); err != nil {
+					return err
+				}
+			}
+		}
+		w.WriteBlockHeader(0, 0)
+		return nil
+
+	case avro.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		if len(m) > 0 {
+			w.WriteBlockHeader(int64(len(m)), 0)
+			for _, k := range sortedKeys(m) {
+				w.WriteString(k)
+				if err := writeDatum(w, schema.(*avro.MapSchema).Values(), m[k]); err != nil {
+					return err
+				}
+			}
+		}
+		w.WriteBlockHeader(0, 0)
+		return nil
+
+	case avro.Record:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, field := range schema.(*avro.RecordSchema).Fields() {
+			if err := writeDatum(w, field.Type(), m[field.Name()]); err != nil {
+				return fmt.Errorf("%s: %w", field.Name(), err)
+			}
+		}
+		return nil
+
+	case avro.Union:
+		u, ok := v.(unionValue)
+		if !ok {
+			break
+		}
+		w.WriteLong(int64(u.index))
+		return writeDatum(w, schema.(*avro.UnionSchema).Types()[u.index], u.value)
+	}
+
+	return fmt.Errorf("unexpected value %v for %s", v, schema.Type())
+}
+
+// unionBranchName returns the name of a union type in the Avro JSON encoding.
+func unionBranchName(schema avro.Schema) string {
+	schema = resolveRef(schema)
+	if named, ok := schema.(avro.NamedSchema); ok {
+		return named.FullName()
+	}
+
+	return string(schema.Type())
+}
+
+// jsonField is a field of a jsonObject.
+type jsonField struct {
+	name  string
+	value interface{}
+}
+
+// jsonObject is a JSON object that keeps its field order.
+type jsonObject []jsonField
+
+// MarshalJSON marshals the object to json.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, f := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, err := jsoniter.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := jsoniter.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return append(b, '}'), nil
+}
+
+// toJSON converts a plain form value into a value that marshals to its JSON encoding.
+// If plain is true, union values are not wrapped with their type name.
+func toJSON(schema avro.Schema, v interface{}, plain bool) interface{} {
+	schema = resolveRef(schema)
+
+	switch schema.Type() {
+	case avro.Float:
+		if f, ok := v.(float32); ok {
+			if s, ok := jsonFloat(float64(f)).(string); ok {
+				return s
+			}
+			return f
+		}
+
+	case avro.Double:
+		if f, ok := v.(float64); ok {
+			return jsonFloat(f)
+		}
+
+	case avro.Bytes, avro.Fixed:
+		if b, ok := v.([]byte); ok {
+			return bytesToString(b)
+		}
+
+	case avro.Array:
+		arr := v.([]interface{})
+		out := make([]interface{}, len(arr))
+		for i, elem := range arr {
+			out[i] = toJSON(schema.(*avro.ArraySchema).Items(), elem, plain)
+		}
+		return out
+
+	case avro.Map:
+		m := v.(map[string]interface{})
+		out := make(jsonObject, 0, len(m))
+		for _, k := range sortedKeys(m) {
+			out = append(out, jsonField{name: k, value: toJSON(schema.(*avro.MapSchema).Values(), m[k], plain)})
+		}
+		return out
+
+	case avro.Record:
+		m := v.(map[string]interface{})
+		fields := schema.(*avro.RecordSchema).Fields()
+		out := make(jsonObject, 0, len(fields))
+		for _, field := range fields {
+			out = append(out, jsonField{name: field.Name(), value: toJSON(field.Type(), m[field.Name()], plain)})
+		}
+		return out
+
+	case avro.Union:
+		u := v.(unionValue)
+		typ := schema.(*avro.UnionSchema).Types()[u.index]
+		val := toJSON(typ, u.value, plain)
+		if plain || typ.Type() == avro.Null {
+			return val
+		}
+		return jsonObject{{name: unionBranchName(typ), value: val}}
+	}
+
+	return v
+}
+
+func jsonFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	return f
+}
+
+// bytesToString encodes bytes as a string with one code point per byte, as the Avro JSON encoding requires.
+func bytesToString(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
+
+func stringToBytes(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, fmt.Errorf("invalid byte %q in string", r)
+		}
+		b = append(b, byte(r))
+	}
+	return b, nil
+}
+
+// fromJSON converts a value decoded from its JSON encoding into its plain form.
+// If plain is true, union values are not expected to be wrapped with their type name.
+func fromJSON(schema avro.Schema, v interface{}, plain bool) (interface{}, error) {
+	schema = resolveRef(schema)
+
+	switch schema.Type() {
+	case avro.Null:
+		if v == nil {
+			return nil, nil
+		}
+
+	case avro.Boolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+	case avro.Int:
+		i, err := jsonInt(v, 32)
+		if err != nil {
+			return nil, err
+		}
+		return int32(i), nil
+
+	case avro.Long:
+		return jsonInt(v, 64)
+
+	case avro.Float:
+		f, err := jsonNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		return float32(f), nil
+
+	case avro.Double:
+		return jsonNumber(v)
+
+	case avro.String:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+
+	case avro.Bytes, avro.Fixed:
+		s, ok := v.(string)
+		if !ok {
+			break
+		}
+		b, err := stringToBytes(s)
+		if err != nil {
+			return nil, err
+		}
+		if fixed, ok := schema.(*avro.FixedSchema); ok && len(b) != fixed.Size() {
+			return nil, fmt.Errorf("expected %d bytes for fixed %s, got %d", fixed.Size(), fixed.FullName(), len(b))
+		}
+		return b, nil
+
+	case avro.Enum:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+
+	case avro.Array:
+		arr, ok := v.([]interface{})
+		if !ok {
+			break
+		}
+		out := make([]interface{}, len(arr))
+		for i, elem := range arr {
+			val, err := fromJSON(schema.(*avro.ArraySchema).Items(), elem, plain)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = val
+		}
+		return out, nil
+
+	case avro.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		out := make(map[string]interface{}, len(m))
+		for k, elem := range m {
+			val, err := fromJSON(schema.(*avro.MapSchema).Values(), elem, plain)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			out[k] = val
+		}
+		return out, nil
+
+	case avro.Record:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		out := make(map[string]interface{}, len(m))
+		for _, field := range schema.(*avro.RecordSchema).Fields() {
+			elem, ok := m[field.Name()]
+			if !ok {
+				if !field.HasDefault() {
+					return nil, fmt.Errorf("missing required field %s", field.Name())
+				}
+
+				val, err := defaultValue(field.Type(), field.Default())
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", field.Name(), err)
+				}
+				out[field.Name()] = val
+				continue
+			}
+
+			val, err := fromJSON(field.Type(), elem, plain)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name(), err)
+			}
+			out[field.Name()] = val
+		}
+		return out, nil
+
+	case avro.Union:
+		types := schema.(*avro.UnionSchema).Types()
+		if v == nil {
+			if _, idx := types.Get(string(avro.Null)); idx >= 0 {
+				return unionValue{index: idx}, nil
+			}
+			break
+		}
+
+		if plain {
+			for i, typ := range types {
+				if val, err := fromJSON(typ, v, plain); err == nil {
+					return unionValue{index: i, value: val}, nil
+				}
+			}
+			break
+		}
+
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			break
+		}
+		for name, elem := range m {
+			for i, typ := range types {
+				if unionBranchName(typ) != name {
+					continue
+				}
+
+				val, err := fromJSON(typ, elem, plain)
+				if err != nil {
+					return nil, err
+				}
+				return unionValue{index: i, value: val}, nil
+			}
+			return nil, fmt.Errorf("unknown union type %s", name)
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected value %v for %s", v, schema.Type())
+}
+
+// jsonNumberAPI decodes JSON numbers as jsoniter.Number.
+var jsonNumberAPI = jsoniter.Config{UseNumber: true}.Froze()
+
+// defaultValue converts a field default into its plain form.
+func defaultValue(schema avro.Schema, def interface{}) (interface{}, error) {
+	// Defaults are kept in the form they were parsed in, which marshals to their JSON encoding.
+	b, err := jsoniter.Marshal(def)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err = jsonNumberAPI.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	if union, ok := resolveRef(schema).(*avro.UnionSchema); ok {
+		// The default of a union is for its first type.
+		val, err := fromJSON(union.Types()[0], v, true)
+		if err != nil {
+			return nil, err
+		}
+		return unionValue{index: 0, value: val}, nil
+	}
+
+	return fromJSON(schema, v, true)
+}
+
+// numberString returns the text of a JSON number decoded with UseNumber.
+func numberString(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return string(n), true
+	case jsoniter.Number:
+		return string(n), true
+	}
+	return "", false
+}
+
+func jsonInt(v interface{}, bits int) (int64, error) {
+	n, ok := numberString(v)
+	if !ok {
+		return 0, fmt.Errorf("unexpected value %v for integer", v)
+	}
+
+	return strconv.ParseInt(n, 10, bits)
+}
+
+func jsonNumber(v interface{}) (float64, error) {
+	if n, ok := numberString(v); ok {
+		return strconv.ParseFloat(n, 64)
+	}
+
+	switch val := v.(type) {
+	case string:
+		switch val {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+	}
+
+	return 0, errors.New("unexpected value for number")
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
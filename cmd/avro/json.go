@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/ocf"
+)
+
+func init() {
+	register("tojson", command{
+		usage: "[-plain] [-pretty] FILE",
+		help:  "Prints the records of a container file as JSON",
+		run:   runToJSON,
+	})
+	register("fromjson", command{
+		usage: "-schema-file FILE [-plain] [-codec CODEC] FILE",
+		help:  "Converts JSON records into a container file on stdout",
+		run:   runFromJSON,
+	})
+}
+
+func runToJSON(env env, args []string) error {
+	fs := newFlagSet("tojson", env)
+	plain := fs.Bool("plain", false, "Do not wrap union values with their type name")
+	pretty := fs.Bool("pretty", false, "Indent the JSON output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	enc := jsoniter.NewEncoder(env.stdout)
+	if *pretty {
+		enc.SetIndent("", "  ")
+	}
+
+	return withDecoder(env, fs.Arg(0), func(dec *ocf.Decoder, schema avro.Schema) error {
+		return eachDatum(dec, schema, func(v interface{}) error {
+			return enc.Encode(toJSON(schema, v, *plain))
+		})
+	})
+}
+
+func runFromJSON(env env, args []string) error {
+	fs := newFlagSet("fromjson", env)
+	schemaFile := fs.String("schema-file", "", "The file containing the schema of the records")
+	plain := fs.Bool("plain", false, "Union values are not wrapped with their type name")
+	codec := fs.String("codec", string(ocf.Null), "The output codec")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *schemaFile == "" {
+		return errUsage
+	}
+
+	schema, err := avro.ParseFiles(*schemaFile)
+	if err != nil {
+		return err
+	}
+
+	f, err := open(env, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	out, err := newDatumWriter(env.stdout, schema, ocf.CodecName(*codec), nil)
+	if err != nil {
+		return err
+	}
+
+	dec := jsoniter.NewDecoder(bufio.NewReader(f))
+	dec.UseNumber()
+	for {
+		var j interface{}
+		if err = dec.Decode(&j); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+
+		v, err := fromJSON(schema, j, *plain)
+		if err != nil {
+			return err
+		}
+		if err = out.Write(v); err != nil {
+			return err
+		}
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xl4hub/hamba-avro/lint"
+)
+
+func init() {
+	register("lint", command{
+		usage: "[-json] [-disable RULE,...] SCHEMA_FILE...",
+		help:  "Checks schemas against naming and style conventions",
+		run:   runLint,
+	})
+}
+
+func runLint(env env, args []string) error {
+	fs := newFlagSet("lint", env)
+	asJSON := fs.Bool("json", false, "Print the issues as JSON")
+	disable := fs.String("disable", "", "A comma separated list of rules to disable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+
+	disabled := map[string]bool{}
+	for _, name := range strings.Split(*disable, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			disabled[name] = true
+		}
+	}
+	var rules []lint.Rule
+	for _, rule := range lint.DefaultRules() {
+		if !disabled[rule.Name()] {
+			rules = append(rules, rule)
+		}
+	}
+	linter := lint.New(rules...)
+
+	issues := []lint.Issue{}
+	for _, path := range fs.Args() {
+		schema, err := parseSchemaFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		issues = append(issues, linter.Lint(schema)...)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			if _, err := fmt.Fprintln(env.stdout, issue.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("found %d issues", len(issues))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+Command avro is a tool for inspecting and converting Avro Object Container Files and schemas.
+
+Usage:
+
+	avro <command> [flags] [args]
+
+Run "avro help" for the list of commands.
+*/
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// errUsage is returned when a command is called with invalid arguments.
+var errUsage = errors.New("invalid usage")
+
+// env is the environment a command runs in.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	usage string
+	help  string
+	run   func(env env, args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(name string, cmd command) {
+	commands[name] = cmd
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+func run(args []string, env env) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(env.stderr)
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(env.stderr, "avro: unknown command %q\n", args[0])
+		printUsage(env.stderr)
+		return 2
+	}
+
+	if err := cmd.run(env, args[1:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			_, _ = fmt.Fprintf(env.stderr, "usage: avro %s %s\n", args[0], cmd.usage)
+			return 2
+		}
+
+		_, _ = fmt.Fprintf(env.stderr, "avro %s: %v\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("usage: avro <command> [flags] [args]\n\nCommands:\n")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(&b, "  %-12s %s\n", name, commands[name].help)
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+// newFlagSet creates a flag set for the named command that reports errors to env.
+func newFlagSet(name string, env env) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	return fs
+}
+
+// open opens the named file, or stdin if the name is "-".
+func open(env env, name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return ioutil.NopCloser(env.stdin), nil
+	}
+
+	return os.Open(filepath.Clean(name))
+}
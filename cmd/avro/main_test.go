@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro/ocf"
+	"github.com/xl4hub/hamba-avro/registry"
+	"github.com/xl4hub/hamba-avro/registry/server"
+)
+
+const fullJSON = `{"strings":["string1","string2","string3","string4","string5"],"longs":[1,2,3,4,5],"enum":"C",` +
+	`"map":{"key1":1,"key2":2,"key3":3,"key4":4,"key5":5},"nullable":{"string":"union value"},` +
+	`"fixed":"\u0001\u0002\u0003\u0004\u0001\u0002\u0003\u0004\u0001\u0002\u0003\u0004\u0001\u0002\u0003\u0004",` +
+	`"record":{"long":1925639126735,"string":"I am a test record","int":666,"float":7171.17,"double":916734926348163,"bool":true}}`
+
+func runCmd(stdin []byte, args ...string) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	code := run(args, env{stdin: bytes.NewReader(stdin), stdout: stdout, stderr: stderr})
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCmd(nil)
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "tojson")
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	code, _, stderr := runCmd(nil, "foo")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "foo"`)
+}
+
+func TestRun_InvalidArgs(t *testing.T) {
+	code, _, stderr := runCmd(nil, "getschema")
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: avro getschema FILE")
+}
+
+func TestGetSchema(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "getschema", "../../testdata/full.avro")
+
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, `{"name":"org.hamba.avro.FullRecord","type":"record"`))
+}
+
+func TestGetSchema_FileNotFound(t *testing.T) {
+	code, _, stderr := runCmd(nil, "getschema", "../../testdata/missing.avro")
+
+	assert.Equal(t, 1, code)
+	assert.NotEmpty(t, stderr)
+}
+
+func TestGetMeta(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "getmeta", "../../testdata/full-deflate.avro")
+
+	assert.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, "avro.codec\tdeflate\navro.schema\t{"))
+}
+
+func TestGetMeta_Key(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "getmeta", "-key", "avro.codec", "../../testdata/full-snappy.avro")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "snappy\n", stdout)
+}
+
+func TestGetMeta_MissingKey(t *testing.T) {
+	code, _, _ := runCmd(nil, "getmeta", "-key", "foo", "../../testdata/full-snappy.avro")
+
+	assert.Equal(t, 1, code)
+}
+
+func TestCount(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "count", "../../testdata/full.avro")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1\n", stdout)
+}
+
+func TestToJSON(t *testing.T) {
+	files := []string{"full.avro", "full-deflate.avro", "full-snappy.avro"}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			code, stdout, _ := runCmd(nil, "tojson", filepath.Join("../../testdata", file))
+
+			assert.Equal(t, 0, code)
+			assert.Equal(t, fullJSON+"\n", stdout)
+		})
+	}
+}
+
+func TestToJSON_Plain(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "tojson", "-plain", "../../testdata/full.avro")
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, `"nullable":"union value"`)
+}
+
+func TestToJSON_InvalidFile(t *testing.T) {
+	code, _, _ := runCmd(nil, "tojson", "../../testdata/deflate-invalid-data.avro")
+
+	assert.Equal(t, 1, code)
+}
+
+func TestFromJSON(t *testing.T) {
+	schema := writeFile(t, "schema.avsc", readFile(t, "full.avro", "getschema"))
+
+	code, avroFile, stderr := runCmd([]byte(fullJSON+"\n"+fullJSON), "fromjson", "-schema-file", schema, "-codec", "deflate", "-")
+	require.Equal(t, 0, code, stderr)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "tojson", "-")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, fullJSON+"\n"+fullJSON+"\n", stdout)
+}
+
+func TestFromJSON_Plain(t *testing.T) {
+	schema := writeFile(t, "schema.avsc", `{"type":"record","name":"test","fields":[
+		{"name":"a","type":["null","long","string"]},
+		{"name":"b","type":"string","default":"foo"}
+	]}`)
+
+	code, avroFile, _ := runCmd([]byte(`{"a":"bar"} {"a":null} {"a":1}`), "fromjson", "-plain", "-schema-file", schema, "-")
+	require.Equal(t, 0, code)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "tojson", "-")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `{"a":{"string":"bar"},"b":"foo"}`+"\n"+`{"a":null,"b":"foo"}`+"\n"+`{"a":{"long":1},"b":"foo"}`+"\n", stdout)
+}
+
+func TestFromJSON_InvalidRecord(t *testing.T) {
+	schema := writeFile(t, "schema.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"int"}]}`)
+
+	code, _, stderr := runCmd([]byte(`{"b":1}`), "fromjson", "-schema-file", schema, "-")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing required field a")
+}
+
+func TestCat(t *testing.T) {
+	in := writeFile(t, "in.avro", longFile(t, 10))
+
+	code, avroFile, _ := runCmd(nil, "cat", "-offset", "3", "-limit", "4", in)
+	require.Equal(t, 0, code)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "tojson", "-")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "3\n4\n5\n6\n", stdout)
+}
+
+func TestConcat(t *testing.T) {
+	in := writeFile(t, "in.avro", longFile(t, 2))
+
+	code, avroFile, _ := runCmd(nil, "concat", in, in)
+	require.Equal(t, 0, code)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "count", "-")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "4\n", stdout)
+}
+
+func TestConcat_CopiesBlocks(t *testing.T) {
+	in, err := ioutil.ReadFile("../../testdata/full-deflate.avro")
+	require.NoError(t, err)
+
+	code, avroFile, _ := runCmd(nil, "concat", "../../testdata/full-deflate.avro", "../../testdata/full-snappy.avro")
+	require.Equal(t, 0, code)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "getmeta", "-key", "avro.codec", "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "deflate\n", stdout)
+
+	// The deflate block is copied unchanged.
+	block := in[len(in)-100:]
+	assert.True(t, bytes.Contains([]byte(avroFile), block))
+
+	code, stdout, _ = runCmd([]byte(avroFile), "tojson", "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, fullJSON+"\n"+fullJSON+"\n", stdout)
+}
+
+func TestConcat_SchemaMismatch(t *testing.T) {
+	in := writeFile(t, "in.avro", longFile(t, 2))
+
+	code, _, stderr := runCmd(nil, "concat", in, "../../testdata/full.avro")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "schema does not match")
+}
+
+func TestRecodec(t *testing.T) {
+	code, avroFile, _ := runCmd(nil, "recodec", "-codec", "snappy", "../../testdata/full-deflate.avro")
+	require.Equal(t, 0, code)
+
+	code, stdout, _ := runCmd([]byte(avroFile), "getmeta", "-key", "avro.codec", "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "snappy\n", stdout)
+
+	code, stdout, _ = runCmd([]byte(avroFile), "tojson", "-")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, fullJSON+"\n", stdout)
+}
+
+func TestRecodec_RequiresCodec(t *testing.T) {
+	code, _, _ := runCmd(nil, "recodec", "../../testdata/full-deflate.avro")
+
+	assert.Equal(t, 2, code)
+}
+
+func TestRepair(t *testing.T) {
+	data := []byte(longFile(t, 3))
+	data[len(data)-1] ^= 0xff
+	in := writeFile(t, "in.avro", string(data))
+
+	code, avroFile, stderr := runCmd(nil, "repair", in)
+	require.Equal(t, 0, code)
+	assert.Contains(t, stderr, "skipped")
+
+	code, stdout, _ := runCmd([]byte(avroFile), "count", "-")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "2\n", stdout)
+}
+
+func TestCompat(t *testing.T) {
+	reader := writeFile(t, "reader.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"long"},{"name":"b","type":"string","default":""}]}`)
+	writer := writeFile(t, "writer.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"int"}]}`)
+
+	code, stdout, _ := runCmd(nil, "compat", reader, writer)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "schemas are compatible\n", stdout)
+}
+
+func TestCompat_Incompatible(t *testing.T) {
+	reader := writeFile(t, "reader.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"int"}]}`)
+	writer := writeFile(t, "writer.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"long"}]}`)
+
+	code, _, stderr := runCmd(nil, "compat", reader, writer)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "schemas are not compatible")
+}
+
+func TestDiff(t *testing.T) {
+	old := writeFile(t, "old.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"int"}]}`)
+	updated := writeFile(t, "new.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"long"},{"name":"b","type":"string","default":""}]}`)
+
+	code, stdout, _ := runCmd(nil, "diff", old, updated)
+
+	assert.Equal(t, 0, code)
+	want := "test.a: type changed from int to long (backward compatible)\n" +
+		"test.b: field added (fully compatible)\n"
+	assert.Equal(t, want, stdout)
+}
+
+func TestDiff_JSON(t *testing.T) {
+	old := writeFile(t, "old.avsc", `{"type":"enum","name":"test","symbols":["A"]}`)
+	updated := writeFile(t, "new.avsc", `{"type":"enum","name":"test","symbols":["A","B"]}`)
+
+	code, stdout, _ := runCmd(nil, "diff", "-json", old, updated)
+
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `[{"type":"symbol-added","path":"test","new":"B","backward":true,"forward":false}]`, stdout)
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	code, stdout, _ := runCmd(nil, "diff", "../../testdata/schema.avsc", "../../testdata/schema.avsc")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "no changes\n", stdout)
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{typ: "CRC64-AVRO", want: "8f014872634503c7"},
+		{typ: "MD5", want: "095d71cf12556b9d5e330ad575b3df5d"},
+		{typ: "SHA256", want: "e9e5c1c9e4f6277339d1bcde0733a59bd42f8731f449da6dc13010a916930d48"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.typ, func(t *testing.T) {
+			code, stdout, _ := runCmd(nil, "fingerprint", "-type", test.typ, "../../testdata/schema.avsc")
+
+			assert.Equal(t, 0, code)
+			assert.Equal(t, test.want+"\n", stdout)
+		})
+	}
+}
+
+func TestFingerprint_UnknownType(t *testing.T) {
+	code, _, _ := runCmd(nil, "fingerprint", "-type", "foo", "../../testdata/schema.avsc")
+
+	assert.Equal(t, 1, code)
+}
+
+func readFile(t *testing.T, name string, cmd string) string {
+	t.Helper()
+
+	code, stdout, stderr := runCmd(nil, cmd, filepath.Join("../../testdata", name))
+	require.Equal(t, 0, code, stderr)
+
+	return stdout
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "avro")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0o600))
+
+	return path
+}
+
+func longFile(t *testing.T, n int) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	enc, err := ocf.NewEncoder(`"long"`, buf, ocf.WithBlockLength(1))
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		require.NoError(t, enc.Encode(int64(i)))
+	}
+	require.NoError(t, enc.Close())
+
+	return buf.String()
+}
+
+func TestLint(t *testing.T) {
+	path := writeFile(t, "schema.avsc", `{"type":"record","name":"test","doc":"A test.","fields":[{"name":"a","type":"int","doc":"A."}]}`)
+
+	code, stdout, stderr := runCmd(nil, "lint", path)
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "test: type name \"test\" is not PascalCase (type-name-style)\n", stdout)
+	assert.Equal(t, "avro lint: found 1 issues\n", stderr)
+}
+
+func TestLint_Disable(t *testing.T) {
+	path := writeFile(t, "schema.avsc", `{"type":"record","name":"test","fields":[{"name":"a","type":"int"}]}`)
+
+	code, stdout, _ := runCmd(nil, "lint", "-disable", "type-name-style,missing-doc", path)
+
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+}
+
+func TestLint_JSON(t *testing.T) {
+	path := writeFile(t, "schema.avsc", `{"type":"enum","name":"Test","doc":"A test.","symbols":["a"]}`)
+
+	code, stdout, _ := runCmd(nil, "lint", "-json", path)
+
+	assert.Equal(t, 1, code)
+	assert.JSONEq(t, `[{"rule":"symbol-style","path":"Test","message":"symbol \"a\" is not UPPER_SNAKE_CASE"}]`, stdout)
+}
+
+func newRegistryServer(t *testing.T) (*httptest.Server, *registry.MemoryRegistry) {
+	t.Helper()
+
+	reg := registry.NewMemoryRegistry()
+	s := httptest.NewServer(server.New(reg))
+	t.Cleanup(s.Close)
+	return s, reg
+}
+
+func TestRegistry(t *testing.T) {
+	src, srcReg := newRegistryServer(t)
+	_, err := srcReg.ImportSchema("user", 7, 1, `{"type":"record","name":"User","fields":[{"name":"id","type":"long"}]}`)
+	require.NoError(t, err)
+	_, err = srcReg.ImportSchema("order", 9, 2, `{"type":"record","name":"Order","fields":[{"name":"id","type":"long"}]}`)
+	require.NoError(t, err)
+	tmp, err := ioutil.TempDir("", "avro")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmp) })
+	dir := filepath.Join(tmp, "snapshot")
+
+	code, stdout, stderr := runCmd(nil, "registry", src.URL, dir)
+
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "user\tversion 1\tid 7\norder\tversion 2\tid 9\n", stdout)
+	assert.FileExists(t, filepath.Join(dir, "manifest.json"))
+
+	dst, dstReg := newRegistryServer(t)
+
+	code, stdout, stderr = runCmd(nil, "registry", "-subjects", "ord*", "-import", dir, dst.URL)
+
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "order\tversion 2\tid 9\n", stdout)
+	v, err := dstReg.GetSchemaVersion("order", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 9, v.ID)
+	subjects, err := dstReg.GetSubjects()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"order"}, subjects)
+	client, err := registry.NewClient(dst.URL)
+	require.NoError(t, err)
+	mode, err := client.GetMode("")
+	require.NoError(t, err)
+	assert.Equal(t, registry.ModeReadWrite, mode)
+}
+
+func TestRegistry_CreatesWithoutImport(t *testing.T) {
+	src, srcReg := newRegistryServer(t)
+	_, err := srcReg.ImportSchema("user", 7, 3, `"string"`)
+	require.NoError(t, err)
+	dst, dstReg := newRegistryServer(t)
+
+	code, stdout, stderr := runCmd(nil, "registry", src.URL, dst.URL)
+
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "user\tversion 3\tid 7\n", stdout)
+	v, err := dstReg.GetSchemaVersion("user", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.ID)
+}
+
+func TestRegistry_DryRun(t *testing.T) {
+	src, srcReg := newRegistryServer(t)
+	_, _, err := srcReg.CreateSchema("user", `"string"`)
+	require.NoError(t, err)
+	dst, dstReg := newRegistryServer(t)
+
+	code, stdout, stderr := runCmd(nil, "registry", "-dry-run", src.URL, dst.URL)
+
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "user\tversion 1\tid 1\n", stdout)
+	subjects, err := dstReg.GetSubjects()
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestRegistry_Errors(t *testing.T) {
+	code, _, stderr := runCmd(nil, "registry", "http://localhost")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: avro registry")
+
+	code, _, stderr = runCmd(nil, "registry", filepath.Join(os.TempDir(), "does-not-exist"), "http://localhost")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "avro registry: ")
+}
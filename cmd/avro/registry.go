@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xl4hub/hamba-avro/registry"
+)
+
+func init() {
+	register("registry", command{
+		usage: "[-subjects PATTERN,...] [-import] [-dry-run] SOURCE DESTINATION",
+		help:  "Copies schemas between registries and snapshot directories",
+		run:   runRegistry,
+	})
+}
+
+// runRegistry copies the schemas of SOURCE to DESTINATION, each either the url
+// of a registry or a snapshot directory.
+func runRegistry(env env, args []string) error {
+	fs := newFlagSet("registry", env)
+	subjects := fs.String("subjects", "", "A comma separated list of subject patterns to copy")
+	importMode := fs.Bool("import", false, "Keep the ids and version numbers, putting a destination registry in IMPORT mode while copying")
+	dryRun := fs.Bool("dry-run", false, "Print the versions that would be copied, without copying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	src, dst := fs.Arg(0), fs.Arg(1)
+
+	var opts []registry.MigrateFunc
+	for _, pattern := range strings.Split(*subjects, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			opts = append(opts, registry.WithSubjects(pattern))
+		}
+	}
+
+	ctx := context.Background()
+	var from registry.Registry
+	if isRegistryURL(src) {
+		client, err := newRegistryClient(src)
+		if err != nil {
+			return err
+		}
+		from = client
+	} else {
+		mem := registry.NewMemoryRegistry()
+		if _, err := registry.Import(ctx, src, mem, opts...); err != nil {
+			return err
+		}
+		from = mem
+	}
+
+	if *dryRun {
+		opts = append(opts, registry.WithDryRun())
+	}
+
+	var versions []registry.SchemaVersion
+	if isRegistryURL(dst) {
+		client, err := newRegistryClient(dst)
+		if err != nil {
+			return err
+		}
+		if versions, err = migrate(ctx, from, client, *importMode && !*dryRun, opts); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if versions, err = registry.Export(ctx, from, dst, opts...); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range versions {
+		if _, err := fmt.Fprintf(env.stdout, "%s\tversion %d\tid %d\n", v.Subject, v.Version, v.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate copies the versions of src to the dst registry. If importMode is set, the
+// versions keep their ids and version numbers and dst is put in IMPORT mode while they
+// are copied, otherwise dst assigns them.
+func migrate(
+	ctx context.Context,
+	src registry.Registry,
+	dst *registry.Client,
+	importMode bool,
+	opts []registry.MigrateFunc,
+) (versions []registry.SchemaVersion, err error) {
+	if !importMode {
+		return registry.Migrate(ctx, src, dst, append(opts, registry.WithCreate())...)
+	}
+
+	mode, err := dst.GetModeContext(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = dst.SetModeContext(ctx, "", registry.ModeImport); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if restoreErr := dst.SetModeContext(ctx, "", mode); restoreErr != nil && err == nil {
+			err = restoreErr
+		}
+	}()
+
+	return registry.Migrate(ctx, src, dst, opts...)
+}
+
+func isRegistryURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func newRegistryClient(url string) (*registry.Client, error) {
+	return registry.NewClient(url)
+}
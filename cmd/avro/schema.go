@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+func init() {
+	register("compat", command{
+		usage: "READER_SCHEMA_FILE WRITER_SCHEMA_FILE",
+		help:  "Checks if data written with the writer schema can be read with the reader schema",
+		run:   runCompat,
+	})
+	register("diff", command{
+		usage: "[-json] OLD_SCHEMA_FILE NEW_SCHEMA_FILE",
+		help:  "Prints the changes between two versions of a schema",
+		run:   runDiff,
+	})
+	register("fingerprint", command{
+		usage: "[-type CRC64-AVRO|MD5|SHA256] SCHEMA_FILE",
+		help:  "Prints the fingerprint of a schema",
+		run:   runFingerprint,
+	})
+}
+
+func runCompat(env env, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	reader, err := avro.ParseFiles(args[0])
+	if err != nil {
+		return err
+	}
+	writer, err := avro.ParseFiles(args[1])
+	if err != nil {
+		return err
+	}
+
+	if err = avro.NewSchemaCompatibility().Compatible(reader, writer); err != nil {
+		return fmt.Errorf("schemas are not compatible: %w", err)
+	}
+
+	_, err = fmt.Fprintln(env.stdout, "schemas are compatible")
+	return err
+}
+
+func runFingerprint(env env, args []string) error {
+	fs := newFlagSet("fingerprint", env)
+	typ := fs.String("type", string(avro.CRC64Avro), "The fingerprint algorithm")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	schema, err := avro.ParseFiles(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fp, err := schema.FingerprintUsing(avro.FingerprintType(*typ))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(env.stdout, hex.EncodeToString(fp))
+	return err
+}
+
+func runDiff(env env, args []string) error {
+	fs := newFlagSet("diff", env)
+	asJSON := fs.Bool("json", false, "Print the changes as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+
+	// The versions share names, so each is parsed with its own cache.
+	old, err := parseSchemaFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	updated, err := parseSchemaFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	changes := avro.Diff(old, updated)
+
+	if *asJSON {
+		if changes == nil {
+			changes = []avro.Change{}
+		}
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+
+	if len(changes) == 0 {
+		_, err = fmt.Fprintln(env.stdout, "no changes")
+		return err
+	}
+	return avro.WriteDiff(env.stdout, changes)
+}
+
+func parseSchemaFile(path string) (avro.Schema, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return avro.ParseWithCache(string(b), "", &avro.SchemaCache{})
+}
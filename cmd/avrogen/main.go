@@ -0,0 +1,68 @@
+/*
+Command avrogen generates Go code for Avro protocols.
+
+Usage:
+
+	avrogen -pkg NAME [-o FILE] PROTOCOL.avpr|PROTOCOL.avdl
+
+Protocols with an .avdl extension are parsed as Avro IDL.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/gen"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("avrogen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	pkg := fs.String("pkg", "", "The package name of the generated code")
+	out := fs.String("o", "", "The output file, defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *pkg == "" {
+		_, _ = fmt.Fprintln(stderr, "usage: avrogen -pkg NAME [-o FILE] PROTOCOL.avpr|PROTOCOL.avdl")
+		return 2
+	}
+
+	parse := avro.ParseProtocolFile
+	if filepath.Ext(fs.Arg(0)) == ".avdl" {
+		parse = avro.ParseIDLFile
+	}
+	proto, err := parse(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+
+	buf := &bytes.Buffer{}
+	if err = gen.Protocol(buf, proto, gen.Config{PackageName: *pkg}); err != nil {
+		_, _ = fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		_, err = stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(*out, buf.Bytes(), 0o600)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "avrogen: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	stdout := &bytes.Buffer{}
+
+	code := run([]string{"-pkg", "echo", "../../testdata/echo.avpr"}, stdout, &bytes.Buffer{})
+
+	assert.Equal(t, 0, code)
+	want, err := ioutil.ReadFile("../../gen/testdata/echo.golden")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), stdout.String())
+}
+
+func TestRun_OutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "avrogen")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	out := filepath.Join(dir, "echo.go")
+
+	code := run([]string{"-pkg", "echo", "-o", out, "../../testdata/echo.avpr"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.Equal(t, 0, code)
+	assert.FileExists(t, out)
+}
+
+func TestRun_IDL(t *testing.T) {
+	stdout := &bytes.Buffer{}
+
+	code := run([]string{"-pkg", "simple", "../../testdata/idl/simple.avdl"}, stdout, &bytes.Buffer{})
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), "type Simple interface {")
+}
+
+func TestRun_RequiresPackage(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	code := run([]string{"../../testdata/echo.avpr"}, &bytes.Buffer{}, stderr)
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "usage: avrogen")
+}
+
+func TestRun_InvalidProtocol(t *testing.T) {
+	code := run([]string{"-pkg", "echo", "../../testdata/missing.avpr"}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.Equal(t, 1, code)
+}
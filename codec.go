@@ -57,6 +57,7 @@ func (r *Reader) ReadVal(schema Schema, obj interface{}) {
 	}
 
 	decoder.Decode(ptr, r)
+	wrapDecodeError(r, "")
 }
 
 // WriteVal writes the Avro encoding of obj.
@@ -70,6 +71,7 @@ func (w *Writer) WriteVal(schema Schema, val interface{}) {
 	}
 
 	encoder.Encode(reflect2.PtrOf(val), w)
+	wrapEncodeError(w, "")
 }
 
 func (c *frozenConfig) DecoderOf(schema Schema, typ reflect2.Type) ValDecoder {
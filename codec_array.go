@@ -1,9 +1,7 @@
 package avro
 
 import (
-	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"unsafe"
 
@@ -56,12 +54,12 @@ func (d *arrayDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		for i := start; i < size; i++ {
 			elemPtr := sliceType.UnsafeGetIndex(ptr, i)
 			d.decoder.Decode(elemPtr, r)
+			if decodeFailed(r) {
+				wrapDecodeError(r, indexPath(i))
+				return
+			}
 		}
 	}
-
-	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
-		r.Error = fmt.Errorf("%v: %w", d.typ, r.Error)
-	}
 }
 
 func encoderOfArray(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
@@ -92,16 +90,20 @@ func (e *arrayEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 			for j := i; j < i+blockLength && j < length; j++ {
 				elemPtr := e.typ.UnsafeGetIndex(ptr, j)
 				e.encoder.Encode(elemPtr, w)
+				if encodeFailed(w) {
+					wrapEncodeError(w, indexPath(j))
+					break
+				}
 				count++
 			}
 
 			return count
 		})
+
+		if encodeFailed(w) {
+			return
+		}
 	}
 
 	w.WriteBlockHeader(0, 0)
-
-	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
-		w.Error = fmt.Errorf("%v: %w", e.typ, w.Error)
-	}
 }
@@ -1,9 +1,7 @@
 package avro
 
 import (
-	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"unsafe"
 
@@ -56,17 +54,17 @@ func (d *mapDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		}
 
 		for i := int64(0); i < l; i++ {
-			keyPtr := reflect2.PtrOf(r.ReadString())
+			key := r.ReadString()
 			elemPtr := d.elemType.UnsafeNew()
 			d.decoder.Decode(elemPtr, r)
+			if decodeFailed(r) {
+				wrapDecodeError(r, keyPath(key))
+				return
+			}
 
-			d.mapType.UnsafeSetIndex(ptr, keyPtr, elemPtr)
+			d.mapType.UnsafeSetIndex(ptr, reflect2.PtrOf(key), elemPtr)
 		}
 	}
-
-	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
-		r.Error = fmt.Errorf("%v: %w", d.mapType, r.Error)
-	}
 }
 
 func encoderOfMap(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
@@ -97,19 +95,20 @@ func (e *mapEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 			var i int
 			for i = 0; iter.HasNext() && i < blockLength; i++ {
 				keyPtr, elemPtr := iter.UnsafeNext()
-				w.WriteString(*((*string)(keyPtr)))
+				key := *((*string)(keyPtr))
+				w.WriteString(key)
 				e.encoder.Encode(elemPtr, w)
+				if encodeFailed(w) {
+					wrapEncodeError(w, keyPath(key))
+					break
+				}
 			}
 
 			return int64(i)
 		})
 
-		if wrote == 0 {
+		if wrote == 0 || encodeFailed(w) {
 			break
 		}
 	}
-
-	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
-		w.Error = fmt.Errorf("%v: %w", e.mapType, w.Error)
-	}
 }
@@ -1,9 +1,7 @@
 package avro
 
 import (
-	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"unsafe"
 
@@ -64,6 +62,7 @@ func decoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDec
 		// Skip field if it doesnt exist
 		if sf == nil {
 			fields = append(fields, &structFieldDecoder{
+				name:    field.Name(),
 				decoder: createSkipDecoder(field.Type()),
 			})
 			continue
@@ -71,6 +70,7 @@ func decoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDec
 
 		dec := decoderOfType(cfg, field.Type(), sf.Field[len(sf.Field)-1].Type())
 		fields = append(fields, &structFieldDecoder{
+			name:    field.Name(),
 			field:   sf.Field,
 			decoder: dec,
 		})
@@ -87,10 +87,15 @@ type structDecoder struct {
 func (d *structDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	for _, field := range d.fields {
 		field.Decode(ptr, r)
+		if decodeFailed(r) {
+			wrapDecodeError(r, fieldPath(field.name))
+			return
+		}
 	}
 }
 
 type structFieldDecoder struct {
+	name    string
 	field   []*reflect2.UnsafeStructField
 	decoder ValDecoder
 }
@@ -120,12 +125,6 @@ func (d *structFieldDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		}
 	}
 	d.decoder.Decode(fieldPtr, r)
-
-	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
-		for _, f := range d.field {
-			r.Error = fmt.Errorf("%s: %w", f.Name(), r.Error)
-		}
-	}
 }
 
 func encoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
@@ -152,6 +151,7 @@ func encoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEnc
 				if field.Type().Type() == Union && field.Type().(*UnionSchema).Nullable() {
 					defaultType := reflect2.TypeOf(&def)
 					fields = append(fields, &structFieldEncoder{
+						name:       field.Name(),
 						defaultPtr: reflect2.PtrOf(&def),
 						encoder:    encoderOfPtrUnion(cfg, field.Type(), defaultType),
 					})
@@ -161,6 +161,7 @@ func encoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEnc
 
 			defaultType := reflect2.TypeOf(def)
 			fields = append(fields, &structFieldEncoder{
+				name:       field.Name(),
 				defaultPtr: reflect2.PtrOf(def),
 				encoder:    encoderOfType(cfg, field.Type(), defaultType),
 			})
@@ -169,6 +170,7 @@ func encoderOfStruct(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEnc
 		}
 
 		fields = append(fields, &structFieldEncoder{
+			name:    field.Name(),
 			field:   sf.Field,
 			encoder: encoderOfType(cfg, field.Type(), sf.Field[len(sf.Field)-1].Type()),
 		})
@@ -185,10 +187,15 @@ type structEncoder struct {
 func (e *structEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 	for _, field := range e.fields {
 		field.Encode(ptr, w)
+		if encodeFailed(w) {
+			wrapEncodeError(w, fieldPath(field.name))
+			return
+		}
 	}
 }
 
 type structFieldEncoder struct {
+	name       string
 	field      []*reflect2.UnsafeStructField
 	defaultPtr unsafe.Pointer
 	encoder    ValEncoder
@@ -219,12 +226,6 @@ func (e *structFieldEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 		}
 	}
 	e.encoder.Encode(fieldPtr, w)
-
-	if w.Error != nil && !errors.Is(w.Error, io.EOF) {
-		for _, f := range e.field {
-			w.Error = fmt.Errorf("%s: %w", f.Name(), w.Error)
-		}
-	}
 }
 
 func decoderOfRecord(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
@@ -265,13 +266,13 @@ func (d *recordMapDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	for _, field := range d.fields {
 		elem := d.elemType.UnsafeNew()
 		field.decoder.Decode(elem, r)
+		if decodeFailed(r) {
+			wrapDecodeError(r, fieldPath(field.name))
+			return
+		}
 
 		d.mapType.UnsafeSetIndex(ptr, reflect2.PtrOf(field), elem)
 	}
-
-	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
-		r.Error = fmt.Errorf("%v: %w", d.mapType, r.Error)
-	}
 }
 
 func encoderOfRecord(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
@@ -342,10 +343,14 @@ func (e *recordMapEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 
 			defPtr := reflect2.PtrOf(field.def)
 			field.defEncoder.Encode(defPtr, w)
-			continue
+		} else {
+			field.encoder.Encode(valPtr, w)
 		}
 
-		field.encoder.Encode(valPtr, w)
+		if encodeFailed(w) {
+			wrapEncodeError(w, fieldPath(field.name))
+			return
+		}
 	}
 }
 
@@ -92,6 +92,10 @@ func (d *mapUnionDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 
 	elemPtr := d.elemType.UnsafeNew()
 	decoderOfType(d.cfg, resSchema, d.elemType).Decode(elemPtr, r)
+	if decodeFailed(r) {
+		wrapDecodeError(r, branchPath(resSchema))
+		return
+	}
 
 	d.mapType.UnsafeSetIndex(ptr, keyPtr, elemPtr)
 }
@@ -158,6 +162,9 @@ func (e *mapUnionEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 		encoder = &onePtrEncoder{encoder}
 	}
 	encoder.Encode(elemPtr, w)
+	if encodeFailed(w) {
+		wrapEncodeError(w, branchPath(schema))
+	}
 }
 
 func decoderOfPtrUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValDecoder {
@@ -196,11 +203,13 @@ func (d *unionPtrDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		newPtr := d.typ.UnsafeNew()
 		d.decoder.Decode(newPtr, r)
 		*((*unsafe.Pointer)(ptr)) = newPtr
-		return
+	} else {
+		// Reuse existing instance
+		d.decoder.Decode(*((*unsafe.Pointer)(ptr)), r)
+	}
+	if decodeFailed(r) {
+		wrapDecodeError(r, branchPath(schema))
 	}
-
-	// Reuse existing instance
-	d.decoder.Decode(*((*unsafe.Pointer)(ptr)), r)
 }
 
 func encoderOfPtrUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type) ValEncoder {
@@ -232,6 +241,9 @@ func (e *unionPtrEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 
 	w.WriteLong(e.typeIdx)
 	e.encoder.Encode(*((*unsafe.Pointer)(ptr)), w)
+	if encodeFailed(w) {
+		wrapEncodeError(w, branchPath(e.schema.Types()[e.typeIdx]))
+	}
 }
 
 func decoderOfResolvedUnion(cfg *frozenConfig, schema Schema) ValDecoder {
@@ -291,6 +303,9 @@ func (d *unionResolvedDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 		name := schemaTypeName(schema)
 		obj := map[string]interface{}{}
 		obj[name] = r.ReadNext(schema)
+		if decodeFailed(r) {
+			wrapDecodeError(r, branchPath(schema))
+		}
 
 		*pObj = obj
 		return
@@ -316,6 +331,9 @@ func (d *unionResolvedDecoder) Decode(ptr unsafe.Pointer, r *Reader) {
 	}
 
 	d.decoders[i].Decode(newPtr, r)
+	if decodeFailed(r) {
+		wrapDecodeError(r, branchPath(schema))
+	}
 	*pObj = typ.UnsafeIndirect(newPtr)
 }
 
@@ -382,12 +400,14 @@ func encoderOfResolverUnion(cfg *frozenConfig, schema Schema, typ reflect2.Type)
 
 	return &unionResolverEncoder{
 		pos:     pos,
+		path:    branchPath(schema),
 		encoder: encoder,
 	}
 }
 
 type unionResolverEncoder struct {
 	pos     int
+	path    string
 	encoder ValEncoder
 }
 
@@ -395,6 +415,7 @@ func (e *unionResolverEncoder) Encode(ptr unsafe.Pointer, w *Writer) {
 	w.WriteLong(int64(e.pos))
 
 	e.encoder.Encode(ptr, w)
+	wrapEncodeError(w, e.path)
 }
 
 func getUnionSchema(schema *UnionSchema, r *Reader) (int, Schema) {
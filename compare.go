@@ -0,0 +1,234 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Compare compares the Avro encoded data a and b of the schema in the sort order defined
+// by the Avro specification, without decoding them into Go values. The result is 0 if
+// a == b, -1 if a < b and +1 if a > b.
+//
+// Record fields are compared in order, reversed if their order is descending and skipped
+// if it is ignore. Unions are compared by branch, then by value. Maps cannot be compared,
+// returning an error.
+func Compare(schema Schema, a, b []byte) (int, error) {
+	ra := (&Reader{cfg: DefaultConfig.(*frozenConfig)}).Reset(a)
+	rb := (&Reader{cfg: DefaultConfig.(*frozenConfig)}).Reset(b)
+
+	c := comparer{a: ra, b: rb}
+	res := c.compare(schema)
+	if c.err != nil {
+		return 0, c.err
+	}
+	if ra.Error != nil {
+		return 0, fmt.Errorf("avro: reading a: %w", ra.Error)
+	}
+	if rb.Error != nil {
+		return 0, fmt.Errorf("avro: reading b: %w", rb.Error)
+	}
+	return res, nil
+}
+
+// CompareValues compares a and b in the sort order of the schema, as Compare compares
+// their encodings.
+func CompareValues(schema Schema, a, b interface{}) (int, error) {
+	return DefaultConfig.(*frozenConfig).compareValues(schema, a, b)
+}
+
+func (c *frozenConfig) compareValues(schema Schema, a, b interface{}) (int, error) {
+	dataA, err := c.Marshal(schema, a)
+	if err != nil {
+		return 0, err
+	}
+	dataB, err := c.Marshal(schema, b)
+	if err != nil {
+		return 0, err
+	}
+	return Compare(schema, dataA, dataB)
+}
+
+type comparer struct {
+	a, b *Reader
+	err  error
+}
+
+func (c *comparer) failed() bool {
+	return c.err != nil || c.a.Error != nil || c.b.Error != nil
+}
+
+func (c *comparer) compare(schema Schema) int {
+	if c.failed() {
+		return 0
+	}
+
+	switch schema.Type() {
+	case Null:
+		return 0
+
+	case Boolean:
+		return compareInt64(boolToInt64(c.a.ReadBool()), boolToInt64(c.b.ReadBool()))
+
+	case Int:
+		return compareInt64(int64(c.a.ReadInt()), int64(c.b.ReadInt()))
+
+	case Long:
+		return compareInt64(c.a.ReadLong(), c.b.ReadLong())
+
+	case Float:
+		return compareFloat64(float64(c.a.ReadFloat()), float64(c.b.ReadFloat()))
+
+	case Double:
+		return compareFloat64(c.a.ReadDouble(), c.b.ReadDouble())
+
+	case String, Bytes:
+		return bytes.Compare(c.a.ReadBytes(), c.b.ReadBytes())
+
+	case Fixed:
+		size := schema.(*FixedSchema).Size()
+		bufA, bufB := make([]byte, size), make([]byte, size)
+		c.a.Read(bufA)
+		c.b.Read(bufB)
+		return bytes.Compare(bufA, bufB)
+
+	case Enum:
+		return compareInt64(int64(c.a.ReadInt()), int64(c.b.ReadInt()))
+
+	case Ref:
+		return c.compare(schema.(*RefSchema).Schema())
+
+	case Record:
+		return c.compareRecord(schema.(*RecordSchema))
+
+	case Array:
+		return c.compareArray(schema.(*ArraySchema))
+
+	case Union:
+		types := schema.(*UnionSchema).Types()
+		idxA, idxB := c.a.ReadLong(), c.b.ReadLong()
+		if idxA != idxB {
+			return compareInt64(idxA, idxB)
+		}
+		if idxA < 0 || idxA >= int64(len(types)) {
+			c.err = fmt.Errorf("avro: unknown union type index %d", idxA)
+			return 0
+		}
+		return c.compare(types[idxA])
+
+	case Map:
+		c.err = errors.New("avro: maps cannot be compared")
+		return 0
+
+	default:
+		c.err = fmt.Errorf("avro: schema type %s is unsupported", schema.Type())
+		return 0
+	}
+}
+
+func (c *comparer) compareRecord(schema *RecordSchema) int {
+	for _, field := range schema.Fields() {
+		if field.Order() == Ignore {
+			if field.Type().Type() != Null {
+				skip := createSkipDecoder(field.Type())
+				skip.Decode(nil, c.a)
+				skip.Decode(nil, c.b)
+			}
+			continue
+		}
+
+		res := c.compare(field.Type())
+		if c.failed() {
+			return 0
+		}
+		if res == 0 {
+			continue
+		}
+		if field.Order() == Desc {
+			return -res
+		}
+		return res
+	}
+	return 0
+}
+
+func (c *comparer) compareArray(schema *ArraySchema) int {
+	itemsA, itemsB := &blockIterator{r: c.a}, &blockIterator{r: c.b}
+	for {
+		nextA, nextB := itemsA.next(), itemsB.next()
+		if c.failed() {
+			return 0
+		}
+		if !nextA || !nextB {
+			return compareInt64(boolToInt64(nextA), boolToInt64(nextB))
+		}
+
+		if res := c.compare(schema.Items()); res != 0 || c.failed() {
+			return res
+		}
+	}
+}
+
+// blockIterator iterates over the items of a blocked array.
+type blockIterator struct {
+	r    *Reader
+	n    int64
+	done bool
+}
+
+// next determines if there is another item, reading the next block header if needed.
+func (i *blockIterator) next() bool {
+	if i.done {
+		return false
+	}
+
+	if i.n == 0 {
+		i.n, _ = i.r.ReadBlockHeader()
+		if i.n == 0 {
+			i.done = true
+			return false
+		}
+	}
+	i.n--
+	return true
+}
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareFloat64 compares floats as Java does, ordering -0 before 0 and NaN after all other values.
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	nanA, nanB := math.IsNaN(a), math.IsNaN(b)
+	switch {
+	case nanA && nanB:
+		return 0
+	case nanA:
+		return 1
+	case nanB:
+		return -1
+	}
+	return compareInt64(boolToInt64(!math.Signbit(a)), boolToInt64(!math.Signbit(b)))
+}
@@ -0,0 +1,275 @@
+package avro_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+func TestCompare(t *testing.T) {
+	defer ConfigTeardown()
+
+	tests := []struct {
+		name   string
+		schema string
+		a      interface{}
+		b      interface{}
+		want   int
+	}{
+		{
+			name:   "Null",
+			schema: `"null"`,
+			a:      nil,
+			b:      nil,
+			want:   0,
+		},
+		{
+			name:   "Boolean",
+			schema: `"boolean"`,
+			a:      false,
+			b:      true,
+			want:   -1,
+		},
+		{
+			name:   "Int",
+			schema: `"int"`,
+			a:      -1,
+			b:      -2,
+			want:   1,
+		},
+		{
+			name:   "Long",
+			schema: `"long"`,
+			a:      int64(math.MinInt64),
+			b:      int64(math.MaxInt64),
+			want:   -1,
+		},
+		{
+			name:   "Float",
+			schema: `"float"`,
+			a:      float32(1.5),
+			b:      float32(1.5),
+			want:   0,
+		},
+		{
+			name:   "Double NaN",
+			schema: `"double"`,
+			a:      math.NaN(),
+			b:      math.Inf(1),
+			want:   1,
+		},
+		{
+			name:   "Double Negative Zero",
+			schema: `"double"`,
+			a:      math.Copysign(0, -1),
+			b:      float64(0),
+			want:   -1,
+		},
+		{
+			name:   "String",
+			schema: `"string"`,
+			a:      "ab",
+			b:      "b",
+			want:   -1,
+		},
+		{
+			name:   "String Prefix",
+			schema: `"string"`,
+			a:      "abc",
+			b:      "ab",
+			want:   1,
+		},
+		{
+			name:   "Bytes",
+			schema: `"bytes"`,
+			a:      []byte{0xff},
+			b:      []byte{0x01, 0x02},
+			want:   1,
+		},
+		{
+			name:   "Fixed",
+			schema: `{"type": "fixed", "name": "test", "size": 2}`,
+			a:      [2]byte{0x01, 0x02},
+			b:      [2]byte{0x01, 0x03},
+			want:   -1,
+		},
+		{
+			name:   "Enum",
+			schema: `{"type": "enum", "name": "test", "symbols": ["Z", "A"]}`,
+			a:      "Z",
+			b:      "A",
+			want:   -1,
+		},
+		{
+			name:   "Array",
+			schema: `{"type": "array", "items": "int"}`,
+			a:      []int{1, 2, 3},
+			b:      []int{1, 2},
+			want:   1,
+		},
+		{
+			name:   "Array Equal",
+			schema: `{"type": "array", "items": "int"}`,
+			a:      []int{1, 2},
+			b:      []int{1, 2},
+			want:   0,
+		},
+		{
+			name:   "Union Branch",
+			schema: `["null", "string"]`,
+			a:      map[string]interface{}{"string": "a"},
+			b:      map[string]interface{}{},
+			want:   1,
+		},
+		{
+			name:   "Union Value",
+			schema: `["null", "string"]`,
+			a:      map[string]interface{}{"string": "a"},
+			b:      map[string]interface{}{"string": "b"},
+			want:   -1,
+		},
+		{
+			name: "Record",
+			schema: `{"type": "record", "name": "test", "fields": [
+				{"name": "a", "type": "int"},
+				{"name": "b", "type": "string"}
+			]}`,
+			a:    map[string]interface{}{"a": 1, "b": "b"},
+			b:    map[string]interface{}{"a": 1, "b": "a"},
+			want: 1,
+		},
+		{
+			name: "Record Descending",
+			schema: `{"type": "record", "name": "test", "fields": [
+				{"name": "a", "type": "int", "order": "descending"},
+				{"name": "b", "type": "string"}
+			]}`,
+			a:    map[string]interface{}{"a": 1, "b": "a"},
+			b:    map[string]interface{}{"a": 2, "b": "b"},
+			want: 1,
+		},
+		{
+			name: "Record Ignore",
+			schema: `{"type": "record", "name": "test", "fields": [
+				{"name": "a", "type": {"type": "array", "items": "string"}, "order": "ignore"},
+				{"name": "b", "type": {"type": "map", "values": "int"}, "order": "ignore"},
+				{"name": "c", "type": "int"}
+			]}`,
+			a:    map[string]interface{}{"a": []string{"z", "z"}, "b": map[string]int{"a": 1}, "c": 1},
+			b:    map[string]interface{}{"a": []string{"a"}, "b": map[string]int{}, "c": 1},
+			want: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema := avro.MustParse(test.schema)
+			a, err := avro.Marshal(schema, test.a)
+			require.NoError(t, err)
+			b, err := avro.Marshal(schema, test.b)
+			require.NoError(t, err)
+
+			got, err := avro.Compare(schema, a, b)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+
+			got, err = avro.Compare(schema, b, a)
+			require.NoError(t, err)
+			assert.Equal(t, -test.want, got)
+		})
+	}
+}
+
+func TestCompare_BlockedArrays(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(`{"type": "array", "items": "int"}`)
+	// [1, 2, 3] in a single block, and in blocks of one and two items with sizes.
+	a := []byte{0x06, 0x02, 0x04, 0x06, 0x00}
+	b := []byte{0x01, 0x02, 0x02, 0x03, 0x04, 0x04, 0x06, 0x00}
+
+	got, err := avro.Compare(schema, a, b)
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, got)
+}
+
+func TestCompare_Errors(t *testing.T) {
+	defer ConfigTeardown()
+
+	tests := []struct {
+		name   string
+		schema string
+		a      []byte
+		b      []byte
+	}{
+		{
+			name:   "Map",
+			schema: `{"type": "map", "values": "int"}`,
+			a:      []byte{0x00},
+			b:      []byte{0x00},
+		},
+		{
+			name:   "Short Data",
+			schema: `"string"`,
+			a:      []byte{0x04, 'a'},
+			b:      []byte{0x02, 'a'},
+		},
+		{
+			name:   "Invalid Union Index",
+			schema: `["null", "int"]`,
+			a:      []byte{0x04},
+			b:      []byte{0x04},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema := avro.MustParse(test.schema)
+
+			_, err := avro.Compare(schema, test.a, test.b)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	defer ConfigTeardown()
+
+	type record struct {
+		Name string `avro:"name"`
+		Age  int    `avro:"age"`
+	}
+	schema := avro.MustParse(`{"type": "record", "name": "test", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int", "order": "descending"}
+	]}`)
+	recs := []record{{"b", 1}, {"a", 1}, {"a", 2}}
+
+	var err error
+	sort.SliceStable(recs, func(i, j int) bool {
+		var res int
+		res, err = avro.CompareValues(schema, recs[i], recs[j])
+		return res < 0
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []record{{"a", 2}, {"a", 1}, {"b", 1}}, recs)
+}
+
+func TestCompareValues_EncodeError(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(`"string"`)
+
+	_, err := avro.CompareValues(schema, "a", 1)
+
+	assert.Error(t, err)
+}
@@ -25,6 +25,10 @@ type Config struct {
 	// UnionResolutionError determines if an error will be returned
 	// when a type cannot be resolved while decoding a union.
 	UnionResolutionError bool
+
+	// SchemaCache is the cache used when parsing schemas with the API.
+	// This defaults to DefaultSchemaCache.
+	SchemaCache *SchemaCache
 }
 
 // Freeze makes the configuration immutable.
@@ -83,6 +87,8 @@ type API interface {
 	EncoderOf(schema Schema, tpy reflect2.Type) ValEncoder
 
 	// Register registers names to their types for resolution. All primitive types are pre-registered.
+	//
+	// Registered types are only used by this API, isolating union type resolution from other APIs.
 	Register(name string, obj interface{})
 }
 
@@ -173,6 +179,10 @@ func (c *frozenConfig) Register(name string, obj interface{}) {
 	c.resolver.Register(name, obj)
 }
 
+func (c *frozenConfig) parse(schema string) (Schema, error) {
+	return ParseWithCache(schema, "", c.getSchemaCache())
+}
+
 type cacheKey struct {
 	fingerprint [32]byte
 	rtype       uintptr
@@ -221,3 +231,10 @@ func (c *frozenConfig) getBlockLength() int {
 	}
 	return blockSize
 }
+
+func (c *frozenConfig) getSchemaCache() *SchemaCache {
+	if c.config.SchemaCache == nil {
+		return DefaultSchemaCache
+	}
+	return c.config.SchemaCache
+}
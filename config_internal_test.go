@@ -18,6 +18,26 @@ func TestConfig_Freeze(t *testing.T) {
 	assert.Equal(t, 2, cfg.getBlockLength())
 }
 
+func TestParseWithConfig(t *testing.T) {
+	cache := &SchemaCache{}
+	api := Config{SchemaCache: cache}.Freeze()
+
+	schema, err := ParseWithConfig(`{"type": "fixed", "name": "org.hamba.avro.config_test", "size": 4}`, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, schema, cache.Get("org.hamba.avro.config_test"))
+	assert.Nil(t, DefaultSchemaCache.Get("org.hamba.avro.config_test"))
+}
+
+func TestParseWithConfig_UsesDefaultSchemaCache(t *testing.T) {
+	api := Config{}.Freeze()
+
+	schema, err := ParseWithConfig(`{"type": "fixed", "name": "org.hamba.avro.config_default_test", "size": 4}`, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, schema, DefaultSchemaCache.Get("org.hamba.avro.config_default_test"))
+}
+
 func TestConfig_ReusesDecoders(t *testing.T) {
 	api := Config{
 		TagKey:      "test",
@@ -13,7 +13,7 @@ type Decoder struct {
 
 // NewDecoder returns a new decoder that reads from reader r using schema s.
 func NewDecoder(s string, r io.Reader) (*Decoder, error) {
-	sch, err := Parse(s)
+	sch, err := ParseWithConfig(s, DefaultConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,370 @@
+package avro
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ChangeType is the type of a change between two versions of a schema.
+type ChangeType string
+
+// Change type constants.
+const (
+	FieldAdded     ChangeType = "field-added"
+	FieldRemoved   ChangeType = "field-removed"
+	FieldRenamed   ChangeType = "field-renamed"
+	TypeChanged    ChangeType = "type-changed"
+	DefaultChanged ChangeType = "default-changed"
+	SymbolAdded    ChangeType = "symbol-added"
+	SymbolRemoved  ChangeType = "symbol-removed"
+	SizeChanged    ChangeType = "size-changed"
+	DocChanged     ChangeType = "doc-changed"
+)
+
+// Change is a change between two versions of a schema.
+type Change struct {
+	// Type is the type of the change.
+	Type ChangeType `json:"type"`
+
+	// Path is the path of the changed schema or field. See Diff for its format.
+	Path string `json:"path"`
+
+	// Old and New hold the changed values, such as type names, defaults, symbols,
+	// sizes, docs or field names, where they apply to the type of change.
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+
+	// Backward is set if data written with the old schema can be read with the new schema.
+	Backward bool `json:"backward"`
+
+	// Forward is set if data written with the new schema can be read with the old schema.
+	Forward bool `json:"forward"`
+}
+
+// String returns a human-readable description of the change.
+func (c Change) String() string {
+	var desc string
+	switch c.Type {
+	case FieldAdded:
+		desc = "field added"
+	case FieldRemoved:
+		desc = "field removed"
+	case FieldRenamed:
+		desc = fmt.Sprintf("field renamed from %s", c.Old)
+	case TypeChanged:
+		desc = fmt.Sprintf("type changed from %s to %s", c.Old, c.New)
+	case DefaultChanged:
+		desc = fmt.Sprintf("default changed from %s to %s", diffValue(c.Old), diffValue(c.New))
+	case SymbolAdded:
+		desc = fmt.Sprintf("symbol %s added", c.New)
+	case SymbolRemoved:
+		desc = fmt.Sprintf("symbol %s removed", c.Old)
+	case SizeChanged:
+		desc = fmt.Sprintf("size changed from %v to %v", c.Old, c.New)
+	case DocChanged:
+		desc = fmt.Sprintf("doc changed from %q to %q", c.Old, c.New)
+	default:
+		desc = string(c.Type)
+	}
+
+	var compat string
+	switch {
+	case c.Backward && c.Forward:
+		compat = "fully compatible"
+	case c.Backward:
+		compat = "backward compatible"
+	case c.Forward:
+		compat = "forward compatible"
+	default:
+		compat = "incompatible"
+	}
+
+	return c.Path + ": " + desc + " (" + compat + ")"
+}
+
+func diffValue(v interface{}) string {
+	if v == nil {
+		return "none"
+	}
+
+	b, err := jsoniter.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// WriteDiff writes a human-readable description of the changes to w, one change per line.
+func WriteDiff(w io.Writer, changes []Change) error {
+	for _, c := range changes {
+		if _, err := io.WriteString(w, c.String()+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff returns the changes between the old and new version of a schema, each annotated
+// with its compatibility according to SchemaCompatibility.
+//
+// The path of a change starts with the full name or type of the root schema, followed by
+// ".name" for record fields, "[]" for array items, "{}" for map values and "<name>" for
+// union types. Fields are matched by name, or by the aliases of the new field, in which
+// case they are reported as renamed.
+func Diff(oldSchema, newSchema Schema) []Change {
+	d := differ{compat: NewSchemaCompatibility(), seen: map[[2]Schema]bool{}}
+	d.diff(diffTypeName(newSchema), oldSchema, newSchema)
+	return d.changes
+}
+
+type differ struct {
+	compat  *SchemaCompatibility
+	seen    map[[2]Schema]bool
+	changes []Change
+}
+
+func (d *differ) add(c Change, backward, forward bool) {
+	c.Backward = backward
+	c.Forward = forward
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) compatible(reader, writer Schema) bool {
+	return d.compat.Compatible(reader, writer) == nil
+}
+
+func (d *differ) diff(path string, o, n Schema) {
+	o, n = derefSchema(o), derefSchema(n)
+
+	key := [2]Schema{o, n}
+	if d.seen[key] {
+		return
+	}
+	d.seen[key] = true
+
+	if shallowTypeName(o) != shallowTypeName(n) {
+		d.add(
+			Change{Type: TypeChanged, Path: path, Old: diffTypeName(o), New: diffTypeName(n)},
+			d.compatible(n, o), d.compatible(o, n),
+		)
+		return
+	}
+
+	switch os := o.(type) {
+	case *RecordSchema:
+		d.diffRecord(path, os, n.(*RecordSchema))
+
+	case *EnumSchema:
+		d.diffEnum(path, os, n.(*EnumSchema))
+
+	case *FixedSchema:
+		ns := n.(*FixedSchema)
+		d.diffDoc(path, os.Doc(), ns.Doc())
+		if os.Size() != ns.Size() {
+			d.add(
+				Change{Type: SizeChanged, Path: path, Old: os.Size(), New: ns.Size()},
+				d.compatible(ns, os), d.compatible(os, ns),
+			)
+		}
+
+	case *ArraySchema:
+		d.diff(path+"[]", os.Items(), n.(*ArraySchema).Items())
+
+	case *MapSchema:
+		d.diff(path+"{}", os.Values(), n.(*MapSchema).Values())
+
+	case *UnionSchema:
+		d.diffUnion(path, os, n.(*UnionSchema))
+	}
+}
+
+func (d *differ) diffDoc(path, o, n string) {
+	if o != n {
+		d.add(Change{Type: DocChanged, Path: path, Old: o, New: n}, true, true)
+	}
+}
+
+func (d *differ) diffRecord(path string, o, n *RecordSchema) {
+	d.diffDoc(path, o.Doc(), n.Doc())
+
+	matched := map[*Field]bool{}
+	for _, nf := range n.Fields() {
+		fieldPath := path + "." + nf.Name()
+
+		of := diffField(o, nf.Name())
+		renamed := false
+		if of == nil {
+			for _, alias := range nf.Aliases() {
+				if f := diffField(o, alias); f != nil && !matched[f] && diffField(n, alias) == nil {
+					of = f
+					renamed = true
+					break
+				}
+			}
+		}
+
+		if of == nil {
+			b, f := d.fieldCompatible(o, nil, nf)
+			d.add(Change{Type: FieldAdded, Path: fieldPath}, b, f)
+			continue
+		}
+		matched[of] = true
+
+		if renamed {
+			b, f := d.fieldCompatible(o, of, nf)
+			d.add(Change{Type: FieldRenamed, Path: fieldPath, Old: of.Name(), New: nf.Name()}, b, f)
+		}
+
+		if of.HasDefault() != nf.HasDefault() || !reflect.DeepEqual(of.Default(), nf.Default()) {
+			d.add(
+				Change{Type: DefaultChanged, Path: fieldPath, Old: diffDefault(of), New: diffDefault(nf)},
+				true, true,
+			)
+		}
+		d.diffDoc(fieldPath, of.Doc(), nf.Doc())
+		d.diff(fieldPath, of.Type(), nf.Type())
+	}
+
+	for _, of := range o.Fields() {
+		if matched[of] {
+			continue
+		}
+
+		b, f := d.fieldCompatible(o, of, nil)
+		d.add(Change{Type: FieldRemoved, Path: path + "." + of.Name()}, b, f)
+	}
+}
+
+// fieldCompatible determines the compatibility of a field change using records
+// holding only the old and new field.
+func (d *differ) fieldCompatible(rec *RecordSchema, of, nf *Field) (backward, forward bool) {
+	oldRec := &RecordSchema{name: rec.name, properties: properties{reserved: schemaReserved}}
+	if of != nil {
+		oldRec.fields = []*Field{of}
+	}
+	newRec := &RecordSchema{name: rec.name, properties: properties{reserved: schemaReserved}}
+	if nf != nil {
+		newRec.fields = []*Field{nf}
+	}
+
+	return d.compatible(newRec, oldRec), d.compatible(oldRec, newRec)
+}
+
+func (d *differ) diffEnum(path string, o, n *EnumSchema) {
+	d.diffDoc(path, o.Doc(), n.Doc())
+
+	for _, sym := range n.Symbols() {
+		if !d.compat.contains(o.Symbols(), sym) {
+			withSym := &EnumSchema{name: o.name, symbols: append(append([]string{}, o.Symbols()...), sym)}
+			d.add(
+				Change{Type: SymbolAdded, Path: path, New: sym},
+				d.compatible(withSym, o), d.compatible(o, withSym),
+			)
+		}
+	}
+	for _, sym := range o.Symbols() {
+		if !d.compat.contains(n.Symbols(), sym) {
+			withSym := &EnumSchema{name: n.name, symbols: append(append([]string{}, n.Symbols()...), sym)}
+			d.add(
+				Change{Type: SymbolRemoved, Path: path, Old: sym},
+				d.compatible(n, withSym), d.compatible(withSym, n),
+			)
+		}
+	}
+}
+
+func (d *differ) diffUnion(path string, o, n *UnionSchema) {
+	names := func(u *UnionSchema) []string {
+		s := make([]string, len(u.Types()))
+		for i, typ := range u.Types() {
+			s[i] = schemaTypeName(typ)
+		}
+		return s
+	}
+	oldNames, newNames := names(o), names(n)
+
+	if !reflect.DeepEqual(oldNames, newNames) {
+		d.add(
+			Change{Type: TypeChanged, Path: path, Old: diffTypeName(o), New: diffTypeName(n)},
+			d.compatible(n, o), d.compatible(o, n),
+		)
+	}
+
+	for i, typ := range n.Types() {
+		if ot, _ := o.Types().Get(newNames[i]); ot != nil {
+			d.diff(path+"<"+newNames[i]+">", ot, typ)
+		}
+	}
+}
+
+func diffField(rec *RecordSchema, name string) *Field {
+	for _, f := range rec.Fields() {
+		if f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func diffDefault(f *Field) interface{} {
+	if !f.HasDefault() {
+		return nil
+	}
+	return f.Default()
+}
+
+func derefSchema(schema Schema) Schema {
+	if ref, ok := schema.(*RefSchema); ok {
+		return ref.Schema()
+	}
+	return schema
+}
+
+// shallowTypeName returns the name of a schema type, without the types it contains.
+func shallowTypeName(schema Schema) string {
+	name := string(schema.Type())
+	if named, ok := schema.(NamedSchema); ok {
+		name += " " + named.FullName()
+	}
+	if l, ok := schema.(LogicalTypeSchema); ok && l.Logical() != nil {
+		name += " " + diffLogicalName(l.Logical())
+	}
+	return name
+}
+
+// diffTypeName returns a readable name of a schema type.
+func diffTypeName(schema Schema) string {
+	switch s := derefSchema(schema).(type) {
+	case NamedSchema:
+		return s.FullName()
+	case *ArraySchema:
+		return "array<" + diffTypeName(s.Items()) + ">"
+	case *MapSchema:
+		return "map<" + diffTypeName(s.Values()) + ">"
+	case *UnionSchema:
+		names := make([]string, len(s.Types()))
+		for i, typ := range s.Types() {
+			names[i] = diffTypeName(typ)
+		}
+		return "union<" + strings.Join(names, ",") + ">"
+	case *PrimitiveSchema:
+		if s.Logical() != nil {
+			return string(s.Type()) + "." + diffLogicalName(s.Logical())
+		}
+		return string(s.Type())
+	default:
+		return string(schema.Type())
+	}
+}
+
+func diffLogicalName(l LogicalSchema) string {
+	if dec, ok := l.(*DecimalLogicalSchema); ok {
+		return string(Decimal) + "(" + strconv.Itoa(dec.Precision()) + "," + strconv.Itoa(dec.Scale()) + ")"
+	}
+	return string(l.Type())
+}
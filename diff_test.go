@@ -0,0 +1,127 @@
+package avro_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+func TestDiff(t *testing.T) {
+	old := mustParseIsolated(t, `{
+		"type": "record",
+		"name": "org.hamba.avro.Order",
+		"doc": "An order",
+		"fields": [
+			{"name": "id", "type": "int"},
+			{"name": "note", "type": "string"},
+			{"name": "customer", "type": "string", "default": "none"},
+			{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "OLD"]}},
+			{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+			{"name": "tags", "type": {"type": "array", "items": "int"}},
+			{"name": "removed", "type": "string", "default": ""},
+			{"name": "price", "type": "string"}
+		]
+	}`)
+	updated := mustParseIsolated(t, `{
+		"type": "record",
+		"name": "org.hamba.avro.Order",
+		"doc": "An updated order",
+		"fields": [
+			{"name": "id", "type": "long"},
+			{"name": "comment", "type": "string", "aliases": ["note"]},
+			{"name": "customer", "type": "string", "default": "unknown"},
+			{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "DONE"]}},
+			{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 8}},
+			{"name": "tags", "type": {"type": "array", "items": "string"}},
+			{"name": "added", "type": "int"},
+			{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}}
+		]
+	}`)
+
+	got := avro.Diff(old, updated)
+
+	want := []avro.Change{
+		{Type: avro.DocChanged, Path: "org.hamba.avro.Order", Old: "An order", New: "An updated order", Backward: true, Forward: true},
+		{Type: avro.TypeChanged, Path: "org.hamba.avro.Order.id", Old: "int", New: "long", Backward: true, Forward: false},
+		{Type: avro.FieldRenamed, Path: "org.hamba.avro.Order.comment", Old: "note", New: "comment", Backward: true, Forward: false},
+		{Type: avro.DefaultChanged, Path: "org.hamba.avro.Order.customer", Old: "none", New: "unknown", Backward: true, Forward: true},
+		{Type: avro.SymbolAdded, Path: "org.hamba.avro.Order.status", New: "DONE", Backward: true, Forward: false},
+		{Type: avro.SymbolRemoved, Path: "org.hamba.avro.Order.status", Old: "OLD", Backward: false, Forward: true},
+		{Type: avro.SizeChanged, Path: "org.hamba.avro.Order.hash", Old: 4, New: 8, Backward: false, Forward: false},
+		{Type: avro.TypeChanged, Path: "org.hamba.avro.Order.tags[]", Old: "int", New: "string", Backward: false, Forward: false},
+		{Type: avro.FieldAdded, Path: "org.hamba.avro.Order.added", Backward: false, Forward: true},
+		{Type: avro.TypeChanged, Path: "org.hamba.avro.Order.price", Old: "string", New: "bytes.decimal(4,2)", Backward: true, Forward: true},
+		{Type: avro.FieldRemoved, Path: "org.hamba.avro.Order.removed", Backward: true, Forward: true},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestDiff_Unions(t *testing.T) {
+	old := mustParseIsolated(t, `{"type": "record", "name": "A", "fields": [
+		{"name": "a", "type": ["null", {"type": "record", "name": "B", "fields": [{"name": "b", "type": "int"}]}]},
+		{"name": "c", "type": ["null", "int"]}
+	]}`)
+	updated := mustParseIsolated(t, `{"type": "record", "name": "A", "fields": [
+		{"name": "a", "type": ["null", {"type": "record", "name": "B", "fields": [{"name": "b", "type": "int", "doc": "docs"}]}]},
+		{"name": "c", "type": ["null", "int", "string"]}
+	]}`)
+
+	got := avro.Diff(old, updated)
+
+	want := []avro.Change{
+		{Type: avro.DocChanged, Path: "A.a<B>.b", Old: "", New: "docs", Backward: true, Forward: true},
+		{Type: avro.TypeChanged, Path: "A.c", Old: "union<null,int>", New: "union<null,int,string>", Backward: true, Forward: false},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestDiff_Recursive(t *testing.T) {
+	schema := `{"type": "record", "name": "Node", "fields": [{"name": "next", "type": ["null", "Node"]}, {"name": "map", "type": {"type": "map", "values": "Node"}}]}`
+	old := mustParseIsolated(t, schema)
+	updated := mustParseIsolated(t, schema)
+
+	got := avro.Diff(old, updated)
+
+	assert.Empty(t, got)
+}
+
+func TestWriteDiff(t *testing.T) {
+	changes := []avro.Change{
+		{Type: avro.FieldAdded, Path: "A.a", Backward: true, Forward: true},
+		{Type: avro.FieldRemoved, Path: "A.b", Backward: true},
+		{Type: avro.FieldRenamed, Path: "A.c", Old: "d", New: "c", Forward: true},
+		{Type: avro.TypeChanged, Path: "A.e", Old: "int", New: "string"},
+		{Type: avro.DefaultChanged, Path: "A.f", Old: nil, New: "test", Backward: true, Forward: true},
+		{Type: avro.SymbolAdded, Path: "B", New: "X", Backward: true},
+		{Type: avro.SymbolRemoved, Path: "B", Old: "Y", Forward: true},
+		{Type: avro.SizeChanged, Path: "C", Old: 4, New: 8},
+		{Type: avro.DocChanged, Path: "C", Old: "", New: "docs", Backward: true, Forward: true},
+	}
+	buf := &bytes.Buffer{}
+
+	err := avro.WriteDiff(buf, changes)
+
+	require.NoError(t, err)
+	want := `A.a: field added (fully compatible)
+A.b: field removed (backward compatible)
+A.c: field renamed from d (forward compatible)
+A.e: type changed from int to string (incompatible)
+A.f: default changed from none to "test" (fully compatible)
+B: symbol X added (backward compatible)
+B: symbol Y removed (forward compatible)
+C: size changed from 4 to 8 (incompatible)
+C: doc changed from "" to "docs" (fully compatible)
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func mustParseIsolated(t *testing.T, schema string) avro.Schema {
+	t.Helper()
+
+	s, err := avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+	require.NoError(t, err)
+	return s
+}
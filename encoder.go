@@ -12,7 +12,7 @@ type Encoder struct {
 
 // NewEncoder returns a new encoder that writes to w using schema s.
 func NewEncoder(s string, w io.Writer) (*Encoder, error) {
-	sch, err := Parse(s)
+	sch, err := ParseWithConfig(s, DefaultConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,118 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is an error decoding a value.
+type DecodeError struct {
+	// Path is the path of the value being decoded when the error happened, such as
+	// ".orders[3].price", or "." for the decoded value itself. The branch of a union
+	// is written as a type assertion, such as ".note.(string)".
+	Path string
+
+	// Offset is the offset in the input at which the error was detected.
+	Offset int64
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error returns the error message.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("avro: decoding %s at offset %d: %s", errorPath(e.Path), e.Offset, errorCause(e.Err))
+}
+
+// Unwrap returns the cause of the error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// EncodeError is an error encoding a value.
+type EncodeError struct {
+	// Path is the path of the value being encoded when the error happened, written
+	// as the Path of a DecodeError.
+	Path string
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error returns the error message.
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("avro: encoding %s: %s", errorPath(e.Path), errorCause(e.Err))
+}
+
+// Unwrap returns the cause of the error.
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+func errorPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
+
+func errorCause(err error) string {
+	return strings.TrimPrefix(err.Error(), "avro: ")
+}
+
+func fieldPath(name string) string {
+	return "." + name
+}
+
+func indexPath(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+func keyPath(key string) string {
+	return "[" + strconv.Quote(key) + "]"
+}
+
+func branchPath(schema Schema) string {
+	return ".(" + schemaTypeName(schema) + ")"
+}
+
+// decodeFailed determines if decoding failed, rather than reaching the end of the input.
+func decodeFailed(r *Reader) bool {
+	return r.Error != nil && !errors.Is(r.Error, io.EOF)
+}
+
+// wrapDecodeError prefixes the path of the decoding error of r with the path of the value
+// containing the failed value.
+func wrapDecodeError(r *Reader, path string) {
+	if !decodeFailed(r) {
+		return
+	}
+
+	if derr, ok := r.Error.(*DecodeError); ok {
+		derr.Path = path + derr.Path
+		return
+	}
+	r.Error = &DecodeError{Path: path, Offset: r.InputOffset(), Err: r.Error}
+}
+
+// encodeFailed determines if encoding failed.
+func encodeFailed(w *Writer) bool {
+	return w.Error != nil && !errors.Is(w.Error, io.EOF)
+}
+
+// wrapEncodeError prefixes the path of the encoding error of w with the path of the value
+// containing the failed value.
+func wrapEncodeError(w *Writer, path string) {
+	if !encodeFailed(w) {
+		return
+	}
+
+	if eerr, ok := w.Error.(*EncodeError); ok {
+		eerr.Path = path + eerr.Path
+		return
+	}
+	w.Error = &EncodeError{Path: path, Err: w.Error}
+}
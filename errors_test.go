@@ -0,0 +1,186 @@
+package avro_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+type ErrorItem struct {
+	ID   int32  `avro:"id"`
+	Name string `avro:"name"`
+}
+
+type ErrorRecord struct {
+	A      int64                  `avro:"a"`
+	Orders []ErrorItem            `avro:"orders"`
+	Kinds  map[string]string      `avro:"kinds"`
+	Note   *string                `avro:"note"`
+	Extra  map[string]interface{} `avro:"extra"`
+}
+
+const errorSchema = `{
+	"type": "record",
+	"name": "test",
+	"fields": [
+		{"name": "a", "type": "long"},
+		{"name": "orders", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "item",
+			"fields": [
+				{"name": "id", "type": "int"},
+				{"name": "name", "type": "string"}
+			]
+		}}},
+		{"name": "kinds", "type": {"type": "map", "values": {"type": "enum", "name": "kind", "symbols": ["A", "B"]}}},
+		{"name": "note", "type": ["null", "string"]},
+		{"name": "extra", "type": ["null", "string"]}
+	]
+}`
+
+func TestDecodeError(t *testing.T) {
+	defer ConfigTeardown()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		path    string
+		offset  int64
+		wantErr string
+	}{
+		{
+			name:    "Array Record Field",
+			data:    []byte{0x36, 0x04, 0x02, 0x00, 0x04, 0x01},
+			path:    ".orders[1].name",
+			offset:  6,
+			wantErr: "avro: decoding .orders[1].name at offset 6: ReadString: invalid string length",
+		},
+		{
+			name:    "Map",
+			data:    []byte{0x36, 0x00, 0x04, 0x02, 'a', 0x00, 0x02, 'b', 0x0a},
+			path:    `.kinds["b"]`,
+			offset:  9,
+			wantErr: `avro: decoding .kinds["b"] at offset 9: decode unknown enum symbol: unknown enum symbol`,
+		},
+		{
+			name:    "Union",
+			data:    []byte{0x36, 0x00, 0x00, 0x02, 0x01},
+			path:    ".note.(string)",
+			offset:  5,
+			wantErr: "avro: decoding .note.(string) at offset 5: ReadString: invalid string length",
+		},
+		{
+			name:    "Union Index",
+			data:    []byte{0x36, 0x00, 0x00, 0x00, 0x04},
+			path:    ".extra",
+			offset:  5,
+			wantErr: "avro: decoding .extra at offset 5: decode union type: unknown union type",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema := avro.MustParse(errorSchema)
+
+			var got ErrorRecord
+			err := avro.Unmarshal(schema, test.data, &got)
+
+			require.Error(t, err)
+			assert.EqualError(t, err, test.wantErr)
+			var derr *avro.DecodeError
+			require.True(t, errors.As(err, &derr))
+			assert.Equal(t, test.path, derr.Path)
+			assert.Equal(t, test.offset, derr.Offset)
+		})
+	}
+}
+
+func TestDecodeError_Generic(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(errorSchema)
+	data := []byte{0x36, 0x04, 0x02, 0x00, 0x04, 0x01}
+
+	var got interface{}
+	err := avro.Unmarshal(schema, data, &got)
+
+	var derr *avro.DecodeError
+	require.True(t, errors.As(err, &derr))
+	assert.Equal(t, ".orders[1].name", derr.Path)
+	assert.Equal(t, int64(6), derr.Offset)
+}
+
+func TestDecodeError_Cause(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(`{"type": "record", "name": "test", "fields": [{"name": "a", "type": "string"}]}`)
+
+	var got struct {
+		A int `avro:"a"`
+	}
+	err := avro.Unmarshal(schema, []byte{0x02, 'a'}, &got)
+
+	assert.EqualError(t, err, "avro: decoding .a at offset 0: int is unsupported for Avro string")
+	var derr *avro.DecodeError
+	require.True(t, errors.As(err, &derr))
+	assert.EqualError(t, derr.Err, "avro: int is unsupported for Avro string")
+}
+
+func TestEncodeError(t *testing.T) {
+	defer ConfigTeardown()
+
+	tests := []struct {
+		name    string
+		value   ErrorRecord
+		path    string
+		wantErr string
+	}{
+		{
+			name: "Map",
+			value: ErrorRecord{
+				Kinds: map[string]string{"a": "C"},
+			},
+			path:    `.kinds["a"]`,
+			wantErr: `avro: encoding .kinds["a"]: unknown enum symbol: C`,
+		},
+		{
+			name: "Union Map",
+			value: ErrorRecord{
+				Extra: map[string]interface{}{"string": 1},
+			},
+			path:    ".extra.(string)",
+			wantErr: "avro: encoding .extra.(string): int is unsupported for Avro string",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema := avro.MustParse(errorSchema)
+
+			_, err := avro.Marshal(schema, test.value)
+
+			require.Error(t, err)
+			assert.EqualError(t, err, test.wantErr)
+			var eerr *avro.EncodeError
+			require.True(t, errors.As(err, &eerr))
+			assert.Equal(t, test.path, eerr.Path)
+		})
+	}
+}
+
+func TestEncodeError_Array(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(`{"type": "array", "items": {"type": "enum", "name": "kind", "symbols": ["A", "B"]}}`)
+
+	_, err := avro.Marshal(schema, []string{"A", "B", "C"})
+
+	var eerr *avro.EncodeError
+	require.True(t, errors.As(err, &eerr))
+	assert.Equal(t, "[2]", eerr.Path)
+}
@@ -0,0 +1,458 @@
+// Package gen generates Go code for Avro protocols.
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// Config configures the code generation.
+type Config struct {
+	// PackageName is the package name of the generated code.
+	PackageName string
+}
+
+// Protocol writes the Go code for the protocol to w.
+//
+// The code contains a struct for each record and error type, a request struct for each message,
+// an interface with a method per message, a client implementing the interface with the
+// ipc package, and an adapter registering an implementation of the interface on an ipc.Server.
+func Protocol(w io.Writer, proto *avro.Protocol, cfg Config) error {
+	if cfg.PackageName == "" {
+		return errors.New("gen: package name is required")
+	}
+
+	g := &generator{
+		imports: map[string]bool{},
+		named:   map[string]bool{},
+	}
+
+	b, err := g.protocol(proto, cfg)
+	if err != nil {
+		return err
+	}
+
+	src, err := format.Source(b)
+	if err != nil {
+		return fmt.Errorf("gen: generated invalid code: %w", err)
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+type message struct {
+	name   string
+	method string
+	msg    *avro.Message
+}
+
+type generator struct {
+	imports map[string]bool
+	named   map[string]bool
+	types   bytes.Buffer
+}
+
+func (g *generator) protocol(proto *avro.Protocol, cfg Config) ([]byte, error) {
+	for _, typ := range proto.Types() {
+		if _, err := g.typeOf(typ); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(proto.Messages()))
+	for name := range proto.Messages() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]message, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, message{name: name, method: goName(name), msg: proto.Message(name)})
+	}
+
+	body := &bytes.Buffer{}
+	for _, m := range msgs {
+		if err := g.request(body, m); err != nil {
+			return nil, err
+		}
+	}
+
+	iface := goName(proto.Name())
+	if err := g.iface(body, iface, msgs); err != nil {
+		return nil, err
+	}
+	g.client(body, iface, msgs)
+	g.server(body, iface, msgs)
+	g.errors(body, iface, msgs)
+
+	g.imports["context"] = true
+	g.imports["errors"] = true
+	g.imports["github.com/xl4hub/hamba-avro"] = true
+	g.imports["github.com/xl4hub/hamba-avro/ipc"] = true
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by avrogen. DO NOT EDIT.\n\npackage %s\n\n", cfg.PackageName)
+	out.WriteString("import (\n")
+	var std, other []string
+	for _, imp := range sortedKeys(g.imports) {
+		if strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") {
+			other = append(other, imp)
+			continue
+		}
+		std = append(std, imp)
+	}
+	for _, imp := range std {
+		fmt.Fprintf(out, "\t%q\n", imp)
+	}
+	out.WriteString("\n")
+	for _, imp := range other {
+		fmt.Fprintf(out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+
+	fmt.Fprintf(out, "// %sProtocol is the canonical form of the %s protocol.\n", iface, proto.FullName())
+	fmt.Fprintf(out, "const %sProtocol = %s\n\n", iface, strconv.Quote(proto.String()))
+
+	out.Write(g.types.Bytes())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// request writes the request struct of a message.
+func (g *generator) request(w io.Writer, m message) error {
+	fmt.Fprintf(w, "// %sRequest is the request of the %s message.\n", m.method, m.name)
+	fmt.Fprintf(w, "type %sRequest struct {\n", m.method)
+	if err := g.fields(w, m.msg.Request().Fields()); err != nil {
+		return err
+	}
+	fmt.Fprint(w, "}\n\n")
+	return nil
+}
+
+// signature returns the method signature of a message.
+func (g *generator) signature(m message) (string, error) {
+	sig := fmt.Sprintf("%s(ctx context.Context, req *%sRequest) ", m.method, m.method)
+	if m.msg.Response() == nil {
+		return sig + "error", nil
+	}
+
+	typ, err := g.typeOf(m.msg.Response())
+	if err != nil {
+		return "", err
+	}
+	return sig + "(" + typ + ", error)", nil
+}
+
+func (g *generator) iface(w io.Writer, iface string, msgs []message) error {
+	fmt.Fprintf(w, "// %s is the %s protocol.\n", iface, iface)
+	fmt.Fprintf(w, "type %s interface {\n", iface)
+	for _, m := range msgs {
+		sig, err := g.signature(m)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "\t// %s calls the %s message.\n", m.method, m.name)
+		fmt.Fprintf(w, "\t%s\n", sig)
+	}
+	fmt.Fprint(w, "}\n\n")
+	return nil
+}
+
+func (g *generator) client(w io.Writer, iface string, msgs []message) {
+	fmt.Fprintf(w, "// %sClient calls the %s protocol on a server.\n", iface, iface)
+	fmt.Fprintf(w, "type %sClient struct {\n\tclient *ipc.Client\n}\n\n", iface)
+	fmt.Fprintf(w, "var _ %s = (*%sClient)(nil)\n\n", iface, iface)
+
+	fmt.Fprintf(w, "// New%sClient returns a client that sends calls with transport.\n", iface)
+	fmt.Fprintf(w, "func New%sClient(transport ipc.Transport) *%sClient {\n", iface, iface)
+	fmt.Fprintf(w, "\treturn &%sClient{client: ipc.NewClient(avro.MustParseProtocol(%sProtocol), transport)}\n}\n\n", iface, iface)
+
+	for _, m := range msgs {
+		// The signature has been generated by iface.
+		sig, _ := g.signature(m)
+
+		fmt.Fprintf(w, "// %s calls the %s message.\n", m.method, m.name)
+		fmt.Fprintf(w, "func (c *%sClient) %s {\n", iface, sig)
+
+		if m.msg.OneWay() {
+			fmt.Fprintf(w, "\treturn c.client.Call(ctx, %q, req, nil)\n}\n\n", m.name)
+			continue
+		}
+
+		resp := "nil"
+		if m.msg.Response() != nil {
+			typ, _ := g.typeOf(m.msg.Response())
+			fmt.Fprintf(w, "\tvar resp %s\n", typ)
+			resp = "&resp"
+		}
+
+		fmt.Fprintf(w, "\terr := c.client.Do(ctx, &ipc.Call{\n")
+		fmt.Fprintf(w, "\t\tMessage: %q,\n\t\tRequest: req,\n\t\tResponse: %s,\n", m.name, resp)
+		if errs := errorTypes(m.msg); len(errs) > 0 {
+			fmt.Fprint(w, "\t\tErrors: map[string]interface{}{\n")
+			for _, e := range errs {
+				fmt.Fprintf(w, "\t\t\t%q: &%s{},\n", e.FullName(), goName(e.Name()))
+			}
+			fmt.Fprint(w, "\t\t},\n")
+		}
+		fmt.Fprint(w, "\t})\n")
+
+		if m.msg.Response() == nil {
+			fmt.Fprintf(w, "\treturn %sError(err)\n}\n\n", lowerFirst(iface))
+			continue
+		}
+		fmt.Fprintf(w, "\treturn resp, %sError(err)\n}\n\n", lowerFirst(iface))
+	}
+}
+
+func (g *generator) server(w io.Writer, iface string, msgs []message) {
+	fmt.Fprintf(w, "// New%sServer returns a server for the %s protocol that calls impl.\n", iface, iface)
+	fmt.Fprintf(w, "func New%sServer(impl %s) (*ipc.Server, error) {\n", iface, iface)
+	fmt.Fprintf(w, "\tsrv := ipc.NewServer(avro.MustParseProtocol(%sProtocol))\n", iface)
+	fmt.Fprintf(w, "\tif err := Register%s(srv, impl); err != nil {\n\t\treturn nil, err\n\t}\n\treturn srv, nil\n}\n\n", iface)
+
+	fmt.Fprintf(w, "// Register%s registers the messages of impl on srv.\n", iface)
+	fmt.Fprintf(w, "func Register%s(srv *ipc.Server, impl %s) error {\n", iface, iface)
+	fmt.Fprint(w, "\thandlers := map[string]ipc.Handler{\n")
+	for _, m := range msgs {
+		fmt.Fprintf(w, "\t\t%q: func(ctx context.Context, r *ipc.Request) (interface{}, error) {\n", m.name)
+		fmt.Fprintf(w, "\t\t\tvar req %sRequest\n", m.method)
+		fmt.Fprint(w, "\t\t\tif err := r.Decode(&req); err != nil {\n\t\t\t\treturn nil, err\n\t\t\t}\n")
+		if m.msg.Response() == nil {
+			fmt.Fprintf(w, "\t\t\treturn nil, %sServerError(impl.%s(ctx, &req))\n", lowerFirst(iface), m.method)
+		} else {
+			fmt.Fprintf(w, "\t\t\tresp, err := impl.%s(ctx, &req)\n", m.method)
+			fmt.Fprintf(w, "\t\t\tif err != nil {\n\t\t\t\treturn nil, %sServerError(err)\n\t\t\t}\n\t\t\treturn resp, nil\n", lowerFirst(iface))
+		}
+		fmt.Fprint(w, "\t\t},\n")
+	}
+	fmt.Fprint(w, "\t}\n\n")
+	fmt.Fprint(w, "\tfor name, h := range handlers {\n\t\tif err := srv.Handle(name, h); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n\treturn nil\n}\n\n")
+}
+
+// errors writes the functions converting between declared errors and ipc errors.
+func (g *generator) errors(w io.Writer, iface string, msgs []message) {
+	fmt.Fprintf(w, "// %sError returns the declared error of err, if it is one.\n", lowerFirst(iface))
+	fmt.Fprintf(w, "func %sError(err error) error {\n", lowerFirst(iface))
+	fmt.Fprint(w, "\tvar e *ipc.Error\n\tif errors.As(err, &e) {\n")
+	fmt.Fprint(w, "\t\tif v, ok := e.Value.(error); ok {\n\t\t\treturn v\n\t\t}\n\t}\n\treturn err\n}\n\n")
+
+	seen := map[string]bool{}
+	var errs []avro.NamedSchema
+	for _, m := range msgs {
+		for _, e := range errorTypes(m.msg) {
+			if seen[e.FullName()] {
+				continue
+			}
+			seen[e.FullName()] = true
+			errs = append(errs, e)
+		}
+	}
+
+	fmt.Fprintf(w, "// %sServerError returns err as an ipc error if it is a declared error.\n", lowerFirst(iface))
+	fmt.Fprintf(w, "func %sServerError(err error) error {\n", lowerFirst(iface))
+	for i, e := range errs {
+		fmt.Fprintf(w, "\tvar e%d *%s\n", i, goName(e.Name()))
+		fmt.Fprintf(w, "\tif errors.As(err, &e%d) {\n\t\treturn &ipc.Error{Name: %q, Value: e%d}\n\t}\n", i, e.FullName(), i)
+	}
+	fmt.Fprint(w, "\treturn err\n}\n")
+}
+
+// typeOf returns the Go type of the schema, declaring named types as needed.
+func (g *generator) typeOf(schema avro.Schema) (string, error) {
+	switch s := schema.(type) {
+	case *avro.RefSchema:
+		return g.typeOf(s.Schema())
+
+	case *avro.PrimitiveSchema:
+		return g.primitive(s)
+
+	case *avro.RecordSchema:
+		name := goName(s.Name())
+		if err := g.record(name, s); err != nil {
+			return "", err
+		}
+		return name, nil
+
+	case *avro.EnumSchema:
+		return "string", nil
+
+	case *avro.FixedSchema:
+		return fmt.Sprintf("[%d]byte", s.Size()), nil
+
+	case *avro.ArraySchema:
+		typ, err := g.typeOf(s.Items())
+		if err != nil {
+			return "", err
+		}
+		return "[]" + typ, nil
+
+	case *avro.MapSchema:
+		typ, err := g.typeOf(s.Values())
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + typ, nil
+
+	case *avro.UnionSchema:
+		if s.Nullable() && len(s.Types()) == 2 {
+			_, idx := s.Indices()
+			typ, err := g.typeOf(s.Types()[idx])
+			if err != nil {
+				return "", err
+			}
+			return "*" + typ, nil
+		}
+
+		// Other unions are represented by the name of their type and their value.
+		for _, typ := range s.Types() {
+			if _, err := g.typeOf(typ); err != nil {
+				return "", err
+			}
+		}
+		return "map[string]interface{}", nil
+	}
+
+	return "", fmt.Errorf("gen: unsupported schema type %s", schema.Type())
+}
+
+func (g *generator) primitive(s *avro.PrimitiveSchema) (string, error) {
+	if l := s.Logical(); l != nil {
+		switch l.Type() {
+		case avro.Date, avro.TimestampMillis, avro.TimestampMicros:
+			g.imports["time"] = true
+			return "time.Time", nil
+		case avro.TimeMillis, avro.TimeMicros:
+			g.imports["time"] = true
+			return "time.Duration", nil
+		case avro.Decimal:
+			g.imports["math/big"] = true
+			return "*big.Rat", nil
+		}
+	}
+
+	switch s.Type() {
+	case avro.Null:
+		return "interface{}", nil
+	case avro.Boolean:
+		return "bool", nil
+	case avro.Int:
+		return "int", nil
+	case avro.Long:
+		return "int64", nil
+	case avro.Float:
+		return "float32", nil
+	case avro.Double:
+		return "float64", nil
+	case avro.String:
+		return "string", nil
+	case avro.Bytes:
+		return "[]byte", nil
+	}
+
+	return "", fmt.Errorf("gen: unsupported schema type %s", s.Type())
+}
+
+// record declares the struct of a record schema, if it has not been declared yet.
+func (g *generator) record(name string, s *avro.RecordSchema) error {
+	if g.named[s.FullName()] {
+		return nil
+	}
+	g.named[s.FullName()] = true
+
+	// Field types are declared before the record.
+	fields := &bytes.Buffer{}
+	if err := g.fields(fields, s.Fields()); err != nil {
+		return err
+	}
+
+	kind := "record"
+	if s.IsError() {
+		kind = "error"
+	}
+	fmt.Fprintf(&g.types, "// %s is the %s %s.\n", name, s.FullName(), kind)
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", name, fields.String())
+
+	if s.IsError() {
+		g.imports["fmt"] = true
+		fmt.Fprint(&g.types, "// Error returns the error message.\n")
+		fmt.Fprintf(&g.types, "func (e *%s) Error() string {\n\treturn fmt.Sprintf(\"%s: %%+v\", *e)\n}\n\n", name, s.FullName())
+	}
+	return nil
+}
+
+func (g *generator) fields(w io.Writer, fields []*avro.Field) error {
+	for _, f := range fields {
+		typ, err := g.typeOf(f.Type())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "\t%s %s `avro:%q`\n", goName(f.Name()), typ, f.Name())
+	}
+	return nil
+}
+
+// errorTypes returns the declared errors of the message.
+func errorTypes(msg *avro.Message) []avro.NamedSchema {
+	if msg.Errors() == nil {
+		return nil
+	}
+
+	var errs []avro.NamedSchema
+	for _, typ := range msg.Errors().Types()[1:] {
+		if ref, ok := typ.(*avro.RefSchema); ok {
+			typ = ref.Schema()
+		}
+		if rec, ok := typ.(*avro.RecordSchema); ok {
+			errs = append(errs, rec)
+		}
+	}
+	return errs
+}
+
+// goName converts an Avro name into an exported Go identifier.
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	})
+
+	var b strings.Builder
+	for _, p := range parts {
+		r := []rune(p)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,104 @@
+package gen_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/gen"
+)
+
+var update = flag.Bool("update", false, "Update golden files")
+
+func TestProtocol(t *testing.T) {
+	proto, err := avro.ParseProtocolFile("../testdata/echo.avpr")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	err = gen.Protocol(buf, proto, gen.Config{PackageName: "echo"})
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile("testdata/echo.golden", buf.Bytes(), 0o600))
+	}
+
+	want, err := ioutil.ReadFile("testdata/echo.golden")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String())
+}
+
+func TestProtocol_Types(t *testing.T) {
+	proto := avro.MustParseProtocol(`{
+		"protocol": "test_service",
+		"namespace": "org.hamba.avro",
+		"types": [
+			{"name": "Kind", "type": "enum", "symbols": ["A", "B"]},
+			{"name": "Hash", "type": "fixed", "size": 4},
+			{"name": "Failure", "type": "error", "fields": [{"name": "reason", "type": "string"}]}
+		],
+		"messages": {
+			"put_item": {
+				"request": [
+					{"name": "item_id", "type": "long"},
+					{"name": "kind", "type": "Kind"},
+					{"name": "hash", "type": "Hash"},
+					{"name": "tags", "type": {"type": "array", "items": "string"}},
+					{"name": "attrs", "type": {"type": "map", "values": "int"}},
+					{"name": "note", "type": ["null", "string"]},
+					{"name": "value", "type": ["int", "string"]},
+					{"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+					{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}},
+					{"name": "inner", "type": {"type": "record", "name": "Inner", "fields": [{"name": "a", "type": "double"}]}}
+				],
+				"response": "null",
+				"errors": ["Failure"]
+			},
+			"log": {
+				"request": [{"name": "msg", "type": "string"}],
+				"one-way": true
+			}
+		}
+	}`)
+
+	buf := &bytes.Buffer{}
+	err := gen.Protocol(buf, proto, gen.Config{PackageName: "test"})
+
+	require.NoError(t, err)
+	got := buf.String()
+	for _, want := range []string{
+		"\t\"math/big\"\n",
+		"\t\"time\"\n",
+		"type Inner struct {\n\tA float64 `avro:\"a\"`\n}",
+		"func (e *Failure) Error() string",
+		"\tItemId int64 ",
+		"\tKind   string ",
+		"\tHash   [4]byte ",
+		"\tTags   []string ",
+		"\tAttrs  map[string]int ",
+		"\tNote   *string ",
+		"\tValue  map[string]interface{} ",
+		"\tAt     time.Time ",
+		"\tPrice  *big.Rat ",
+		"\tInner  Inner ",
+		"type TestService interface {",
+		"\tLog(ctx context.Context, req *LogRequest) error\n",
+		"\tPutItem(ctx context.Context, req *PutItemRequest) error\n",
+		"return c.client.Call(ctx, \"log\", req, nil)",
+		"\"org.hamba.avro.Failure\": &Failure{},",
+		"func RegisterTestService(srv *ipc.Server, impl TestService) error {",
+	} {
+		assert.Contains(t, got, want)
+	}
+}
+
+func TestProtocol_RequiresPackageName(t *testing.T) {
+	proto := avro.MustParseProtocol(`{"protocol": "test", "messages": {}}`)
+
+	err := gen.Protocol(&bytes.Buffer{}, proto, gen.Config{})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,1011 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// ParseIDL parses an Avro IDL protocol.
+//
+// Imports are resolved relative to the current working directory.
+func ParseIDL(idl string) (*Protocol, error) {
+	return parseIDL(idl, ".", map[string]bool{})
+}
+
+// ParseIDLFile parses an Avro IDL protocol from a file.
+//
+// Imports are resolved relative to the directory of the file.
+func ParseIDLFile(path string) (*Protocol, error) {
+	path = filepath.Clean(path)
+	s, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := map[string]bool{}
+	if abs, err := filepath.Abs(path); err == nil {
+		imported[abs] = true
+	}
+
+	return parseIDL(string(s), filepath.Dir(path), imported)
+}
+
+// MustParseIDL parses an Avro IDL protocol, panicing if there is an error.
+func MustParseIDL(idl string) *Protocol {
+	parsed, err := ParseIDL(idl)
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+func parseIDL(idl, dir string, imported map[string]bool) (*Protocol, error) {
+	p, err := newIDLParser(idl, dir, imported)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := p.parseProtocol()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProtocol(m)
+}
+
+type idlTokenKind int
+
+const (
+	idlEOF idlTokenKind = iota
+	idlIdent
+	idlString
+	idlNumber
+	idlPunct
+)
+
+type idlToken struct {
+	kind idlTokenKind
+	val  string
+	line int
+	doc  string
+
+	// quoted is set for identifiers escaped with backticks, which are never keywords.
+	quoted bool
+}
+
+func (t idlToken) String() string {
+	if t.kind == idlEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+func lexIDL(src string) ([]idlToken, error) {
+	var toks []idlToken
+	line := 1
+	doc := ""
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("avro: idl: line %d: unterminated comment", line)
+			}
+			comment := src[i+2 : i+2+end]
+			if strings.HasPrefix(comment, "*") && comment != "*" {
+				doc = cleanIDLDoc(comment[1:])
+			}
+			line += strings.Count(comment, "\n")
+			i += end + 4
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+				if j < len(src) && src[j] == '\n' {
+					return nil, fmt.Errorf("avro: idl: line %d: unterminated string", line)
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("avro: idl: line %d: unterminated string", line)
+			}
+			var str string
+			if err := jsoniter.Unmarshal([]byte(src[i:j+1]), &str); err != nil {
+				return nil, fmt.Errorf("avro: idl: line %d: invalid string %s", line, src[i:j+1])
+			}
+			toks = append(toks, idlToken{kind: idlString, val: str, line: line, doc: doc})
+			doc = ""
+			i = j + 1
+
+		case c == '`':
+			end := strings.IndexByte(src[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("avro: idl: line %d: unterminated identifier", line)
+			}
+			toks = append(toks, idlToken{kind: idlIdent, val: src[i+1 : i+1+end], line: line, doc: doc, quoted: true})
+			doc = ""
+			i += end + 2
+
+		case c == '-' || isIDLDigit(c):
+			j := i + 1
+			for ; j < len(src) && (isIDLDigit(src[j]) || strings.IndexByte(".eE+-", src[j]) >= 0); j++ {
+			}
+			toks = append(toks, idlToken{kind: idlNumber, val: src[i:j], line: line, doc: doc})
+			doc = ""
+			i = j
+
+		case isIDLIdentStart(c):
+			// Annotation names, such as java-class, may contain dashes.
+			annotation := len(toks) > 0 && toks[len(toks)-1].kind == idlPunct && toks[len(toks)-1].val == "@"
+			j := i + 1
+			for ; j < len(src) && (isIDLIdentStart(src[j]) || isIDLDigit(src[j]) || src[j] == '.' || (annotation && src[j] == '-')); j++ {
+			}
+			toks = append(toks, idlToken{kind: idlIdent, val: src[i:j], line: line, doc: doc})
+			doc = ""
+			i = j
+
+		case strings.IndexByte("{}()<>[],;:=?@", c) >= 0:
+			toks = append(toks, idlToken{kind: idlPunct, val: string(c), line: line, doc: doc})
+			doc = ""
+			i++
+
+		default:
+			return nil, fmt.Errorf("avro: idl: line %d: unexpected character %q", line, c)
+		}
+	}
+
+	return append(toks, idlToken{kind: idlEOF, line: line}), nil
+}
+
+func isIDLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIDLIdentStart(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+func cleanIDLDoc(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		l = strings.TrimPrefix(l, "*")
+		lines[i] = strings.TrimSpace(l)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+type idlParser struct {
+	toks []idlToken
+	pos  int
+
+	dir      string
+	imported map[string]bool
+
+	// namespace is the protocol namespace, space the namespace of the declaration being parsed.
+	namespace string
+	space     string
+	names     map[string]bool
+
+	types    []interface{}
+	messages map[string]interface{}
+}
+
+func newIDLParser(idl, dir string, imported map[string]bool) (*idlParser, error) {
+	toks, err := lexIDL(idl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idlParser{
+		toks:     toks,
+		dir:      dir,
+		imported: imported,
+		names:    map[string]bool{},
+		types:    []interface{}{},
+		messages: map[string]interface{}{},
+	}, nil
+}
+
+func (p *idlParser) errorf(tok idlToken, format string, args ...interface{}) error {
+	return fmt.Errorf("avro: idl: line %d: %s", tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *idlParser) peek() idlToken {
+	return p.toks[p.pos]
+}
+
+func (p *idlParser) next() idlToken {
+	tok := p.toks[p.pos]
+	if tok.kind != idlEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *idlParser) isPunct(val string) bool {
+	tok := p.peek()
+	return tok.kind == idlPunct && tok.val == val
+}
+
+func (p *idlParser) isKeyword(val string) bool {
+	tok := p.peek()
+	return tok.kind == idlIdent && !tok.quoted && tok.val == val
+}
+
+func (p *idlParser) expectPunct(val string) error {
+	tok := p.next()
+	if tok.kind != idlPunct || tok.val != val {
+		return p.errorf(tok, "expected %q, found %s", val, tok)
+	}
+	return nil
+}
+
+func (p *idlParser) expectKeyword(val string) error {
+	tok := p.next()
+	if tok.kind != idlIdent || tok.quoted || tok.val != val {
+		return p.errorf(tok, "expected %q, found %s", val, tok)
+	}
+	return nil
+}
+
+func (p *idlParser) expectIdent() (string, error) {
+	tok := p.next()
+	if tok.kind != idlIdent {
+		return "", p.errorf(tok, "expected identifier, found %s", tok)
+	}
+	return tok.val, nil
+}
+
+func (p *idlParser) expectString() (string, error) {
+	tok := p.next()
+	if tok.kind != idlString {
+		return "", p.errorf(tok, "expected string, found %s", tok)
+	}
+	return tok.val, nil
+}
+
+func (p *idlParser) parseProtocol() (map[string]interface{}, error) {
+	doc := p.peek().doc
+	props, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("protocol"); err != nil {
+		return nil, err
+	}
+	protoName, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	proto := map[string]interface{}{"protocol": protoName}
+	if err = p.setNamespace(proto, props); err != nil {
+		return nil, err
+	}
+	n, err := newName(protoName, p.namespace)
+	if err != nil {
+		return nil, err
+	}
+	p.namespace = n.space
+	if doc != "" {
+		proto["doc"] = doc
+	}
+	for k, v := range props {
+		proto[k] = v
+	}
+
+	if err = p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	for !p.isPunct("}") {
+		if p.peek().kind == idlEOF {
+			return nil, p.errorf(p.peek(), "expected \"}\", found %s", p.peek())
+		}
+		if err = p.parseDeclaration(); err != nil {
+			return nil, err
+		}
+	}
+	p.next()
+	if tok := p.next(); tok.kind != idlEOF {
+		return nil, p.errorf(tok, "unexpected %s after protocol", tok)
+	}
+
+	proto["types"] = p.types
+	proto["messages"] = p.messages
+	return proto, nil
+}
+
+func (p *idlParser) parseDeclaration() error {
+	if p.isKeyword("import") {
+		return p.parseImport()
+	}
+
+	doc := p.peek().doc
+	props, err := p.parseAnnotations()
+	if err != nil {
+		return err
+	}
+
+	p.space = p.namespace
+	switch {
+	case p.isKeyword("record"), p.isKeyword("error"):
+		return p.parseRecord(doc, props)
+	case p.isKeyword("enum"):
+		return p.parseEnum(doc, props)
+	case p.isKeyword("fixed"):
+		return p.parseFixed(doc, props)
+	default:
+		return p.parseMessage(doc, props)
+	}
+}
+
+func (p *idlParser) parseAnnotations() (map[string]interface{}, error) {
+	props := map[string]interface{}{}
+	for p.isPunct("@") {
+		p.next()
+		tok := p.peek()
+		key, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectPunct("("); err != nil {
+			return nil, err
+		}
+		val, err := p.parseJSON()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+
+		if _, ok := props[key]; ok {
+			return nil, p.errorf(tok, "duplicate annotation %q", key)
+		}
+		props[key] = val
+	}
+	return props, nil
+}
+
+func (p *idlParser) parseJSON() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case idlString:
+		return tok.val, nil
+
+	case idlNumber:
+		var v float64
+		if err := jsoniter.Unmarshal([]byte(tok.val), &v); err != nil {
+			return nil, p.errorf(tok, "invalid number %s", tok.val)
+		}
+		return v, nil
+
+	case idlIdent:
+		switch tok.val {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+
+	case idlPunct:
+		switch tok.val {
+		case "[":
+			arr := []interface{}{}
+			for !p.isPunct("]") {
+				if len(arr) > 0 {
+					if err := p.expectPunct(","); err != nil {
+						return nil, err
+					}
+				}
+				v, err := p.parseJSON()
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, v)
+			}
+			p.next()
+			return arr, nil
+
+		case "{":
+			obj := map[string]interface{}{}
+			for !p.isPunct("}") {
+				if len(obj) > 0 {
+					if err := p.expectPunct(","); err != nil {
+						return nil, err
+					}
+				}
+				key, err := p.expectString()
+				if err != nil {
+					return nil, err
+				}
+				if err = p.expectPunct(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.parseJSON()
+				if err != nil {
+					return nil, err
+				}
+				obj[key] = v
+			}
+			p.next()
+			return obj, nil
+		}
+	}
+
+	return nil, p.errorf(tok, "expected json value, found %s", tok)
+}
+
+func (p *idlParser) setNamespace(m, props map[string]interface{}) error {
+	v, ok := props["namespace"]
+	if !ok {
+		return nil
+	}
+	delete(props, "namespace")
+
+	ns, ok := v.(string)
+	if !ok {
+		return errors.New("avro: idl: namespace must be a string")
+	}
+	if ns != "" {
+		m["namespace"] = ns
+	}
+	p.namespace = ns
+	return nil
+}
+
+func (p *idlParser) startNamed(typ, doc string, props map[string]interface{}) (map[string]interface{}, error) {
+	tok := p.peek()
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	space := p.namespace
+	if v, ok := props["namespace"]; ok {
+		delete(props, "namespace")
+		if space, ok = v.(string); !ok {
+			return nil, p.errorf(tok, "namespace must be a string")
+		}
+	}
+	n, err := newName(name, space)
+	if err != nil {
+		return nil, p.errorf(tok, "%v", err)
+	}
+	if p.names[n.full] {
+		return nil, p.errorf(tok, "duplicate type %q", n.full)
+	}
+	p.names[n.full] = true
+	p.space = n.space
+
+	m := map[string]interface{}{"type": typ, "name": n.name}
+	if n.space != "" {
+		m["namespace"] = n.space
+	}
+	if doc != "" {
+		m["doc"] = doc
+	}
+	for k, v := range props {
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (p *idlParser) parseRecord(doc string, props map[string]interface{}) error {
+	typ := p.next().val
+	rec, err := p.startNamed(typ, doc, props)
+	if err != nil {
+		return err
+	}
+
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	fields := []interface{}{}
+	for !p.isPunct("}") {
+		fs, err := p.parseFields(";")
+		if err != nil {
+			return err
+		}
+		if err = p.expectPunct(";"); err != nil {
+			return err
+		}
+		fields = append(fields, fs...)
+	}
+	p.next()
+
+	rec["fields"] = fields
+	p.types = append(p.types, rec)
+	return nil
+}
+
+// parseFields parses a field declaration, which may declare several variables of the same type.
+func (p *idlParser) parseFields(end string) ([]interface{}, error) {
+	doc := p.peek().doc
+	props, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	// The order and aliases annotations apply to the field, all others to the type.
+	fieldProps := map[string]interface{}{}
+	for _, k := range []string{"order", "aliases"} {
+		if v, ok := props[k]; ok {
+			fieldProps[k] = v
+			delete(props, k)
+		}
+	}
+
+	typ, nullable, err := p.parseType(props)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []interface{}
+	for {
+		if d := p.peek().doc; d != "" {
+			doc = d
+		}
+		varProps, err := p.parseAnnotations()
+		if err != nil {
+			return nil, err
+		}
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+
+		field := map[string]interface{}{"name": name, "type": typ}
+		for k, v := range fieldProps {
+			field[k] = v
+		}
+		for k, v := range varProps {
+			field[k] = v
+		}
+		if doc != "" {
+			field["doc"] = doc
+		}
+
+		if p.isPunct("=") {
+			p.next()
+			def, err := p.parseJSON()
+			if err != nil {
+				return nil, err
+			}
+			field["default"] = def
+
+			// The default of a union must match its first type.
+			if nullable && def != nil {
+				u := typ.([]interface{})
+				field["type"] = []interface{}{u[1], u[0]}
+			}
+		}
+		fields = append(fields, field)
+
+		if !p.isPunct(",") || end == "," {
+			return fields, nil
+		}
+		p.next()
+	}
+}
+
+func (p *idlParser) parseEnum(doc string, props map[string]interface{}) error {
+	p.next()
+	enum, err := p.startNamed("enum", doc, props)
+	if err != nil {
+		return err
+	}
+
+	if err = p.expectPunct("{"); err != nil {
+		return err
+	}
+	symbols := []interface{}{}
+	for !p.isPunct("}") {
+		if len(symbols) > 0 {
+			if err = p.expectPunct(","); err != nil {
+				return err
+			}
+		}
+		sym, err := p.expectIdent()
+		if err != nil {
+			return err
+		}
+		symbols = append(symbols, sym)
+	}
+	p.next()
+	enum["symbols"] = symbols
+
+	if p.isPunct("=") {
+		p.next()
+		def, err := p.expectIdent()
+		if err != nil {
+			return err
+		}
+		enum["default"] = def
+	}
+	if p.isPunct(";") {
+		p.next()
+	}
+
+	p.types = append(p.types, enum)
+	return nil
+}
+
+func (p *idlParser) parseFixed(doc string, props map[string]interface{}) error {
+	p.next()
+	fixed, err := p.startNamed("fixed", doc, props)
+	if err != nil {
+		return err
+	}
+
+	if err = p.expectPunct("("); err != nil {
+		return err
+	}
+	size, err := p.parseJSON()
+	if err != nil {
+		return err
+	}
+	if err = p.expectPunct(")"); err != nil {
+		return err
+	}
+	if err = p.expectPunct(";"); err != nil {
+		return err
+	}
+	fixed["size"] = size
+
+	p.types = append(p.types, fixed)
+	return nil
+}
+
+func (p *idlParser) parseMessage(doc string, props map[string]interface{}) error {
+	var resp interface{} = "null"
+	if p.isKeyword("void") {
+		p.next()
+	} else {
+		var err error
+		if resp, _, err = p.parseType(nil); err != nil {
+			return err
+		}
+	}
+
+	tok := p.peek()
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	if _, ok := p.messages[name]; ok {
+		return p.errorf(tok, "duplicate message %q", name)
+	}
+
+	if err = p.expectPunct("("); err != nil {
+		return err
+	}
+	req := []interface{}{}
+	for !p.isPunct(")") {
+		if len(req) > 0 {
+			if err = p.expectPunct(","); err != nil {
+				return err
+			}
+		}
+		fs, err := p.parseFields(",")
+		if err != nil {
+			return err
+		}
+		req = append(req, fs...)
+	}
+	p.next()
+
+	msg := map[string]interface{}{"request": req, "response": resp}
+	switch {
+	case p.isKeyword("oneway"):
+		p.next()
+		msg["one-way"] = true
+
+	case p.isKeyword("throws"):
+		p.next()
+		errs := []interface{}{}
+		for {
+			e, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			errs = append(errs, p.resolveRef(e))
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+		msg["errors"] = errs
+	}
+	if err = p.expectPunct(";"); err != nil {
+		return err
+	}
+
+	if doc != "" {
+		msg["doc"] = doc
+	}
+	for k, v := range props {
+		msg[k] = v
+	}
+	p.messages[name] = msg
+	return nil
+}
+
+var idlLogicalTypes = map[string][2]string{
+	"date":         {string(Int), string(Date)},
+	"time_ms":      {string(Int), string(TimeMillis)},
+	"timestamp_ms": {string(Long), string(TimestampMillis)},
+	"uuid":         {string(String), string(UUID)},
+}
+
+// parseType parses a type with the given properties, reporting if the type was declared nullable.
+func (p *idlParser) parseType(props map[string]interface{}) (interface{}, bool, error) {
+	tok := p.next()
+	if tok.kind != idlIdent {
+		return nil, false, p.errorf(tok, "expected type, found %s", tok)
+	}
+
+	var typ interface{}
+	switch val := tok.val; {
+	case tok.quoted:
+		typ = p.resolveRef(val)
+
+	case val == "array" || val == "map":
+		if err := p.expectPunct("<"); err != nil {
+			return nil, false, err
+		}
+		inner, err := p.parseInnerType()
+		if err != nil {
+			return nil, false, err
+		}
+		if err = p.expectPunct(">"); err != nil {
+			return nil, false, err
+		}
+		key := "items"
+		if val == "map" {
+			key = "values"
+		}
+		typ = map[string]interface{}{"type": val, key: inner}
+
+	case val == "union":
+		if err := p.expectPunct("{"); err != nil {
+			return nil, false, err
+		}
+		types := []interface{}{}
+		for !p.isPunct("}") {
+			if len(types) > 0 {
+				if err := p.expectPunct(","); err != nil {
+					return nil, false, err
+				}
+			}
+			inner, err := p.parseInnerType()
+			if err != nil {
+				return nil, false, err
+			}
+			types = append(types, inner)
+		}
+		p.next()
+		if len(props) > 0 {
+			return nil, false, p.errorf(tok, "unions cannot be annotated")
+		}
+		return types, false, nil
+
+	case val == "decimal":
+		if err := p.expectPunct("("); err != nil {
+			return nil, false, err
+		}
+		prec, err := p.parseJSON()
+		if err != nil {
+			return nil, false, err
+		}
+		if err = p.expectPunct(","); err != nil {
+			return nil, false, err
+		}
+		scale, err := p.parseJSON()
+		if err != nil {
+			return nil, false, err
+		}
+		if err = p.expectPunct(")"); err != nil {
+			return nil, false, err
+		}
+		typ = map[string]interface{}{
+			"type":        string(Bytes),
+			"logicalType": string(Decimal),
+			"precision":   prec,
+			"scale":       scale,
+		}
+
+	case idlLogicalTypes[val][0] != "":
+		lt := idlLogicalTypes[val]
+		typ = map[string]interface{}{"type": lt[0], "logicalType": lt[1]}
+
+	default:
+		switch Type(val) {
+		case Null, String, Bytes, Int, Long, Float, Double, Boolean:
+			typ = val
+		default:
+			typ = p.resolveRef(val)
+		}
+	}
+
+	if len(props) > 0 {
+		switch t := typ.(type) {
+		case string:
+			if !isPrimitiveTypeName(t) {
+				return nil, false, p.errorf(tok, "type references cannot be annotated")
+			}
+			m := map[string]interface{}{"type": t}
+			for k, v := range props {
+				m[k] = v
+			}
+			typ = m
+		case map[string]interface{}:
+			for k, v := range props {
+				t[k] = v
+			}
+		}
+	}
+
+	if p.isPunct("?") {
+		p.next()
+		return []interface{}{string(Null), typ}, true, nil
+	}
+	return typ, false, nil
+}
+
+func (p *idlParser) parseInnerType() (interface{}, error) {
+	props, err := p.parseAnnotations()
+	if err != nil {
+		return nil, err
+	}
+	typ, _, err := p.parseType(props)
+	return typ, err
+}
+
+func isPrimitiveTypeName(s string) bool {
+	switch Type(s) {
+	case Null, String, Bytes, Int, Long, Float, Double, Boolean:
+		return true
+	}
+	return false
+}
+
+// resolveRef resolves a type reference to its full name, looking in the
+// namespace of the current declaration before the protocol namespace.
+func (p *idlParser) resolveRef(n string) string {
+	if strings.ContainsRune(n, '.') {
+		return n
+	}
+
+	for _, space := range []string{p.space, p.namespace} {
+		if full := fullName(space, n); p.names[full] {
+			return full
+		}
+	}
+	return fullName(p.space, n)
+}
+
+func (p *idlParser) parseImport() error {
+	p.next()
+	tok := p.peek()
+	kind, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	file, err := p.expectString()
+	if err != nil {
+		return err
+	}
+	if err = p.expectPunct(";"); err != nil {
+		return err
+	}
+
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if p.imported[abs] {
+		return nil
+	}
+	p.imported[abs] = true
+
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "idl":
+		sub, err := newIDLParser(string(b), filepath.Dir(path), p.imported)
+		if err != nil {
+			return err
+		}
+		sub.names = p.names
+		proto, err := sub.parseProtocol()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		p.addTypes(proto["types"].([]interface{}), "")
+		return p.addMessages(tok, proto["messages"].(map[string]interface{}))
+
+	case "protocol":
+		var proto map[string]interface{}
+		if err = jsoniter.Unmarshal(b, &proto); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		space, _ := proto["namespace"].(string)
+		types, _ := proto["types"].([]interface{})
+		p.addTypes(types, space)
+		msgs, _ := proto["messages"].(map[string]interface{})
+		return p.addMessages(tok, msgs)
+
+	case "schema":
+		var schema interface{}
+		if err = jsoniter.Unmarshal(b, &schema); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		p.addTypes([]interface{}{schema}, "")
+		return nil
+
+	default:
+		return p.errorf(tok, "unknown import type %q", kind)
+	}
+}
+
+// addTypes adds imported types, making the namespace they were declared in explicit.
+func (p *idlParser) addTypes(types []interface{}, space string) {
+	for _, typ := range types {
+		if m, ok := typ.(map[string]interface{}); ok && space != "" {
+			if _, ok = m["namespace"]; !ok {
+				m["namespace"] = space
+			}
+		}
+		defs := map[string]interface{}{}
+		collectNames(typ, "", defs, nil)
+		for name := range defs {
+			p.names[name] = true
+		}
+		p.types = append(p.types, typ)
+	}
+}
+
+func (p *idlParser) addMessages(tok idlToken, msgs map[string]interface{}) error {
+	for k, v := range msgs {
+		if _, ok := p.messages[k]; ok {
+			return p.errorf(tok, "duplicate message %q", k)
+		}
+		p.messages[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package avro_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+func TestParseIDLFile(t *testing.T) {
+	proto, err := avro.ParseIDLFile("testdata/idl/simple.avdl")
+
+	require.NoError(t, err)
+	assert.Equal(t, "org.hamba.avro.Simple", proto.FullName())
+	assert.Equal(t, "An example protocol in Avro IDL.", proto.Doc())
+
+	names := make([]string, len(proto.Types()))
+	for i, typ := range proto.Types() {
+		names[i] = typ.FullName()
+	}
+	assert.Equal(t, []string{
+		"org.hamba.common.Kind",
+		"org.hamba.common.Hash",
+		"org.hamba.avro.Status",
+		"org.hamba.avro.TestRecord",
+		"org.hamba.avro.TestError",
+	}, names)
+
+	rec := proto.Types()[3].(*avro.RecordSchema)
+	assert.Equal(t, "A TestRecord.", rec.Doc())
+	assert.Equal(t, []string{"org.hamba.avro.OldRecord"}, rec.Aliases())
+
+	want := `{"name":"org.hamba.avro.TestRecord","type":"record","fields":[` +
+		`{"name":"error","type":"string"},` +
+		`{"name":"kind","type":{"name":"org.hamba.common.Kind","type":"enum","symbols":["FOO","BAR"]}},` +
+		`{"name":"hash","type":{"name":"org.hamba.common.Hash","type":"fixed","size":16}},` +
+		`{"name":"status","type":{"name":"org.hamba.avro.Status","type":"enum","symbols":["ACTIVE","INACTIVE"]}},` +
+		`{"name":"note","type":["null","string"]},` +
+		`{"name":"count","type":["null","int"]},` +
+		`{"name":"stamp","type":"long"},` +
+		`{"name":"updated","type":"long"},` +
+		`{"name":"tags","type":{"type":"array","items":"string"}},` +
+		`{"name":"attrs","type":{"type":"map","values":"int"}},` +
+		`{"name":"createdAt","type":{"type":"long","logicalType":"timestamp-micros"}},` +
+		`{"name":"day","type":{"type":"int","logicalType":"date"}},` +
+		`{"name":"price","type":{"type":"bytes","logicalType":"decimal","precision":9,"scale":2}}` +
+		`]}`
+	assert.Equal(t, want, rec.String())
+
+	fields := rec.Fields()
+	assert.Equal(t, "Tests that keywords can be escaped.", fields[0].Doc())
+	assert.Equal(t, "FOO", fields[1].Default())
+	assert.Equal(t, []string{"oldStamp"}, fields[6].Aliases())
+	assert.Nil(t, fields[7].Aliases())
+	assert.Equal(t, int64(0), fields[7].Default())
+
+	errRec := proto.Types()[4].(*avro.RecordSchema)
+	assert.True(t, errRec.IsError())
+
+	assert.Len(t, proto.Messages(), 5)
+	hello := proto.Message("hello")
+	require.NotNil(t, hello)
+	assert.Equal(t, `{"request":[{"name":"greeting","type":"string"}],"response":"string"}`, hello.String())
+	echo := proto.Message("echo")
+	require.NotNil(t, echo)
+	assert.Equal(t, "record", echo.Request().Fields()[0].Name())
+	assert.Equal(t, `["int","null"]`, echo.Request().Fields()[1].Type().String())
+	assert.Equal(t, "org.hamba.avro.TestRecord", echo.Response().(*avro.RefSchema).Schema().(avro.NamedSchema).FullName())
+	errMsg := proto.Message("error")
+	require.NotNil(t, errMsg)
+	assert.Equal(t, `["string","org.hamba.avro.TestError"]`, errMsg.Errors().String())
+	assert.True(t, proto.Message("ping").OneWay())
+	add := proto.Message("add")
+	require.NotNil(t, add)
+	assert.Equal(t, 0, add.Request().Fields()[1].Default())
+}
+
+func TestParseIDL_FileNotFound(t *testing.T) {
+	_, err := avro.ParseIDLFile("testdata/idl/missing.avdl")
+
+	assert.Error(t, err)
+}
+
+func TestParseIDL(t *testing.T) {
+	tests := []struct {
+		name    string
+		idl     string
+		wantErr bool
+	}{
+		{
+			name:    "Valid",
+			idl:     `protocol Test { record A { int a; } A get(); }`,
+			wantErr: false,
+		},
+		{
+			name:    "Namespace",
+			idl:     `@namespace("org.hamba.avro") protocol Test { @namespace("org.hamba.other") fixed A(4); org.hamba.other.A get(); }`,
+			wantErr: false,
+		},
+		{
+			name:    "Recursive Record",
+			idl:     `protocol Test { record Node { string value; Node? next; } }`,
+			wantErr: false,
+		},
+		{
+			name:    "Comments",
+			idl:     "// line comment\n/* block comment */ protocol Test { /**/ }",
+			wantErr: false,
+		},
+		{
+			name:    "Not A Protocol",
+			idl:     `record A { int a; }`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated Protocol",
+			idl:     `protocol Test { record A { int a; }`,
+			wantErr: true,
+		},
+		{
+			name:    "Trailing Input",
+			idl:     `protocol Test { } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Type",
+			idl:     `protocol Test { record A { B b; } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate Type",
+			idl:     `protocol Test { fixed A(4); fixed A(8); }`,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate Message",
+			idl:     `protocol Test { void a(); void a(); }`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Default",
+			idl:     `protocol Test { record A { int a = "test"; } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid Name",
+			idl:     `protocol Test { record A { int 0a; } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Annotated Type Reference",
+			idl:     `protocol Test { fixed A(4); record B { @foo("bar") A a; } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate Annotation",
+			idl:     `@foo(1) @foo(2) protocol Test { }`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid JSON",
+			idl:     `protocol Test { record A { array<int> a = [1 2]; } }`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated String",
+			idl:     `@foo("bar) protocol Test { }`,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated Comment",
+			idl:     `/* protocol Test { }`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Import",
+			idl:     `protocol Test { import foo "bar"; }`,
+			wantErr: true,
+		},
+		{
+			name:    "Missing Import",
+			idl:     `protocol Test { import idl "testdata/idl/missing.avdl"; }`,
+			wantErr: true,
+		},
+		{
+			name:    "Non Error Thrown",
+			idl:     `protocol Test { record A { int a; } void a() throws A; }`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := avro.ParseIDL(test.idl)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseIDL_TypeAnnotations(t *testing.T) {
+	proto := avro.MustParseIDL(`protocol Test {
+		record A {
+			@logicalType("uuid") string id;
+			@java-class("java.util.ArrayList") array<long> values;
+			timestamp_ms at;
+			time_ms time;
+			uuid other;
+		}
+	}`)
+
+	rec := proto.Types()[0].(*avro.RecordSchema)
+	fields := rec.Fields()
+	assert.Equal(t, avro.UUID, fields[0].Type().(*avro.PrimitiveSchema).Logical().Type())
+	assert.Equal(t, "java.util.ArrayList", fields[1].Type().(*avro.ArraySchema).Prop("java-class"))
+	assert.Equal(t, avro.TimestampMillis, fields[2].Type().(*avro.PrimitiveSchema).Logical().Type())
+	assert.Equal(t, avro.TimeMillis, fields[3].Type().(*avro.PrimitiveSchema).Logical().Type())
+	assert.Equal(t, avro.UUID, fields[4].Type().(*avro.PrimitiveSchema).Logical().Type())
+}
+
+func TestParseIDL_NullableDefault(t *testing.T) {
+	proto := avro.MustParseIDL(`protocol Test {
+		record A {
+			string? a = null;
+			string? b = "test";
+		}
+	}`)
+
+	fields := proto.Types()[0].(*avro.RecordSchema).Fields()
+	assert.Equal(t, `["null","string"]`, fields[0].Type().String())
+	assert.Nil(t, fields[0].Default())
+	assert.Equal(t, `["string","null"]`, fields[1].Type().String())
+	assert.Equal(t, "test", fields[1].Default())
+}
+
+func TestMustParseIDL_PanicsOnError(t *testing.T) {
+	assert.Panics(t, func() {
+		avro.MustParseIDL("protocol")
+	})
+}
@@ -0,0 +1,252 @@
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	avro "github.com/xl4hub/hamba-avro"
+)
+
+// Call is a call to a message.
+type Call struct {
+	// Message is the name of the message to call.
+	Message string
+	// Meta is the call metadata sent to the server.
+	Meta map[string][]byte
+	// Request is the request parameters, encoded as a record with a field per parameter.
+	Request interface{}
+	// Response is decoded into from the message response. It may be nil to discard the response.
+	Response interface{}
+	// ResponseMeta is set to the response metadata sent by the server.
+	ResponseMeta map[string][]byte
+	// Errors maps the full names of declared errors to the values they are decoded into.
+	// The value of an *Error is the given value if its name is in Errors.
+	Errors map[string]interface{}
+}
+
+// Client calls the messages of a protocol on a server.
+type Client struct {
+	proto     *avro.Protocol
+	hash      [16]byte
+	transport Transport
+
+	mu         sync.Mutex
+	server     *avro.Protocol
+	serverHash [16]byte
+	connected  bool
+}
+
+// NewClient returns a client for the given protocol that sends calls with transport.
+func NewClient(proto *avro.Protocol, transport Transport) *Client {
+	hash := hashBytes(proto)
+
+	return &Client{
+		proto:      proto,
+		hash:       hash,
+		transport:  transport,
+		server:     proto,
+		serverHash: hash,
+	}
+}
+
+// Call calls the named message with the request parameters in req, decoding the response into resp.
+//
+// Errors returned by the message are returned as an *Error.
+func (c *Client) Call(ctx context.Context, message string, req, resp interface{}) error {
+	return c.Do(ctx, &Call{Message: message, Request: req, Response: resp})
+}
+
+// Do performs the call.
+//
+// Errors returned by the message are returned as an *Error.
+func (c *Client) Do(ctx context.Context, call *Call) error {
+	msg := c.proto.Message(call.Message)
+	if msg == nil {
+		return fmt.Errorf("ipc: unknown message %q", call.Message)
+	}
+
+	w := avro.NewWriter(nil, 512)
+	writeCall(w, call.Meta, call.Message)
+	w.WriteVal(msg.Request(), call.Request)
+	if w.Error != nil {
+		return w.Error
+	}
+
+	var (
+		resp []byte
+		err  error
+	)
+	if c.transport.Stateful() {
+		if err = c.connect(ctx); err != nil {
+			return err
+		}
+		resp, err = c.transport.RoundTrip(ctx, w.Buffer(), msg.OneWay())
+	} else {
+		resp, err = c.roundTrip(ctx, w.Buffer())
+	}
+	if err != nil {
+		return err
+	}
+
+	if msg.OneWay() {
+		return nil
+	}
+
+	return c.readResult(avro.NewReader(nil, 0).Reset(resp), call, msg)
+}
+
+// connect performs the handshake of a stateful transport, if it has not been performed yet.
+func (c *Client) connect(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connected {
+		return nil
+	}
+
+	w := avro.NewWriter(nil, 64)
+	writeCall(w, nil, "")
+	if _, err := c.handshake(ctx, w.Buffer()); err != nil {
+		return err
+	}
+
+	c.connected = true
+	return nil
+}
+
+// roundTrip sends the call with a handshake, returning the remaining response after the handshake.
+func (c *Client) roundTrip(ctx context.Context, b []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.handshake(ctx, b)
+}
+
+// handshake sends the call prefixed with a handshake request, resending it
+// with the client protocol if the server does not know it.
+func (c *Client) handshake(ctx context.Context, b []byte) ([]byte, error) {
+	for _, withProto := range []bool{false, true} {
+		hr := HandshakeRequest{ClientHash: c.hash, ServerHash: c.serverHash}
+		if withProto {
+			proto := c.proto.String()
+			hr.ClientProtocol = &proto
+		}
+
+		w := avro.NewWriter(nil, 512)
+		w.WriteVal(HandshakeRequestSchema, hr)
+		w.Write(b)
+		if w.Error != nil {
+			return nil, w.Error
+		}
+
+		// The handshake response is always sent, so one-way calls wait for it.
+		data, err := c.transport.RoundTrip(ctx, w.Buffer(), false)
+		if err != nil {
+			return nil, err
+		}
+
+		r := avro.NewReader(nil, 0).Reset(data)
+		var resp HandshakeResponse
+		r.ReadVal(HandshakeResponseSchema, &resp)
+		if r.Error != nil {
+			return nil, r.Error
+		}
+
+		if resp.Match != MatchBoth {
+			if err = c.setServer(resp); err != nil {
+				return nil, err
+			}
+		}
+		if resp.Match != MatchNone {
+			return data[r.InputOffset():], nil
+		}
+	}
+
+	return nil, errors.New("ipc: server does not accept the client protocol")
+}
+
+func (c *Client) setServer(resp HandshakeResponse) error {
+	if resp.ServerProtocol == nil || resp.ServerHash == nil {
+		return errors.New("ipc: handshake response is missing the server protocol")
+	}
+
+	proto, err := avro.ParseProtocol(*resp.ServerProtocol)
+	if err != nil {
+		return err
+	}
+
+	c.server = proto
+	c.serverHash = *resp.ServerHash
+	return nil
+}
+
+// readResult reads the response or error of a call.
+func (c *Client) readResult(r *avro.Reader, call *Call, msg *avro.Message) error {
+	c.mu.Lock()
+	if m := c.server.Message(call.Message); m != nil {
+		// The response is encoded with the server protocol.
+		msg = m
+	}
+	c.mu.Unlock()
+
+	meta := map[string][]byte{}
+	r.ReadVal(metaSchema, &meta)
+	call.ResponseMeta = meta
+
+	isErr := r.ReadBool()
+	if r.Error != nil {
+		return r.Error
+	}
+
+	if !isErr {
+		if msg.Response() == nil {
+			return nil
+		}
+
+		if call.Response == nil {
+			var v interface{}
+			r.ReadVal(msg.Response(), &v)
+		} else {
+			r.ReadVal(msg.Response(), call.Response)
+		}
+		return r.Error
+	}
+
+	idx := r.ReadLong()
+	if idx == 0 {
+		e := NewError(r.ReadString())
+		if r.Error != nil {
+			return r.Error
+		}
+		return e
+	}
+
+	types := msg.Errors().Types()
+	if idx < 0 || idx >= int64(len(types)) {
+		return fmt.Errorf("ipc: unknown error index %d", idx)
+	}
+
+	name := schemaName(types[idx])
+	v, ok := call.Errors[name]
+	if ok {
+		r.ReadVal(types[idx], v)
+	} else {
+		r.ReadVal(types[idx], &v)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	return &Error{Name: name, Value: v}
+}
+
+// writeCall writes the call metadata and message name.
+func writeCall(w *avro.Writer, meta map[string][]byte, message string) {
+	if meta == nil {
+		meta = map[string][]byte{}
+	}
+
+	w.WriteVal(metaSchema, meta)
+	w.WriteString(message)
+}
@@ -0,0 +1,170 @@
+/*
+Package ipc implements Avro RPC for protocols parsed with avro.ParseProtocol.
+
+Calls are made with a Client over a Transport and served by a Server, either over HTTP
+or as framed messages over a stream connection.
+
+See the Avro specification for an understanding of Avro RPC: http://avro.apache.org/docs/current/spec.html#Protocol+Wire+Format
+
+Messages are decoded with the schemas of the side that encoded them, so both sides should
+use protocols with the same message schemas. Schema resolution is not performed.
+*/
+package ipc
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	avro "github.com/xl4hub/hamba-avro"
+)
+
+// HandshakeRequestSchema is the Avro schema of a handshake request.
+var HandshakeRequestSchema = avro.MustParse(`{
+	"type": "record",
+	"name": "org.apache.avro.ipc.HandshakeRequest",
+	"fields": [
+		{"name": "clientHash", "type": {"type": "fixed", "name": "MD5", "size": 16}},
+		{"name": "clientProtocol", "type": ["null", "string"]},
+		{"name": "serverHash", "type": "MD5"},
+		{"name": "meta", "type": ["null", {"type": "map", "values": "bytes"}]}
+	]
+}`)
+
+// HandshakeResponseSchema is the Avro schema of a handshake response.
+var HandshakeResponseSchema = avro.MustParse(`{
+	"type": "record",
+	"name": "org.apache.avro.ipc.HandshakeResponse",
+	"fields": [
+		{"name": "match", "type": {"type": "enum", "name": "HandshakeMatch", "symbols": ["BOTH", "CLIENT", "NONE"]}},
+		{"name": "serverProtocol", "type": ["null", "string"]},
+		{"name": "serverHash", "type": ["null", {"type": "fixed", "name": "MD5", "size": 16}]},
+		{"name": "meta", "type": ["null", {"type": "map", "values": "bytes"}]}
+	]
+}`)
+
+// metaSchema is the Avro schema of call metadata.
+var metaSchema = avro.MustParse(`{"type": "map", "values": "bytes"}`)
+
+// HandshakeMatch is the result of a handshake.
+type HandshakeMatch string
+
+// HandshakeMatch constants.
+const (
+	// MatchBoth means the client and server protocols are known to each other.
+	MatchBoth HandshakeMatch = "BOTH"
+	// MatchClient means the client protocol is known, but the client has a different server protocol.
+	MatchClient HandshakeMatch = "CLIENT"
+	// MatchNone means the client protocol is not known to the server.
+	MatchNone HandshakeMatch = "NONE"
+)
+
+// HandshakeRequest is sent by a client before a call.
+type HandshakeRequest struct {
+	ClientHash     [16]byte           `avro:"clientHash"`
+	ClientProtocol *string            `avro:"clientProtocol"`
+	ServerHash     [16]byte           `avro:"serverHash"`
+	Meta           *map[string][]byte `avro:"meta"`
+}
+
+// HandshakeResponse is sent by a server in reply to a handshake request.
+type HandshakeResponse struct {
+	Match          HandshakeMatch     `avro:"match"`
+	ServerProtocol *string            `avro:"serverProtocol"`
+	ServerHash     *[16]byte          `avro:"serverHash"`
+	Meta           *map[string][]byte `avro:"meta"`
+}
+
+// Error is an error returned by a message.
+//
+// A handler returns an Error to send one of the declared errors of its message.
+// Any other error returned by a handler is sent as a string error.
+type Error struct {
+	// Name is the full name of the error schema, or "string" for string errors.
+	Name string
+	// Value is the error value.
+	Value interface{}
+}
+
+// NewError returns a string error with the given message.
+func NewError(msg string) *Error {
+	return &Error{Name: string(avro.String), Value: msg}
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	if msg, ok := e.Value.(string); ok && e.Name == string(avro.String) {
+		return msg
+	}
+	return fmt.Sprintf("ipc: %s: %v", e.Name, e.Value)
+}
+
+const (
+	// maxFrameSize is the maximum size of a buffer written to a stream.
+	maxFrameSize = 8192
+	// maxReadFrameSize is the maximum size of a buffer read from a stream.
+	maxReadFrameSize = 16 << 20
+)
+
+// writeFrames writes b as a list of length prefixed buffers, terminated by an empty buffer.
+func writeFrames(w io.Writer, b []byte) error {
+	out := make([]byte, 0, len(b)+4*(len(b)/maxFrameSize+2))
+	var l [4]byte
+	for len(b) > 0 {
+		n := len(b)
+		if n > maxFrameSize {
+			n = maxFrameSize
+		}
+
+		binary.BigEndian.PutUint32(l[:], uint32(n))
+		out = append(out, l[:]...)
+		out = append(out, b[:n]...)
+		b = b[n:]
+	}
+	out = append(out, 0, 0, 0, 0)
+
+	_, err := w.Write(out)
+	return err
+}
+
+// readFrames reads a list of length prefixed buffers up to the terminating empty buffer.
+func readFrames(r io.Reader) ([]byte, error) {
+	var (
+		b []byte
+		l [4]byte
+	)
+	for {
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			if len(b) > 0 && errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		n := binary.BigEndian.Uint32(l[:])
+		if n == 0 {
+			return b, nil
+		}
+		if n > maxReadFrameSize {
+			return nil, fmt.Errorf("ipc: frame size %d is too large", n)
+		}
+
+		start := len(b)
+		b = append(b, make([]byte, n)...)
+		if _, err := io.ReadFull(r, b[start:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+// hashBytes returns the MD5 hash of the protocol.
+func hashBytes(proto *avro.Protocol) [16]byte {
+	var h [16]byte
+	_, _ = hex.Decode(h[:], []byte(proto.Hash()))
+	return h
+}
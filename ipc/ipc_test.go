@@ -0,0 +1,306 @@
+package ipc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/ipc"
+)
+
+const protocol = `{
+	"protocol": "Echo",
+	"namespace": "org.hamba.avro",
+	"types": [
+		{"name": "Ping", "type": "record", "fields": [
+			{"name": "timestamp", "type": "long"},
+			{"name": "text", "type": "string"}
+		]},
+		{"name": "Pong", "type": "record", "fields": [
+			{"name": "timestamp", "type": "long"},
+			{"name": "ping", "type": "Ping"}
+		]},
+		{"name": "PongError", "type": "error", "fields": [
+			{"name": "reason", "type": "string"}
+		]}
+	],
+	"messages": {
+		"ping": {
+			"request": [{"name": "ping", "type": "Ping"}],
+			"response": "Pong",
+			"errors": ["PongError"]
+		},
+		"notify": {
+			"request": [{"name": "text", "type": "string"}],
+			"one-way": true
+		}
+	}
+}`
+
+type Ping struct {
+	Timestamp int64  `avro:"timestamp"`
+	Text      string `avro:"text"`
+}
+
+type Pong struct {
+	Timestamp int64 `avro:"timestamp"`
+	Ping      Ping  `avro:"ping"`
+}
+
+type PingRequest struct {
+	Ping Ping `avro:"ping"`
+}
+
+type NotifyRequest struct {
+	Text string `avro:"text"`
+}
+
+type PongError struct {
+	Reason string `avro:"reason"`
+}
+
+func newServer(t *testing.T, notified chan string) *ipc.Server {
+	t.Helper()
+
+	srv := ipc.NewServer(avro.MustParseProtocol(protocol))
+	err := srv.Handle("ping", func(ctx context.Context, req *ipc.Request) (interface{}, error) {
+		var r PingRequest
+		if err := req.Decode(&r); err != nil {
+			return nil, err
+		}
+
+		switch r.Ping.Text {
+		case "declared":
+			return nil, &ipc.Error{Name: "org.hamba.avro.PongError", Value: PongError{Reason: "test"}}
+		case "string":
+			return nil, errors.New("test error")
+		}
+
+		for k, v := range req.Meta {
+			req.ResponseMeta[k] = v
+		}
+		return Pong{Timestamp: r.Ping.Timestamp + 1, Ping: r.Ping}, nil
+	})
+	require.NoError(t, err)
+
+	err = srv.Handle("notify", func(ctx context.Context, req *ipc.Request) (interface{}, error) {
+		var r NotifyRequest
+		if err := req.Decode(&r); err != nil {
+			return nil, err
+		}
+
+		notified <- r.Text
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	return srv
+}
+
+func newHTTPClient(t *testing.T, notified chan string) *ipc.Client {
+	t.Helper()
+
+	s := httptest.NewServer(newServer(t, notified))
+	t.Cleanup(s.Close)
+
+	return ipc.NewClient(avro.MustParseProtocol(protocol), ipc.NewHTTPTransport(s.URL))
+}
+
+func newConnClient(t *testing.T, notified chan string) *ipc.Client {
+	t.Helper()
+
+	srv := newServer(t, notified)
+	c, s := net.Pipe()
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = s.Close()
+	})
+
+	go func() { _ = srv.ServeConn(context.Background(), s) }()
+
+	return ipc.NewClient(avro.MustParseProtocol(protocol), ipc.NewConnTransport(c))
+}
+
+func TestServer_HandleUnknownMessage(t *testing.T) {
+	srv := ipc.NewServer(avro.MustParseProtocol(protocol))
+
+	err := srv.Handle("foo", nil)
+
+	assert.Error(t, err)
+}
+
+func TestClient(t *testing.T) {
+	clients := map[string]func(*testing.T, chan string) *ipc.Client{
+		"http": newHTTPClient,
+		"conn": newConnClient,
+	}
+
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			notified := make(chan string, 1)
+			client := newClient(t, notified)
+			ctx := context.Background()
+
+			t.Run("Call", func(t *testing.T) {
+				var got Pong
+				err := client.Call(ctx, "ping", PingRequest{Ping: Ping{Timestamp: 1, Text: "hello"}}, &got)
+
+				require.NoError(t, err)
+				assert.Equal(t, Pong{Timestamp: 2, Ping: Ping{Timestamp: 1, Text: "hello"}}, got)
+			})
+
+			t.Run("LargeRequest", func(t *testing.T) {
+				text := strings.Repeat("a", 20000)
+
+				var got Pong
+				err := client.Call(ctx, "ping", PingRequest{Ping: Ping{Text: text}}, &got)
+
+				require.NoError(t, err)
+				assert.Equal(t, text, got.Ping.Text)
+			})
+
+			t.Run("Meta", func(t *testing.T) {
+				call := &ipc.Call{
+					Message: "ping",
+					Meta:    map[string][]byte{"foo": []byte("bar")},
+					Request: PingRequest{},
+				}
+
+				err := client.Do(ctx, call)
+
+				require.NoError(t, err)
+				assert.Equal(t, map[string][]byte{"foo": []byte("bar")}, call.ResponseMeta)
+			})
+
+			t.Run("DeclaredError", func(t *testing.T) {
+				err := client.Call(ctx, "ping", PingRequest{Ping: Ping{Text: "declared"}}, nil)
+
+				var e *ipc.Error
+				require.True(t, errors.As(err, &e))
+				assert.Equal(t, "org.hamba.avro.PongError", e.Name)
+				assert.Equal(t, map[string]interface{}{"reason": "test"}, e.Value)
+			})
+
+			t.Run("DeclaredErrorWithValue", func(t *testing.T) {
+				got := &PongError{}
+				call := &ipc.Call{
+					Message: "ping",
+					Request: PingRequest{Ping: Ping{Text: "declared"}},
+					Errors:  map[string]interface{}{"org.hamba.avro.PongError": got},
+				}
+
+				err := client.Do(ctx, call)
+
+				var e *ipc.Error
+				require.True(t, errors.As(err, &e))
+				assert.Same(t, got, e.Value)
+				assert.Equal(t, "test", got.Reason)
+			})
+
+			t.Run("StringError", func(t *testing.T) {
+				err := client.Call(ctx, "ping", PingRequest{Ping: Ping{Text: "string"}}, nil)
+
+				var e *ipc.Error
+				require.True(t, errors.As(err, &e))
+				assert.Equal(t, "string", e.Name)
+				assert.Equal(t, "test error", e.Error())
+			})
+
+			t.Run("OneWay", func(t *testing.T) {
+				err := client.Call(ctx, "notify", NotifyRequest{Text: "hello"}, nil)
+
+				require.NoError(t, err)
+				select {
+				case got := <-notified:
+					assert.Equal(t, "hello", got)
+				case <-time.After(time.Second):
+					t.Fatal("one-way message was not received")
+				}
+			})
+
+			t.Run("UnknownMessage", func(t *testing.T) {
+				err := client.Call(ctx, "foo", nil, nil)
+
+				assert.Error(t, err)
+			})
+
+			t.Run("InvalidRequest", func(t *testing.T) {
+				err := client.Call(ctx, "ping", "test", nil)
+
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestClient_DifferentClientProtocol(t *testing.T) {
+	s := httptest.NewServer(newServer(t, nil))
+	defer s.Close()
+
+	// The client protocol has a message the server does not know.
+	clientProto := avro.MustParseProtocol(strings.Replace(protocol, `"messages": {`,
+		`"messages": {"other": {"request": [], "response": "string"},`, 1))
+	client := ipc.NewClient(clientProto, ipc.NewHTTPTransport(s.URL))
+
+	for i := 0; i < 2; i++ {
+		var got Pong
+		err := client.Call(context.Background(), "ping", PingRequest{Ping: Ping{Timestamp: 1}}, &got)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got.Timestamp)
+	}
+
+	err := client.Call(context.Background(), "other", struct{}{}, nil)
+
+	var e *ipc.Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "string", e.Name)
+}
+
+func TestHTTPTransport_ServerError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+
+	client := ipc.NewClient(avro.MustParseProtocol(protocol), ipc.NewHTTPTransport(s.URL))
+
+	err := client.Call(context.Background(), "ping", PingRequest{}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestServer_ServeHTTPRejectsGet(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	newServer(t, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServer_Serve(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	go func() { _ = newServer(t, nil).Serve(context.Background(), l) }()
+
+	transport, err := ipc.Dial(context.Background(), "tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = transport.Close() }()
+	client := ipc.NewClient(avro.MustParseProtocol(protocol), transport)
+
+	var got Pong
+	err = client.Call(context.Background(), "ping", PingRequest{Ping: Ping{Timestamp: 41}}, &got)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), got.Timestamp)
+}
@@ -0,0 +1,306 @@
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	avro "github.com/xl4hub/hamba-avro"
+)
+
+// Request is a call received by a Server.
+type Request struct {
+	// Message is the name of the called message.
+	Message string
+	// Meta is the call metadata sent by the client.
+	Meta map[string][]byte
+	// ResponseMeta is the metadata sent back to the client. Handlers may add to it.
+	ResponseMeta map[string][]byte
+
+	schema avro.Schema
+	data   []byte
+}
+
+// Decode decodes the message request parameters into v.
+//
+// The parameters are decoded as a record with a field per parameter.
+func (r *Request) Decode(v interface{}) error {
+	return avro.Unmarshal(r.schema, r.data, v)
+}
+
+// Handler handles a call to a message.
+//
+// The returned value is sent as the message response. Handlers of one-way
+// messages should return nil, their result is not sent to the client.
+type Handler func(ctx context.Context, req *Request) (interface{}, error)
+
+// Server serves calls to the messages of a protocol.
+type Server struct {
+	proto *avro.Protocol
+	hash  [16]byte
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	clients  map[[16]byte]*avro.Protocol
+}
+
+// NewServer returns a server for the given protocol.
+func NewServer(proto *avro.Protocol) *Server {
+	hash := hashBytes(proto)
+
+	return &Server{
+		proto:    proto,
+		hash:     hash,
+		handlers: map[string]Handler{},
+		clients:  map[[16]byte]*avro.Protocol{hash: proto},
+	}
+}
+
+// Handle registers the handler for the named message.
+func (s *Server) Handle(message string, h Handler) error {
+	if s.proto.Message(message) == nil {
+		return fmt.Errorf("ipc: unknown message %q", message)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[message] = h
+	return nil
+}
+
+// ServeHTTP serves a call sent with HTTP.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := readFrames(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	resp, _, err := s.handle(r.Context(), req, true)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_ = writeFrames(w, resp)
+}
+
+// Serve accepts connections on l and serves each connection on its own goroutine.
+// Serve returns when l fails to accept a connection.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer func() { _ = conn.Close() }()
+
+			_ = s.ServeConn(ctx, conn)
+		}()
+	}
+}
+
+// ServeConn serves framed calls on a stream connection until the connection is closed.
+//
+// The first call on the connection must contain a handshake.
+func (s *Server) ServeConn(ctx context.Context, conn io.ReadWriter) error {
+	handshake := true
+	for {
+		req, err := readFrames(conn)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		resp, ok, err := s.handle(ctx, req, handshake)
+		if err != nil {
+			return err
+		}
+		if handshake && ok {
+			handshake = false
+		}
+
+		if resp == nil {
+			continue
+		}
+		if err = writeFrames(conn, resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request, returning the response to send, if any, and if the handshake succeeded.
+func (s *Server) handle(ctx context.Context, b []byte, handshake bool) ([]byte, bool, error) {
+	r := avro.NewReader(nil, 0).Reset(b)
+	w := avro.NewWriter(nil, 512)
+
+	client := s.proto
+	if handshake {
+		var hr HandshakeRequest
+		r.ReadVal(HandshakeRequestSchema, &hr)
+		if r.Error != nil {
+			return nil, false, r.Error
+		}
+
+		var resp HandshakeResponse
+		client, resp = s.handshake(hr)
+		w.WriteVal(HandshakeResponseSchema, resp)
+		if client == nil {
+			return w.Buffer(), false, w.Error
+		}
+	}
+
+	meta := map[string][]byte{}
+	r.ReadVal(metaSchema, &meta)
+	name := r.ReadString()
+	if r.Error != nil {
+		return nil, false, r.Error
+	}
+
+	if name == "" {
+		// A call without a message only performs the handshake.
+		if !handshake {
+			return nil, true, nil
+		}
+		return w.Buffer(), true, w.Error
+	}
+
+	msg := client.Message(name)
+	if msg == nil {
+		return nil, false, fmt.Errorf("ipc: unknown message %q", name)
+	}
+
+	req := &Request{
+		Message:      name,
+		Meta:         meta,
+		ResponseMeta: map[string][]byte{},
+		schema:       msg.Request(),
+		data:         b[r.InputOffset():],
+	}
+	v, err := s.call(ctx, req)
+
+	if msg.OneWay() {
+		if !handshake {
+			return nil, true, nil
+		}
+		return w.Buffer(), true, w.Error
+	}
+
+	w.WriteVal(metaSchema, req.ResponseMeta)
+	s.writeResult(w, s.proto.Message(name), v, err)
+	return w.Buffer(), true, w.Error
+}
+
+// handshake returns the protocol of the client and the response to the handshake.
+// The client protocol is nil if it is not known.
+func (s *Server) handshake(hr HandshakeRequest) (*avro.Protocol, HandshakeResponse) {
+	s.mu.Lock()
+	client, ok := s.clients[hr.ClientHash]
+	if !ok && hr.ClientProtocol != nil {
+		if proto, err := avro.ParseProtocol(*hr.ClientProtocol); err == nil {
+			client = proto
+			s.clients[hr.ClientHash] = proto
+		}
+	}
+	s.mu.Unlock()
+
+	resp := HandshakeResponse{Match: MatchBoth}
+	switch {
+	case client == nil:
+		resp.Match = MatchNone
+	case hr.ServerHash != s.hash:
+		resp.Match = MatchClient
+	}
+
+	if resp.Match != MatchBoth {
+		proto := s.proto.String()
+		hash := s.hash
+		resp.ServerProtocol = &proto
+		resp.ServerHash = &hash
+	}
+
+	return client, resp
+}
+
+func (s *Server) call(ctx context.Context, req *Request) (interface{}, error) {
+	s.mu.RLock()
+	h, ok := s.handlers[req.Message]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("ipc: no handler for message %q", req.Message)
+	}
+
+	return h(ctx, req)
+}
+
+// writeResult writes the response or error of a call.
+func (s *Server) writeResult(w *avro.Writer, msg *avro.Message, v interface{}, err error) {
+	if err == nil && msg == nil {
+		err = errors.New("ipc: unknown message")
+	}
+
+	if err == nil {
+		resp := avro.NewWriter(nil, 512)
+		if msg.Response() != nil {
+			resp.WriteVal(msg.Response(), v)
+		}
+		if resp.Error == nil {
+			w.WriteBool(false)
+			w.Write(resp.Buffer())
+			return
+		}
+		err = fmt.Errorf("ipc: invalid response: %w", resp.Error)
+	}
+
+	w.WriteBool(true)
+
+	var e *Error
+	if errors.As(err, &e) && msg != nil {
+		for i, typ := range msg.Errors().Types() {
+			if i == 0 || schemaName(typ) != e.Name {
+				continue
+			}
+
+			val := avro.NewWriter(nil, 512)
+			val.WriteVal(typ, e.Value)
+			if val.Error != nil {
+				break
+			}
+
+			w.WriteLong(int64(i))
+			w.Write(val.Buffer())
+			return
+		}
+	}
+
+	// Errors that are not declared by the message are sent as string errors.
+	w.WriteLong(0)
+	w.WriteString(err.Error())
+}
+
+// schemaName returns the full name of a named schema, or the type of other schemas.
+func schemaName(schema avro.Schema) string {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		schema = ref.Schema()
+	}
+	if n, ok := schema.(avro.NamedSchema); ok {
+		return n.FullName()
+	}
+	return string(schema.Type())
+}
@@ -0,0 +1,142 @@
+package ipc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// contentType is the content type of Avro RPC sent with HTTP.
+const contentType = "avro/binary"
+
+// Transport sends requests to a server.
+type Transport interface {
+	// RoundTrip sends the request and returns the response. Stateful transports
+	// do not wait for a response to one-way requests and return nil.
+	RoundTrip(ctx context.Context, req []byte, oneWay bool) ([]byte, error)
+
+	// Stateful returns true if a handshake is only needed once for all requests
+	// sent with the transport.
+	Stateful() bool
+}
+
+// HTTPTransportFunc represents an configuration function for HTTPTransport.
+type HTTPTransportFunc func(*HTTPTransport)
+
+// WithHTTPClient sets the http client used to send requests.
+func WithHTTPClient(client *http.Client) HTTPTransportFunc {
+	return func(t *HTTPTransport) {
+		t.client = client
+	}
+}
+
+// HTTPTransport sends each request as an HTTP POST request.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPTransport returns a transport that sends requests to the given url.
+func NewHTTPTransport(url string, opts ...HTTPTransportFunc) *HTTPTransport {
+	t := &HTTPTransport{
+		url:    url,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// RoundTrip sends the request and returns the response.
+func (t *HTTPTransport) RoundTrip(ctx context.Context, req []byte, _ bool) ([]byte, error) {
+	body := &bytes.Buffer{}
+	if err := writeFrames(body, req); err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", contentType)
+
+	resp, err := t.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ipc: unexpected status code %d", resp.StatusCode)
+	}
+
+	return readFrames(resp.Body)
+}
+
+// Stateful returns false, as each HTTP request performs a handshake.
+func (t *HTTPTransport) Stateful() bool {
+	return false
+}
+
+// ConnTransport sends framed requests over a stream connection.
+//
+// Requests are sent one at a time.
+type ConnTransport struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewConnTransport returns a transport that sends requests over conn.
+func NewConnTransport(conn net.Conn) *ConnTransport {
+	return &ConnTransport{conn: conn}
+}
+
+// Dial connects to the address on the named network and returns a transport for the connection.
+func Dial(ctx context.Context, network, address string) (*ConnTransport, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnTransport(conn), nil
+}
+
+// RoundTrip sends the request and returns the response.
+func (t *ConnTransport) RoundTrip(ctx context.Context, req []byte, oneWay bool) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := t.conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+		defer func() { _ = t.conn.SetDeadline(time.Time{}) }()
+	}
+
+	if err := writeFrames(t.conn, req); err != nil {
+		return nil, err
+	}
+	if oneWay {
+		return nil, nil
+	}
+
+	return readFrames(t.conn)
+}
+
+// Stateful returns true, as the handshake is performed once per connection.
+func (t *ConnTransport) Stateful() bool {
+	return true
+}
+
+// Close closes the connection.
+func (t *ConnTransport) Close() error {
+	return t.conn.Close()
+}
@@ -0,0 +1,136 @@
+/*
+Package lint checks Avro schemas against naming and style conventions.
+
+Issues are reported with the path of the offending schema or field. A path starts
+with the full name or type of the root schema, followed by ".name" for record fields,
+"[]" for array items, "{}" for map values and "<name>" for union types.
+*/
+package lint
+
+import (
+	"github.com/xl4hub/hamba-avro"
+)
+
+// Issue is a violation of a rule.
+type Issue struct {
+	// Rule is the name of the violated rule.
+	Rule string `json:"rule"`
+
+	// Path is the path of the schema or field violating the rule.
+	Path string `json:"path"`
+
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// String returns a human-readable description of the issue.
+func (i Issue) String() string {
+	return i.Path + ": " + i.Message + " (" + i.Rule + ")"
+}
+
+// Node is a schema or record field checked by a rule.
+type Node struct {
+	// Path is the path of the schema or field.
+	Path string
+
+	// Schema is the schema, or the type of the field. References are resolved.
+	Schema avro.Schema
+
+	// Record and Field are set if the node is a record field.
+	Record *avro.RecordSchema
+	Field  *avro.Field
+}
+
+// Rule is a lint rule.
+type Rule interface {
+	// Name returns the name of the rule.
+	Name() string
+
+	// Check returns a message for each violation of the rule by the node.
+	Check(node Node) []string
+}
+
+// Linter checks schemas against a set of rules.
+type Linter struct {
+	rules []Rule
+}
+
+// New returns a linter checking the given rules.
+func New(rules ...Rule) *Linter {
+	return &Linter{rules: rules}
+}
+
+// Lint checks the schema and all schemas and fields it contains, returning the issues found.
+func (l *Linter) Lint(schema avro.Schema) []Issue {
+	v := &visitor{rules: l.rules, paths: map[avro.Schema]string{deref(schema): typeName(schema)}}
+	_ = avro.Walk(schema, v)
+	return v.issues
+}
+
+// visitor checks the nodes visited by avro.Walk. The path of a schema is recorded
+// by its parent before the schema is visited.
+type visitor struct {
+	rules  []Rule
+	paths  map[avro.Schema]string
+	issues []Issue
+}
+
+func (v *visitor) Schema(schema avro.Schema) error {
+	path := v.paths[schema]
+	v.check(Node{Path: path, Schema: schema})
+
+	switch s := schema.(type) {
+	case *avro.ArraySchema:
+		v.paths[deref(s.Items())] = path + "[]"
+
+	case *avro.MapSchema:
+		v.paths[deref(s.Values())] = path + "{}"
+
+	case *avro.UnionSchema:
+		for _, typ := range s.Types() {
+			v.paths[deref(typ)] = path + "<" + typeName(typ) + ">"
+		}
+	}
+	return nil
+}
+
+func (v *visitor) Field(record *avro.RecordSchema, field *avro.Field) error {
+	path := v.paths[record] + "." + field.Name()
+	v.check(Node{Path: path, Schema: deref(field.Type()), Record: record, Field: field})
+
+	v.paths[deref(field.Type())] = path
+	return nil
+}
+
+func (v *visitor) check(node Node) {
+	for _, rule := range v.rules {
+		for _, msg := range rule.Check(node) {
+			v.issues = append(v.issues, Issue{Rule: rule.Name(), Path: node.Path, Message: msg})
+		}
+	}
+}
+
+func deref(schema avro.Schema) avro.Schema {
+	if ref, ok := schema.(*avro.RefSchema); ok {
+		return ref.Schema()
+	}
+	return schema
+}
+
+// typeName returns the full name of a named schema, otherwise its type and logical type.
+func typeName(schema avro.Schema) string {
+	schema = deref(schema)
+	if named, ok := schema.(avro.NamedSchema); ok {
+		return named.FullName()
+	}
+
+	name := string(schema.Type())
+	if l, ok := schema.(avro.LogicalTypeSchema); ok && l.Logical() != nil {
+		name += "." + string(l.Logical().Type())
+	}
+	return name
+}
+
+func isNull(schema avro.Schema) bool {
+	return schema.Type() == avro.Null
+}
@@ -0,0 +1,180 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/lint"
+)
+
+func mustParse(t *testing.T, schema string) avro.Schema {
+	t.Helper()
+
+	s, err := avro.ParseWithCache(schema, "", &avro.SchemaCache{})
+	require.NoError(t, err)
+	return s
+}
+
+func TestLinter_Lint(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   lint.Rule
+		schema string
+		want   []lint.Issue
+	}{
+		{
+			name:   "Type Name Style",
+			rule:   lint.TypeNameStyle{Style: lint.PascalCase},
+			schema: `{"type":"record","name":"org.hamba.my_record","fields":[{"name":"a","type":{"type":"fixed","name":"Hash","size":16}}]}`,
+			want:   []lint.Issue{{Rule: "type-name-style", Path: "org.hamba.my_record", Message: `type name "my_record" is not PascalCase`}},
+		},
+		{
+			name:   "Field Name Style",
+			rule:   lint.FieldNameStyle{Style: lint.SnakeCase},
+			schema: `{"type":"record","name":"Test","fields":[{"name":"first_name","type":"string"},{"name":"lastName","type":"string"}]}`,
+			want:   []lint.Issue{{Rule: "field-name-style", Path: "Test.lastName", Message: `field name "lastName" is not snake_case`}},
+		},
+		{
+			name:   "Symbol Style",
+			rule:   lint.SymbolStyle{Style: lint.UpperSnakeCase},
+			schema: `{"type":"array","items":{"type":"enum","name":"Status","symbols":["ACTIVE","onHold"]}}`,
+			want:   []lint.Issue{{Rule: "symbol-style", Path: "array[]", Message: `symbol "onHold" is not UPPER_SNAKE_CASE`}},
+		},
+		{
+			name:   "Missing Doc",
+			rule:   lint.MissingDoc{Types: true, Fields: true},
+			schema: `{"type":"record","name":"Test","fields":[{"name":"a","type":"int","doc":"A."},{"name":"b","type":"int"}]}`,
+			want: []lint.Issue{
+				{Rule: "missing-doc", Path: "Test", Message: "type has no doc"},
+				{Rule: "missing-doc", Path: "Test.b", Message: "field has no doc"},
+			},
+		},
+		{
+			name:   "Missing Doc Types Only",
+			rule:   lint.MissingDoc{Types: true},
+			schema: `{"type":"record","name":"Test","doc":"Test.","fields":[{"name":"a","type":"int"}]}`,
+		},
+		{
+			name: "Nullable Default",
+			rule: lint.NullableDefault{},
+			schema: `{"type":"record","name":"Test","fields":[
+				{"name":"a","type":["null","int"],"default":null},
+				{"name":"b","type":["null","int"]},
+				{"name":"c","type":["int","null"],"default":1},
+				{"name":"d","type":["null","int","string"]}
+			]}`,
+			want: []lint.Issue{
+				{Rule: "nullable-default", Path: "Test.b", Message: "nullable field does not default to null"},
+				{Rule: "nullable-default", Path: "Test.c", Message: "nullable field does not default to null"},
+			},
+		},
+		{
+			name:   "Null First",
+			rule:   lint.NullFirst{},
+			schema: `{"type":"map","values":{"type":"array","items":["int","null"]}}`,
+			want:   []lint.Issue{{Rule: "null-first", Path: "map{}[]", Message: "null is not the first type of the optional union"}},
+		},
+		{
+			name:   "Decimal Scale",
+			rule:   lint.DecimalScale{},
+			schema: `["null",{"type":"bytes","logicalType":"decimal","precision":4},{"type":"fixed","name":"Amount","size":8,"logicalType":"decimal","precision":10,"scale":2}]`,
+			want:   []lint.Issue{{Rule: "decimal-scale", Path: "union<bytes.decimal>", Message: "decimal has no scale"}},
+		},
+		{
+			name: "Deprecated Types",
+			rule: lint.DeprecatedTypes{Types: map[string]string{
+				"org.hamba.Old":         "use org.hamba.New",
+				"long.timestamp-micros": "",
+			}},
+			schema: `{"type":"record","name":"org.hamba.Test","fields":[
+				{"name":"a","type":{"type":"enum","name":"Old","symbols":["A"]}},
+				{"name":"b","type":{"type":"long","logicalType":"timestamp-micros"}}
+			]}`,
+			want: []lint.Issue{
+				{Rule: "deprecated-type", Path: "org.hamba.Test.a", Message: "type org.hamba.Old is deprecated: use org.hamba.New"},
+				{Rule: "deprecated-type", Path: "org.hamba.Test.b", Message: "type long.timestamp-micros is deprecated"},
+			},
+		},
+		{
+			name:   "Enum Size",
+			rule:   lint.EnumSize{Max: 2},
+			schema: `{"type":"enum","name":"Test","symbols":["A","B","C"]}`,
+			want:   []lint.Issue{{Rule: "enum-size", Path: "Test", Message: "enum has 3 symbols, more than 2"}},
+		},
+		{
+			name:   "Go Keywords",
+			rule:   lint.GoKeywords{},
+			schema: `{"type":"record","name":"Test","fields":[{"name":"type","type":{"type":"enum","name":"Kind","symbols":["go","A"]}}]}`,
+			want: []lint.Issue{
+				{Rule: "go-keyword", Path: "Test.type", Message: `field name "type" is a Go keyword`},
+				{Rule: "go-keyword", Path: "Test.type", Message: `symbol "go" is a Go keyword`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema := mustParse(t, test.schema)
+
+			got := lint.New(test.rule).Lint(schema)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestLinter_LintRecursiveSchema(t *testing.T) {
+	schema := mustParse(t, `{"type":"record","name":"Node","fields":[{"name":"Next","type":["null","Node"],"default":null}]}`)
+
+	got := lint.New(lint.FieldNameStyle{Style: lint.CamelCase}).Lint(schema)
+
+	assert.Equal(t, []lint.Issue{{Rule: "field-name-style", Path: "Node.Next", Message: `field name "Next" is not camelCase`}}, got)
+}
+
+func TestLinter_LintDefaultRules(t *testing.T) {
+	schema := mustParse(t, `{
+		"type":"record","name":"org.hamba.User","doc":"A user.",
+		"fields":[
+			{"name":"id","type":"long","doc":"The id."},
+			{"name":"status","type":["null",{"type":"enum","name":"Status","doc":"The status.","symbols":["ACTIVE","INACTIVE"]}],"default":null,"doc":"The status."}
+		]
+	}`)
+
+	got := lint.New(lint.DefaultRules()...).Lint(schema)
+
+	assert.Empty(t, got)
+}
+
+func TestIssue_String(t *testing.T) {
+	issue := lint.Issue{Rule: "missing-doc", Path: "Test.a", Message: "field has no doc"}
+
+	assert.Equal(t, "Test.a: field has no doc (missing-doc)", issue.String())
+}
+
+func TestStyle_Match(t *testing.T) {
+	tests := []struct {
+		style lint.Style
+		name  string
+		want  bool
+	}{
+		{style: lint.PascalCase, name: "UserId", want: true},
+		{style: lint.PascalCase, name: "userId", want: false},
+		{style: lint.CamelCase, name: "userId", want: true},
+		{style: lint.CamelCase, name: "user_id", want: false},
+		{style: lint.SnakeCase, name: "user_id", want: true},
+		{style: lint.SnakeCase, name: "user__id", want: false},
+		{style: lint.UpperSnakeCase, name: "USER_ID", want: true},
+		{style: lint.UpperSnakeCase, name: "User_ID", want: false},
+		{style: lint.Style("unknown"), name: "anything", want: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.style)+" "+test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.style.Match(test.name))
+		})
+	}
+}
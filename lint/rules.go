@@ -0,0 +1,302 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// Style is a naming style.
+type Style string
+
+// Naming styles.
+const (
+	PascalCase     Style = "PascalCase"
+	CamelCase      Style = "camelCase"
+	SnakeCase      Style = "snake_case"
+	UpperSnakeCase Style = "UPPER_SNAKE_CASE"
+)
+
+var styles = map[Style]*regexp.Regexp{
+	PascalCase:     regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	CamelCase:      regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	SnakeCase:      regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	UpperSnakeCase: regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`),
+}
+
+// Match determines if the name is in the style. Unknown styles match any name.
+func (s Style) Match(name string) bool {
+	re, ok := styles[s]
+	if !ok {
+		return true
+	}
+	return re.MatchString(name)
+}
+
+// DefaultRules returns the rules with their default configuration. DeprecatedTypes
+// is not included, as it has no deprecated types by default.
+func DefaultRules() []Rule {
+	return []Rule{
+		TypeNameStyle{Style: PascalCase},
+		FieldNameStyle{Style: CamelCase},
+		SymbolStyle{Style: UpperSnakeCase},
+		MissingDoc{Types: true, Fields: true},
+		NullableDefault{},
+		NullFirst{},
+		DecimalScale{},
+		EnumSize{Max: 100},
+		GoKeywords{},
+	}
+}
+
+// TypeNameStyle checks that the names of records, enums and fixed schemas are in the style.
+type TypeNameStyle struct {
+	Style Style
+}
+
+// Name returns the name of the rule.
+func (TypeNameStyle) Name() string {
+	return "type-name-style"
+}
+
+// Check checks the node.
+func (r TypeNameStyle) Check(node Node) []string {
+	if node.Field != nil {
+		return nil
+	}
+
+	named, ok := node.Schema.(avro.NamedSchema)
+	if !ok || r.Style.Match(named.Name()) {
+		return nil
+	}
+	return []string{fmt.Sprintf("type name %q is not %s", named.Name(), r.Style)}
+}
+
+// FieldNameStyle checks that the names of record fields are in the style.
+type FieldNameStyle struct {
+	Style Style
+}
+
+// Name returns the name of the rule.
+func (FieldNameStyle) Name() string {
+	return "field-name-style"
+}
+
+// Check checks the node.
+func (r FieldNameStyle) Check(node Node) []string {
+	if node.Field == nil || r.Style.Match(node.Field.Name()) {
+		return nil
+	}
+	return []string{fmt.Sprintf("field name %q is not %s", node.Field.Name(), r.Style)}
+}
+
+// SymbolStyle checks that the symbols of enums are in the style.
+type SymbolStyle struct {
+	Style Style
+}
+
+// Name returns the name of the rule.
+func (SymbolStyle) Name() string {
+	return "symbol-style"
+}
+
+// Check checks the node.
+func (r SymbolStyle) Check(node Node) []string {
+	enum, ok := node.Schema.(*avro.EnumSchema)
+	if !ok || node.Field != nil {
+		return nil
+	}
+
+	var msgs []string
+	for _, sym := range enum.Symbols() {
+		if !r.Style.Match(sym) {
+			msgs = append(msgs, fmt.Sprintf("symbol %q is not %s", sym, r.Style))
+		}
+	}
+	return msgs
+}
+
+// MissingDoc checks that named types and record fields are documented.
+type MissingDoc struct {
+	// Types enables the check for records, enums and fixed schemas.
+	Types bool
+
+	// Fields enables the check for record fields.
+	Fields bool
+}
+
+// Name returns the name of the rule.
+func (MissingDoc) Name() string {
+	return "missing-doc"
+}
+
+// Check checks the node.
+func (r MissingDoc) Check(node Node) []string {
+	if node.Field != nil {
+		if r.Fields && node.Field.Doc() == "" {
+			return []string{"field has no doc"}
+		}
+		return nil
+	}
+
+	doc, ok := node.Schema.(interface{ Doc() string })
+	if !ok || !r.Types || doc.Doc() != "" {
+		return nil
+	}
+	return []string{"type has no doc"}
+}
+
+// NullableDefault checks that fields with an optional union type, a union of null and
+// one other type, default to null.
+type NullableDefault struct{}
+
+// Name returns the name of the rule.
+func (NullableDefault) Name() string {
+	return "nullable-default"
+}
+
+// Check checks the node.
+func (NullableDefault) Check(node Node) []string {
+	if node.Field == nil {
+		return nil
+	}
+
+	union, ok := node.Schema.(*avro.UnionSchema)
+	if !ok || !union.Nullable() {
+		return nil
+	}
+
+	if node.Field.HasDefault() && node.Field.Default() == nil {
+		return nil
+	}
+	return []string{"nullable field does not default to null"}
+}
+
+// NullFirst checks that null is the first type of optional unions.
+type NullFirst struct{}
+
+// Name returns the name of the rule.
+func (NullFirst) Name() string {
+	return "null-first"
+}
+
+// Check checks the node.
+func (NullFirst) Check(node Node) []string {
+	union, ok := node.Schema.(*avro.UnionSchema)
+	if !ok || node.Field != nil || !union.Nullable() || isNull(union.Types()[0]) {
+		return nil
+	}
+	return []string{"null is not the first type of the optional union"}
+}
+
+// DecimalScale checks that decimals have a scale.
+type DecimalScale struct{}
+
+// Name returns the name of the rule.
+func (DecimalScale) Name() string {
+	return "decimal-scale"
+}
+
+// Check checks the node.
+func (DecimalScale) Check(node Node) []string {
+	l, ok := node.Schema.(avro.LogicalTypeSchema)
+	if !ok || node.Field != nil {
+		return nil
+	}
+
+	dec, ok := l.Logical().(*avro.DecimalLogicalSchema)
+	if !ok || dec.Scale() > 0 {
+		return nil
+	}
+	return []string{"decimal has no scale"}
+}
+
+// DeprecatedTypes checks that deprecated types are not used.
+type DeprecatedTypes struct {
+	// Types maps the deprecated types to the reason they are deprecated. A type is the full
+	// name of a named type, or a type with its logical type, such as "long.timestamp-micros".
+	Types map[string]string
+}
+
+// Name returns the name of the rule.
+func (DeprecatedTypes) Name() string {
+	return "deprecated-type"
+}
+
+// Check checks the node.
+func (r DeprecatedTypes) Check(node Node) []string {
+	if node.Field != nil {
+		return nil
+	}
+
+	name := typeName(node.Schema)
+	reason, ok := r.Types[name]
+	if !ok {
+		return nil
+	}
+
+	msg := fmt.Sprintf("type %s is deprecated", name)
+	if reason != "" {
+		msg += ": " + reason
+	}
+	return []string{msg}
+}
+
+// EnumSize checks that enums have at most Max symbols.
+type EnumSize struct {
+	Max int
+}
+
+// Name returns the name of the rule.
+func (EnumSize) Name() string {
+	return "enum-size"
+}
+
+// Check checks the node.
+func (r EnumSize) Check(node Node) []string {
+	enum, ok := node.Schema.(*avro.EnumSchema)
+	if !ok || node.Field != nil || len(enum.Symbols()) <= r.Max {
+		return nil
+	}
+	return []string{fmt.Sprintf("enum has %d symbols, more than %d", len(enum.Symbols()), r.Max)}
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// GoKeywords checks that the names of named types, fields and enum symbols are not Go keywords.
+type GoKeywords struct{}
+
+// Name returns the name of the rule.
+func (GoKeywords) Name() string {
+	return "go-keyword"
+}
+
+// Check checks the node.
+func (GoKeywords) Check(node Node) []string {
+	if node.Field != nil {
+		if goKeywords[node.Field.Name()] {
+			return []string{fmt.Sprintf("field name %q is a Go keyword", node.Field.Name())}
+		}
+		return nil
+	}
+
+	var msgs []string
+	if named, ok := node.Schema.(avro.NamedSchema); ok && goKeywords[named.Name()] {
+		msgs = append(msgs, fmt.Sprintf("type name %q is a Go keyword", named.Name()))
+	}
+	if enum, ok := node.Schema.(*avro.EnumSchema); ok {
+		for _, sym := range enum.Symbols() {
+			if goKeywords[sym] {
+				msgs = append(msgs, fmt.Sprintf("symbol %q is a Go keyword", sym))
+			}
+		}
+	}
+	return msgs
+}
@@ -12,7 +12,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
+	jsoniter "github.com/json-iterator/go"
 	avro "github.com/xl4hub/hamba-avro"
 	"github.com/xl4hub/hamba-avro/internal/bytesx"
 )
@@ -42,21 +45,93 @@ type Header struct {
 	Sync  [16]byte          `avro:"sync"`
 }
 
+type decoderConfig struct {
+	Concurrency int
+	Recover     bool
+	OnSkip      func(SkippedRegion)
+}
+
+// DecoderFunc represents an configuration function for Decoder.
+type DecoderFunc func(cfg *decoderConfig)
+
+// WithDecoderConcurrency sets the number of blocks that are read ahead and
+// decompressed concurrently by the decoder. Blocks are still returned in order,
+// and records are decoded by the caller.
+//
+// It is the decoder counterpart of WithConcurrency, named apart as the Decoder
+// and Encoder options are distinct types.
+func WithDecoderConcurrency(n int) DecoderFunc {
+	return func(cfg *decoderConfig) {
+		cfg.Concurrency = n
+	}
+}
+
+// WithRecovery enables recovery from corrupt blocks on the decoder.
+//
+// Instead of stopping at the first invalid block, the decoder skips to
+// the next sync marker and continues with the following block. Each skipped
+// region is passed to fn, if it is not nil, and is available from Decoder.Skipped.
+func WithRecovery(fn func(SkippedRegion)) DecoderFunc {
+	return func(cfg *decoderConfig) {
+		cfg.Recover = true
+		cfg.OnSkip = fn
+	}
+}
+
+// SkippedRegion is a region of a container file that could not be decoded.
+type SkippedRegion struct {
+	// Offset is the byte offset of the start of the region.
+	Offset int64
+	// Length is the number of bytes skipped.
+	Length int64
+	// Err is the reason the region was skipped.
+	Err error
+}
+
+// block is a container file data block.
+type block struct {
+	offset int64
+	length int64
+
+	count int64
+	size  int
+	data  []byte
+	err   error
+
+	// done is closed once data has been (de)compressed.
+	done chan struct{}
+}
+
 // Decoder reads and decodes Avro values from a container file.
 type Decoder struct {
 	reader      *avro.Reader
 	resetReader *bytesx.ResetReader
 	decoder     *avro.Decoder
+	schema      avro.Schema
 	meta        map[string][]byte
 	sync        [16]byte
 
 	codec Codec
 
+	concurrency int
+	pending     []*block
+	exhausted   bool
+
+	recover bool
+	onSkip  func(SkippedRegion)
+	skipped []SkippedRegion
+
 	count int64
+	err   error
 }
 
 // NewDecoder returns a new decoder that reads from reader r.
-func NewDecoder(r io.Reader) (*Decoder, error) {
+func NewDecoder(r io.Reader, opts ...DecoderFunc) (*Decoder, error) {
+	var cfg decoderConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	reader := avro.NewReader(r, 1024)
 
 	var h Header
@@ -84,9 +159,13 @@ func NewDecoder(r io.Reader) (*Decoder, error) {
 		reader:      reader,
 		resetReader: decReader,
 		decoder:     avro.NewDecoderForSchema(schema, decReader),
+		schema:      schema,
 		meta:        h.Meta,
 		sync:        h.Sync,
 		codec:       codec,
+		concurrency: cfg.Concurrency,
+		recover:     cfg.Recover,
+		onSkip:      cfg.OnSkip,
 	}, nil
 }
 
@@ -98,11 +177,11 @@ func (d *Decoder) Metadata() map[string][]byte {
 // HasNext determines if there is another value to read.
 func (d *Decoder) HasNext() bool {
 	if d.count <= 0 {
-		count := d.readBlock()
+		count := d.nextBlock()
 		d.count = count
 	}
 
-	if d.reader.Error != nil {
+	if d.err != nil {
 		return false
 	}
 
@@ -122,42 +201,240 @@ func (d *Decoder) Decode(v interface{}) error {
 
 // Error returns the last reader error.
 func (d *Decoder) Error() error {
-	if errors.Is(d.reader.Error, io.EOF) {
+	if errors.Is(d.err, io.EOF) {
 		return nil
 	}
 
-	return d.reader.Error
+	return d.err
+}
+
+// Skipped returns the regions skipped by a decoder in recovery mode.
+func (d *Decoder) Skipped() []SkippedRegion {
+	return d.skipped
+}
+
+func (d *Decoder) nextBlock() int64 {
+	blk := d.next()
+	if blk == nil {
+		return 0
+	}
+
+	d.err = blk.err
+	d.resetReader.Reset(blk.data)
+	return blk.count
+}
+
+// next returns the next decompressed block, or nil if there are no more blocks.
+func (d *Decoder) next() *block {
+	for {
+		var blk *block
+		if d.concurrency <= 1 {
+			blk = d.readBlock()
+			if blk.err == nil && blk.count > 0 {
+				blk.data, blk.err = d.codec.Decode(blk.data)
+			}
+		} else {
+			blk = d.nextPending()
+			if blk == nil {
+				return nil
+			}
+		}
+
+		if d.recover && blk.err != nil && !errors.Is(blk.err, io.EOF) &&
+			(d.reader.Error == nil || errors.Is(d.reader.Error, io.EOF)) {
+			d.skip(blk, blk.err)
+			continue
+		}
+
+		return blk
+	}
 }
 
-func (d *Decoder) readBlock() int64 {
+func (d *Decoder) nextPending() *block {
+	// Read ahead, decompressing each block on its own goroutine.
+	for len(d.pending) < d.concurrency && !d.exhausted {
+		blk := d.readBlock()
+		if blk.err != nil && (!d.recover || d.reader.Error != nil) {
+			d.exhausted = true
+		} else if blk.err == nil && blk.count > 0 {
+			blk.done = make(chan struct{})
+			go func(blk *block) {
+				defer close(blk.done)
+
+				blk.data, blk.err = d.codec.Decode(blk.data)
+			}(blk)
+		}
+
+		d.pending = append(d.pending, blk)
+	}
+
+	if len(d.pending) == 0 {
+		return nil
+	}
+
+	blk := d.pending[0]
+	d.pending[0] = nil
+	d.pending = d.pending[1:]
+
+	if blk.done != nil {
+		<-blk.done
+	}
+
+	return blk
+}
+
+func (d *Decoder) skip(blk *block, err error) {
+	region := SkippedRegion{Offset: blk.offset, Length: blk.length, Err: err}
+	d.skipped = append(d.skipped, region)
+
+	if d.onSkip != nil {
+		d.onSkip(region)
+	}
+}
+
+func (d *Decoder) readBlock() *block {
+	if d.recover {
+		return d.scanBlock()
+	}
+
+	offset := d.reader.InputOffset()
 	count := d.reader.ReadLong()
 	size := d.reader.ReadLong()
 
+	var data []byte
 	if count > 0 {
-		data := make([]byte, size)
-		d.reader.Read(data)
-
-		data, err := d.codec.Decode(data)
-		if err != nil {
-			d.reader.Error = err
+		if size < 0 {
+			return &block{offset: offset, err: errors.New("decoder: invalid block size")}
 		}
 
-		d.resetReader.Reset(data)
+		data = make([]byte, size)
+		d.reader.Read(data)
 	}
 
 	var sync [16]byte
 	d.reader.Read(sync[:])
+	length := d.reader.InputOffset() - offset
 	if d.sync != sync && !errors.Is(d.reader.Error, io.EOF) {
-		d.reader.Error = errors.New("decoder: invalid block")
+		return &block{offset: offset, length: length, err: errors.New("decoder: invalid block")}
+	}
+
+	return &block{offset: offset, length: length, count: count, data: data, err: d.reader.Error}
+}
+
+// scanBlock reads a block by scanning for the next sync marker, so that
+// a corrupt block header cannot cause following blocks to be lost.
+func (d *Decoder) scanBlock() *block {
+	offset := d.reader.InputOffset()
+
+	var buf []byte
+	var b [1]byte
+	for {
+		d.reader.Read(b[:])
+		if d.reader.Error != nil {
+			break
+		}
+
+		buf = append(buf, b[0])
+		if len(buf) >= len(d.sync) && bytes.Equal(buf[len(buf)-len(d.sync):], d.sync[:]) {
+			break
+		}
+	}
+	blk := &block{offset: offset, length: int64(len(buf))}
+
+	if d.reader.Error != nil {
+		if len(buf) > 0 && errors.Is(d.reader.Error, io.EOF) {
+			blk.err = errors.New("decoder: unexpected end of file")
+			return blk
+		}
+
+		blk.err = d.reader.Error
+		return blk
+	}
+
+	r := avro.NewReader(nil, 0).Reset(buf[:len(buf)-len(d.sync)])
+	blk.count = r.ReadLong()
+	size := r.ReadLong()
+	if r.Error != nil || blk.count < 0 || size != int64(len(buf)-len(d.sync))-r.InputOffset() {
+		blk.err = errors.New("decoder: invalid block")
+		return blk
+	}
+	blk.data = buf[r.InputOffset() : len(buf)-len(d.sync)]
+
+	return blk
+}
+
+// validate determines if the block data holds exactly count values.
+func (d *Decoder) validate(blk *block) error {
+	r := avro.NewReader(nil, 0).Reset(blk.data)
+	for i := int64(0); i < blk.count; i++ {
+		_ = r.ReadNext(d.schema)
+		if r.Error != nil {
+			return fmt.Errorf("decoder: invalid data: %w", r.Error)
+		}
+	}
+
+	if r.InputOffset() != int64(len(blk.data)) {
+		return errors.New("decoder: invalid data: trailing bytes in block")
+	}
+
+	return nil
+}
+
+// Repair copies all readable blocks from the container file in src to a new container file in dst,
+// returning the regions of src that were skipped.
+//
+// The schema, codec and metadata of src are kept. Blocks are only copied if all their values can be read.
+func Repair(dst io.Writer, src io.Reader) ([]SkippedRegion, error) {
+	dec, err := NewDecoder(src, WithRecovery(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string][]byte, len(dec.meta))
+	for k, v := range dec.meta {
+		meta[k] = v
+	}
+	enc, err := NewEncoder(string(dec.meta[schemaKey]), dst,
+		WithCodec(CodecName(dec.meta[codecKey])),
+		WithMetadata(meta),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		blk := dec.next()
+		if blk == nil || blk.err != nil {
+			if blk != nil {
+				dec.err = blk.err
+			}
+			break
+		}
+		if blk.count == 0 {
+			continue
+		}
+
+		if err = dec.validate(blk); err != nil {
+			dec.skip(blk, err)
+			continue
+		}
+
+		enc.writeBlock(blk.count, len(blk.data), enc.codec.Encode(blk.data))
+		if err = enc.writer.Flush(); err != nil {
+			return dec.Skipped(), err
+		}
 	}
 
-	return count
+	return dec.Skipped(), dec.Error()
 }
 
 type encoderConfig struct {
 	BlockLength int
+	BlockSize   int
+	BlockAge    time.Duration
 	CodecName   CodecName
 	Metadata    map[string][]byte
+	Concurrency int
 }
 
 // EncoderFunc represents an configuration function for Encoder.
@@ -170,6 +447,22 @@ func WithBlockLength(length int) EncoderFunc {
 	}
 }
 
+// WithBlockSize sets the uncompressed size in bytes after which a block is written,
+// regardless of the number of records in it.
+func WithBlockSize(size int) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.BlockSize = size
+	}
+}
+
+// WithMaxBlockAge sets the maximum time a record is buffered before its
+// block is written and flushed, even if no further records are encoded.
+func WithMaxBlockAge(age time.Duration) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.BlockAge = age
+	}
+}
+
 // WithCodec sets the compression codec on the encoder.
 func WithCodec(codec CodecName) EncoderFunc {
 	return func(cfg *encoderConfig) {
@@ -184,8 +477,33 @@ func WithMetadata(meta map[string][]byte) EncoderFunc {
 	}
 }
 
+// WithConcurrency sets the number of blocks that are compressed concurrently
+// by the encoder. Blocks are still written in order.
+//
+// Only compression is concurrent: records are encoded into the block by the
+// caller of Encode.
+func WithConcurrency(n int) EncoderFunc {
+	return func(cfg *encoderConfig) {
+		cfg.Concurrency = n
+	}
+}
+
+// EncoderStats contains statistics about the blocks written by an Encoder.
+type EncoderStats struct {
+	// Blocks is the number of blocks written.
+	Blocks int64
+	// Records is the number of records written.
+	Records int64
+	// UncompressedBytes is the size of the written blocks before compression.
+	UncompressedBytes int64
+	// CompressedBytes is the size of the written blocks after compression.
+	CompressedBytes int64
+}
+
 // Encoder writes Avro container file to an output stream.
 type Encoder struct {
+	mu sync.Mutex
+
 	writer  *avro.Writer
 	buf     *bytes.Buffer
 	encoder *avro.Encoder
@@ -194,7 +512,18 @@ type Encoder struct {
 	codec Codec
 
 	blockLength int
+	blockSize   int
 	count       int
+
+	blockAge time.Duration
+	timer    *time.Timer
+	// generation counts the blocks started, telling age flushes of written blocks apart.
+	generation uint64
+
+	concurrency int
+	pending     []*block
+
+	stats EncoderStats
 }
 
 // NewEncoder returns a new encoder that writes to w using schema s.
@@ -213,9 +542,16 @@ func NewEncoder(s string, w io.Writer, opts ...EncoderFunc) (*Encoder, error) {
 		opt(&cfg)
 	}
 
+	// The schema is written in full, rather than in its canonical form, so the
+	// field orders used by Sort are kept.
+	schemaJSON, err := jsoniter.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
 	writer := avro.NewWriter(w, 512)
 
-	cfg.Metadata[schemaKey] = []byte(schema.String())
+	cfg.Metadata[schemaKey] = schemaJSON
 	cfg.Metadata[codecKey] = []byte(cfg.CodecName)
 	header := Header{
 		Magic: magicBytes,
@@ -238,6 +574,9 @@ func NewEncoder(s string, w io.Writer, opts ...EncoderFunc) (*Encoder, error) {
 		sync:        header.Sync,
 		codec:       codec,
 		blockLength: cfg.BlockLength,
+		blockSize:   cfg.BlockSize,
+		blockAge:    cfg.BlockAge,
+		concurrency: cfg.Concurrency,
 	}
 
 	return e, nil
@@ -245,12 +584,38 @@ func NewEncoder(s string, w io.Writer, opts ...EncoderFunc) (*Encoder, error) {
 
 // Encode writes the Avro encoding of v to the stream.
 func (e *Encoder) Encode(v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if err := e.encoder.Encode(v); err != nil {
 		return err
 	}
 
+	return e.added()
+}
+
+// Write writes the already Avro encoded value in p to the stream.
+//
+// The value is not checked against the schema given to NewEncoder, so the caller is
+// responsible for the encoding being correct, otherwise the resulting container file will be corrupt.
+func (e *Encoder) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n, _ := e.buf.Write(p)
+
+	return n, e.added()
+}
+
+// added is called after a value has been added to the current block.
+func (e *Encoder) added() error {
 	e.count++
-	if e.count >= e.blockLength {
+	if e.count == 1 && e.blockAge > 0 {
+		generation := e.generation
+		e.timer = time.AfterFunc(e.blockAge, func() { e.flushAged(generation) })
+	}
+
+	if e.count >= e.blockLength || (e.blockSize > 0 && e.buf.Len() >= e.blockSize) {
 		if err := e.writerBlock(); err != nil {
 			return err
 		}
@@ -261,33 +626,144 @@ func (e *Encoder) Encode(v interface{}) error {
 
 // Flush flushes the underlying writer.
 func (e *Encoder) Flush() error {
-	if e.count == 0 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.flush()
+}
+
+// Close closes the encoder, flushing the writer.
+func (e *Encoder) Close() error {
+	return e.Flush()
+}
+
+// Stats returns the statistics of the blocks written so far.
+func (e *Encoder) Stats() EncoderStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.stats
+}
+
+// buffered returns the number of bytes held by the encoder that have not been written
+// to the underlying writer yet. Blocks that are not compressed yet count with their uncompressed size.
+func (e *Encoder) buffered() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := e.writer.Buffered() + e.buf.Len()
+	for _, blk := range e.pending {
+		n += blk.size
+	}
+	return n
+}
+
+func (e *Encoder) flush() error {
+	if e.count == 0 && len(e.pending) == 0 {
 		return nil
 	}
 
-	if err := e.writerBlock(); err != nil {
+	if e.concurrency <= 1 {
+		if err := e.writerBlock(); err != nil {
+			return err
+		}
+
+		return e.writer.Error
+	}
+
+	if e.count > 0 {
+		e.queueBlock()
+	}
+
+	for len(e.pending) > 0 {
+		e.writePending()
+	}
+
+	if err := e.writer.Flush(); err != nil {
 		return err
 	}
 
 	return e.writer.Error
 }
 
-// Close closes the encoder, flushing the writer.
-func (e *Encoder) Close() error {
-	return e.Flush()
+// flushAged is called when the oldest buffered record has reached the maximum block age.
+// Any error is kept on the writer and returned by the next call to Encode or Flush.
+func (e *Encoder) flushAged(generation uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.generation != generation {
+		// The block has already been written.
+		return
+	}
+
+	e.timer = nil
+	_ = e.flush()
 }
 
 func (e *Encoder) writerBlock() error {
-	e.writer.WriteLong(int64(e.count))
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
 
-	b := e.codec.Encode(e.buf.Bytes())
+	if e.concurrency > 1 {
+		e.queueBlock()
 
-	e.writer.WriteLong(int64(len(b)))
-	e.writer.Write(b)
+		// Bound the number of blocks held in memory.
+		if len(e.pending) <= e.concurrency {
+			return nil
+		}
 
-	e.writer.Write(e.sync[:])
+		e.writePending()
+		return e.writer.Flush()
+	}
+
+	e.writeBlock(int64(e.count), e.buf.Len(), e.codec.Encode(e.buf.Bytes()))
 
 	e.count = 0
+	e.generation++
 	e.buf.Reset()
 	return e.writer.Flush()
 }
+
+// queueBlock compresses the buffered block on its own goroutine.
+func (e *Encoder) queueBlock() {
+	data := make([]byte, e.buf.Len())
+	copy(data, e.buf.Bytes())
+
+	blk := &block{count: int64(e.count), size: len(data), done: make(chan struct{})}
+	go func() {
+		defer close(blk.done)
+
+		blk.data = e.codec.Encode(data)
+	}()
+	e.pending = append(e.pending, blk)
+
+	e.count = 0
+	e.generation++
+	e.buf.Reset()
+}
+
+// writePending waits for the oldest pending block and writes it.
+func (e *Encoder) writePending() {
+	blk := e.pending[0]
+	e.pending[0] = nil
+	e.pending = e.pending[1:]
+
+	<-blk.done
+	e.writeBlock(blk.count, blk.size, blk.data)
+}
+
+func (e *Encoder) writeBlock(count int64, size int, b []byte) {
+	e.writer.WriteLong(count)
+	e.writer.WriteLong(int64(len(b)))
+	e.writer.Write(b)
+
+	e.writer.Write(e.sync[:])
+
+	e.stats.Blocks++
+	e.stats.Records += count
+	e.stats.UncompressedBytes += int64(size)
+	e.stats.CompressedBytes += int64(len(b))
+}
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/xl4hub/hamba-avro/ocf"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var schema = `{
@@ -518,6 +520,24 @@ func TestEncoder_EncodeWritesBlocks(t *testing.T) {
 	assert.Equal(t, 77, buf.Len())
 }
 
+func TestEncoder_Write(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"long"`, buf, ocf.WithBlockLength(1))
+	defer enc.Close()
+
+	n, err := enc.Write([]byte{0x02})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 77, buf.Len())
+
+	dec, _ := ocf.NewDecoder(buf)
+	var got int64
+	assert.True(t, dec.HasNext())
+	assert.NoError(t, dec.Decode(&got))
+	assert.Equal(t, int64(1), got)
+}
+
 func TestEncoder_EncodeHandlesWriteBlockError(t *testing.T) {
 	w := &errorWriter{}
 	enc, _ := ocf.NewEncoder(`"long"`, w, ocf.WithBlockLength(1))
@@ -555,6 +575,344 @@ func TestEncodeDecodeMetadata(t *testing.T) {
 	assert.Equal(t, []byte("foo"), dec.Metadata()["test"])
 }
 
+func TestEncoder_Concurrency(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"long"`, buf, ocf.WithBlockLength(3), ocf.WithCodec(ocf.Deflate), ocf.WithConcurrency(4))
+
+	for i := int64(0); i < 100; i++ {
+		err := enc.Encode(i)
+		assert.NoError(t, err)
+	}
+
+	err := enc.Close()
+	assert.NoError(t, err)
+
+	dec, err := ocf.NewDecoder(buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		err = dec.Decode(&l)
+
+		assert.NoError(t, err)
+		got = append(got, l)
+	}
+
+	assert.NoError(t, dec.Error())
+	assert.Len(t, got, 100)
+	for i, l := range got {
+		assert.Equal(t, int64(i), l)
+	}
+}
+
+func TestEncoder_ConcurrencyHandlesWriteBlockError(t *testing.T) {
+	w := &errorWriter{}
+	enc, _ := ocf.NewEncoder(`"long"`, w, ocf.WithBlockLength(1), ocf.WithConcurrency(2))
+
+	var err error
+	for i := int64(0); i < 3 && err == nil; i++ {
+		err = enc.Encode(i)
+	}
+	if err == nil {
+		err = enc.Close()
+	}
+
+	assert.Error(t, err)
+}
+
+func TestDecoder_Concurrency(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"long"`, buf, ocf.WithBlockLength(7), ocf.WithCodec(ocf.Snappy))
+	for i := int64(0); i < 100; i++ {
+		_ = enc.Encode(i)
+	}
+	_ = enc.Close()
+
+	dec, err := ocf.NewDecoder(buf, ocf.WithDecoderConcurrency(3))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var want int64
+	for dec.HasNext() {
+		var l int64
+		err = dec.Decode(&l)
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, l)
+		want++
+	}
+
+	assert.NoError(t, dec.Error())
+	assert.Equal(t, int64(100), want)
+}
+
+func TestDecoder_ConcurrencyInvalidData(t *testing.T) {
+	f, err := os.Open("../testdata/deflate-invalid-data.avro")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer f.Close()
+
+	dec, err := ocf.NewDecoder(f, ocf.WithDecoderConcurrency(2))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	got := dec.HasNext()
+
+	assert.False(t, got)
+	assert.Error(t, dec.Error())
+}
+
+func TestDecoder_ConcurrencyInvalidBlock(t *testing.T) {
+	data := []byte{'O', 'b', 'j', 0x01, 0x01, 0x26, 0x16, 'a', 'v', 'r', 'o', '.', 's', 'c', 'h', 'e', 'm', 'a',
+		0x0c, '"', 'l', 'o', 'n', 'g', '"', 0x00, 0xfa, 0x2b, 0x0f, 0x1a, 0xdd, 0xfd, 0x90, 0x7d, 0x87, 0x12,
+		0x15, 0x29, 0xd7, 0x1d, 0x1c, 0xdd, 0x02, 0x02, 0x02, 0xfb, 0x2b, 0x0f, 0x1a, 0xdd, 0xfd, 0x90, 0x7d,
+		0x87, 0x12, 0x15, 0x29, 0xd7, 0x1d, 0x1c, 0xdd,
+	}
+
+	dec, _ := ocf.NewDecoder(bytes.NewReader(data), ocf.WithDecoderConcurrency(2))
+
+	got := dec.HasNext()
+
+	assert.False(t, got)
+	assert.Error(t, dec.Error())
+}
+
+func TestEncoder_EncodeWritesBlocksBySize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"string"`, buf, ocf.WithBlockSize(10))
+
+	err := enc.Encode("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), enc.Stats().Blocks)
+
+	err = enc.Encode("12345")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), enc.Stats().Blocks)
+
+	err = enc.Close()
+	assert.NoError(t, err)
+}
+
+func TestEncoder_EncodeWritesBlocksByAge(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"long"`, buf, ocf.WithMaxBlockAge(10*time.Millisecond))
+	defer enc.Close()
+
+	err := enc.Encode(int64(1))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return enc.Stats().Blocks == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 77, buf.Len())
+}
+
+func TestEncoder_EncodeWritesBlocksByVeryShortAge(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"long"`, buf, ocf.WithMaxBlockAge(time.Nanosecond))
+
+	for i := 0; i < 100; i++ {
+		err := enc.Encode(int64(i))
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			return enc.Stats().Records == int64(i+1)
+		}, time.Second, time.Millisecond)
+	}
+	require.NoError(t, enc.Close())
+
+	dec, err := ocf.NewDecoder(buf)
+	require.NoError(t, err)
+	var got []int64
+	for dec.HasNext() {
+		var v int64
+		require.NoError(t, dec.Decode(&v))
+		got = append(got, v)
+	}
+	require.NoError(t, dec.Error())
+	assert.Len(t, got, 100)
+}
+
+func TestEncoder_Stats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	enc, _ := ocf.NewEncoder(`"string"`, buf, ocf.WithBlockLength(2), ocf.WithCodec(ocf.Deflate))
+
+	for i := 0; i < 5; i++ {
+		_ = enc.Encode("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	}
+	_ = enc.Close()
+
+	got := enc.Stats()
+
+	assert.Equal(t, int64(3), got.Blocks)
+	assert.Equal(t, int64(5), got.Records)
+	assert.Equal(t, int64(5*49), got.UncompressedBytes)
+	assert.Less(t, got.CompressedBytes, got.UncompressedBytes)
+}
+
+func TestDecoder_Recovery(t *testing.T) {
+	data, offsets := encodeLongBlocks(t, 3)
+	data[offsets[1]] = 0x7f
+
+	var skipped []ocf.SkippedRegion
+	dec, err := ocf.NewDecoder(bytes.NewReader(data), ocf.WithRecovery(func(r ocf.SkippedRegion) {
+		skipped = append(skipped, r)
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		err = dec.Decode(&l)
+
+		assert.NoError(t, err)
+		got = append(got, l)
+	}
+
+	assert.NoError(t, dec.Error())
+	assert.Equal(t, []int64{0, 1, 4, 5}, got)
+	assert.Equal(t, skipped, dec.Skipped())
+	if assert.Len(t, skipped, 1) {
+		assert.Equal(t, int64(offsets[1]), skipped[0].Offset)
+		assert.Equal(t, int64(offsets[2]-offsets[1]), skipped[0].Length)
+		assert.Error(t, skipped[0].Err)
+	}
+}
+
+func TestDecoder_RecoveryWithConcurrency(t *testing.T) {
+	data, offsets := encodeLongBlocks(t, 4)
+	data[offsets[2]-1] ^= 0xff
+
+	dec, _ := ocf.NewDecoder(bytes.NewReader(data), ocf.WithRecovery(nil), ocf.WithDecoderConcurrency(2))
+
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		_ = dec.Decode(&l)
+		got = append(got, l)
+	}
+
+	assert.NoError(t, dec.Error())
+	assert.Equal(t, []int64{0, 1, 6, 7}, got)
+	if assert.Len(t, dec.Skipped(), 1) {
+		assert.Equal(t, int64(offsets[1]), dec.Skipped()[0].Offset)
+		assert.Equal(t, int64(offsets[3]-offsets[1]), dec.Skipped()[0].Length)
+	}
+}
+
+func TestDecoder_RecoveryTruncatedFile(t *testing.T) {
+	data, offsets := encodeLongBlocks(t, 2)
+	data = data[:len(data)-5]
+
+	dec, _ := ocf.NewDecoder(bytes.NewReader(data), ocf.WithRecovery(nil))
+
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		_ = dec.Decode(&l)
+		got = append(got, l)
+	}
+
+	assert.NoError(t, dec.Error())
+	assert.Equal(t, []int64{0, 1}, got)
+	if assert.Len(t, dec.Skipped(), 1) {
+		assert.Equal(t, int64(offsets[1]), dec.Skipped()[0].Offset)
+		assert.Equal(t, int64(len(data)-offsets[1]), dec.Skipped()[0].Length)
+	}
+}
+
+func TestDecoder_WithoutRecoveryStopsAtInvalidBlock(t *testing.T) {
+	data, offsets := encodeLongBlocks(t, 3)
+	data[offsets[1]] = 0x7f
+
+	dec, _ := ocf.NewDecoder(bytes.NewReader(data))
+
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		_ = dec.Decode(&l)
+		got = append(got, l)
+	}
+
+	assert.Error(t, dec.Error())
+	assert.Equal(t, []int64{0, 1}, got)
+}
+
+func TestRepair(t *testing.T) {
+	data, offsets := encodeLongBlocks(t, 3)
+	// Corrupt the first value of the second block, keeping its header intact.
+	data[offsets[1]+2] = 0x80
+
+	buf := &bytes.Buffer{}
+	skipped, err := ocf.Repair(buf, bytes.NewReader(data))
+
+	assert.NoError(t, err)
+	if assert.Len(t, skipped, 1) {
+		assert.Equal(t, int64(offsets[1]), skipped[0].Offset)
+	}
+
+	dec, err := ocf.NewDecoder(buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var got []int64
+	for dec.HasNext() {
+		var l int64
+		_ = dec.Decode(&l)
+		got = append(got, l)
+	}
+	assert.NoError(t, dec.Error())
+	assert.Equal(t, []int64{0, 1, 4, 5}, got)
+}
+
+func TestRepair_InvalidFile(t *testing.T) {
+	_, err := ocf.Repair(&bytes.Buffer{}, bytes.NewReader([]byte{'O', 'b', 'j'}))
+
+	assert.Error(t, err)
+}
+
+// encodeLongBlocks encodes n blocks of 2 longs each, returning the
+// data and the offset of each block.
+func encodeLongBlocks(t *testing.T, n int) ([]byte, []int) {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	enc, err := ocf.NewEncoder(`"long"`, buf, ocf.WithBlockLength(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		_ = enc.Encode(int64(2 * i))
+		_ = enc.Encode(int64(2*i + 1))
+
+		if i == 0 {
+			// The header is written with the first block, which is 20 bytes long.
+			offsets = append(offsets, buf.Len()-20)
+		}
+		offsets = append(offsets, buf.Len())
+	}
+	offsets = offsets[:n]
+	_ = enc.Close()
+
+	return buf.Bytes(), offsets
+}
+
 type errorWriter struct{}
 
 func (*errorWriter) Write(p []byte) (n int, err error) {
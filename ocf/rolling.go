@@ -0,0 +1,288 @@
+package ocf
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	avro "github.com/xl4hub/hamba-avro"
+)
+
+// FileFactory creates the n-th file written by a RollingEncoder, starting at 0.
+type FileFactory func(n int) (io.WriteCloser, error)
+
+// ClosedFile describes a file closed by a RollingEncoder.
+type ClosedFile struct {
+	// Index is the number the file was created with by the FileFactory.
+	Index int
+	// File is the closed file as returned by the FileFactory.
+	File io.WriteCloser
+	// Size is the number of bytes written to the file.
+	Size int64
+	// Stats contains the statistics of the blocks written to the file.
+	Stats EncoderStats
+}
+
+type rollingConfig struct {
+	MaxSize     int64
+	MaxRecords  int64
+	MaxAge      time.Duration
+	EncoderOpts []EncoderFunc
+	Closed      func(ClosedFile)
+}
+
+// RollingEncoderFunc represents an configuration function for RollingEncoder.
+type RollingEncoderFunc func(cfg *rollingConfig)
+
+// WithMaxFileSize sets the size in bytes after which a file is closed and a new file is started.
+// Records that have not been written in a block yet count with their uncompressed size.
+func WithMaxFileSize(size int64) RollingEncoderFunc {
+	return func(cfg *rollingConfig) {
+		cfg.MaxSize = size
+	}
+}
+
+// WithMaxFileRecords sets the number of records after which a file is closed and a new file is started.
+func WithMaxFileRecords(n int64) RollingEncoderFunc {
+	return func(cfg *rollingConfig) {
+		cfg.MaxRecords = n
+	}
+}
+
+// WithMaxFileAge sets the time after which a file is closed, even if no further records are encoded.
+// The age of a file starts when its first record is encoded.
+func WithMaxFileAge(age time.Duration) RollingEncoderFunc {
+	return func(cfg *rollingConfig) {
+		cfg.MaxAge = age
+	}
+}
+
+// WithEncoderOptions sets the options of the Encoder used for each file.
+func WithEncoderOptions(opts ...EncoderFunc) RollingEncoderFunc {
+	return func(cfg *rollingConfig) {
+		cfg.EncoderOpts = opts
+	}
+}
+
+// WithFileClosed sets the function called after a file has been completely written and closed.
+//
+// The function is called while the encoder is locked and may be called from
+// another goroutine when the file is closed because of its age.
+func WithFileClosed(fn func(ClosedFile)) RollingEncoderFunc {
+	return func(cfg *rollingConfig) {
+		cfg.Closed = fn
+	}
+}
+
+// countWriter counts the bytes written to a file. The count is atomic, as age flushes
+// write from the timer goroutine of the encoder.
+type countWriter struct {
+	n int64 // Kept first for 64-bit alignment.
+	w io.Writer
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	atomic.AddInt64(&w.n, int64(n))
+	return n, err
+}
+
+func (w *countWriter) written() int64 {
+	return atomic.LoadInt64(&w.n)
+}
+
+// RollingEncoder writes Avro container files, starting a new file when the current
+// file reaches its maximum size, number of records or age.
+//
+// Files are only created once a record is encoded, and each file is a complete container file
+// by the time it is closed.
+type RollingEncoder struct {
+	mu sync.Mutex
+
+	schema  string
+	factory FileFactory
+	cfg     rollingConfig
+
+	n       int
+	file    io.WriteCloser
+	counter *countWriter
+	enc     *Encoder
+	records int64
+	timer   *time.Timer
+
+	// err is an error from closing a file because of its age.
+	err error
+}
+
+// NewRollingEncoder returns a new rolling encoder that writes to files created by factory using schema s.
+func NewRollingEncoder(s string, factory FileFactory, opts ...RollingEncoderFunc) (*RollingEncoder, error) {
+	if _, err := avro.Parse(s); err != nil {
+		return nil, err
+	}
+	if factory == nil {
+		return nil, errors.New("ocf: file factory is required")
+	}
+
+	var cfg rollingConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &RollingEncoder{
+		schema:  s,
+		factory: factory,
+		cfg:     cfg,
+	}, nil
+}
+
+// Encode writes the Avro encoding of v to the current file, starting a new file if needed.
+func (e *RollingEncoder) Encode(v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.takeErr(); err != nil {
+		return err
+	}
+
+	if e.enc == nil {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	e.records++
+
+	if e.full() {
+		return e.close()
+	}
+	return nil
+}
+
+// Flush writes the buffered records of the current file.
+func (e *RollingEncoder) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.takeErr(); err != nil {
+		return err
+	}
+
+	if e.enc == nil {
+		return nil
+	}
+	return e.enc.Flush()
+}
+
+// Rotate closes the current file, if any. The next record is written to a new file.
+func (e *RollingEncoder) Rotate() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.takeErr(); err != nil {
+		return err
+	}
+
+	return e.close()
+}
+
+// Close closes the current file, if any.
+func (e *RollingEncoder) Close() error {
+	return e.Rotate()
+}
+
+func (e *RollingEncoder) takeErr() error {
+	err := e.err
+	e.err = nil
+	return err
+}
+
+func (e *RollingEncoder) open() error {
+	file, err := e.factory(e.n)
+	if err != nil {
+		return err
+	}
+
+	counter := &countWriter{w: file}
+	enc, err := NewEncoder(e.schema, counter, e.cfg.EncoderOpts...)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	e.file = file
+	e.counter = counter
+	e.enc = enc
+	e.records = 0
+
+	if e.cfg.MaxAge > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(e.cfg.MaxAge, func() { e.closeAged(timer) })
+		e.timer = timer
+	}
+
+	return nil
+}
+
+func (e *RollingEncoder) full() bool {
+	if e.cfg.MaxRecords > 0 && e.records >= e.cfg.MaxRecords {
+		return true
+	}
+
+	return e.cfg.MaxSize > 0 && e.counter.written()+int64(e.enc.buffered()) >= e.cfg.MaxSize
+}
+
+// closeAged is called when the current file has reached its maximum age.
+// Any error is kept and returned by the next call to the encoder.
+func (e *RollingEncoder) closeAged(timer *time.Timer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.timer != timer {
+		// The file has already been closed.
+		return
+	}
+
+	if err := e.close(); err != nil && e.err == nil {
+		e.err = err
+	}
+}
+
+// close writes the remaining records of the current file and closes it.
+func (e *RollingEncoder) close() error {
+	if e.enc == nil {
+		return nil
+	}
+
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+
+	file, counter, enc := e.file, e.counter, e.enc
+	e.file, e.counter, e.enc = nil, nil, nil
+	idx := e.n
+	e.n++
+
+	if err := enc.Close(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if e.cfg.Closed != nil {
+		e.cfg.Closed(ClosedFile{
+			Index: idx,
+			File:  file,
+			Size:  counter.written(),
+			Stats: enc.Stats(),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package ocf_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro/ocf"
+)
+
+type memFile struct {
+	bytes.Buffer
+
+	closed   bool
+	closeErr error
+}
+
+func (f *memFile) Close() error {
+	f.closed = true
+	return f.closeErr
+}
+
+type memFiles struct {
+	mu     sync.Mutex
+	files  []*memFile
+	closed []ocf.ClosedFile
+}
+
+func (m *memFiles) create(n int) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n != len(m.files) {
+		return nil, errors.New("unexpected file index")
+	}
+	f := &memFile{}
+	m.files = append(m.files, f)
+	return f, nil
+}
+
+func (m *memFiles) onClosed(f ocf.ClosedFile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = append(m.closed, f)
+}
+
+func (m *memFiles) closedFiles() []ocf.ClosedFile {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]ocf.ClosedFile(nil), m.closed...)
+}
+
+func readLongs(t *testing.T, b []byte) []int64 {
+	t.Helper()
+
+	dec, err := ocf.NewDecoder(bytes.NewReader(b))
+	require.NoError(t, err)
+
+	var got []int64
+	for dec.HasNext() {
+		var v int64
+		require.NoError(t, dec.Decode(&v))
+		got = append(got, v)
+	}
+	require.NoError(t, dec.Error())
+	return got
+}
+
+func TestNewRollingEncoder_InvalidSchema(t *testing.T) {
+	files := &memFiles{}
+
+	_, err := ocf.NewRollingEncoder(`{`, files.create)
+
+	assert.Error(t, err)
+}
+
+func TestNewRollingEncoder_RequiresFactory(t *testing.T) {
+	_, err := ocf.NewRollingEncoder(`"long"`, nil)
+
+	assert.Error(t, err)
+}
+
+func TestRollingEncoder_RotatesByRecords(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create,
+		ocf.WithMaxFileRecords(3),
+		ocf.WithFileClosed(files.onClosed),
+	)
+	require.NoError(t, err)
+
+	for i := int64(0); i < 7; i++ {
+		require.NoError(t, enc.Encode(i))
+	}
+	require.NoError(t, enc.Close())
+
+	require.Len(t, files.files, 3)
+	assert.Equal(t, []int64{0, 1, 2}, readLongs(t, files.files[0].Bytes()))
+	assert.Equal(t, []int64{3, 4, 5}, readLongs(t, files.files[1].Bytes()))
+	assert.Equal(t, []int64{6}, readLongs(t, files.files[2].Bytes()))
+
+	closed := files.closedFiles()
+	require.Len(t, closed, 3)
+	for i, f := range closed {
+		assert.Equal(t, i, f.Index)
+		assert.Same(t, files.files[i], f.File)
+		assert.True(t, files.files[i].closed)
+		assert.Equal(t, int64(files.files[i].Len()), f.Size)
+	}
+	assert.Equal(t, int64(3), closed[0].Stats.Records)
+	assert.Equal(t, int64(1), closed[2].Stats.Records)
+}
+
+func TestRollingEncoder_RotatesBySize(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"string"`, files.create,
+		ocf.WithMaxFileSize(200),
+		ocf.WithEncoderOptions(ocf.WithBlockLength(10)),
+	)
+	require.NoError(t, err)
+
+	value := string(bytes.Repeat([]byte("a"), 50))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, enc.Encode(value))
+	}
+	require.NoError(t, enc.Close())
+
+	require.Greater(t, len(files.files), 1)
+	var total int
+	for _, f := range files.files {
+		assert.True(t, f.closed)
+
+		dec, err := ocf.NewDecoder(bytes.NewReader(f.Bytes()))
+		require.NoError(t, err)
+		for dec.HasNext() {
+			var got string
+			require.NoError(t, dec.Decode(&got))
+			assert.Equal(t, value, got)
+			total++
+		}
+		require.NoError(t, dec.Error())
+	}
+	assert.Equal(t, 10, total)
+}
+
+func TestRollingEncoder_RotatesBySizeWithBlockAge(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create,
+		ocf.WithMaxFileSize(200),
+		ocf.WithEncoderOptions(ocf.WithMaxBlockAge(time.Nanosecond)),
+	)
+	require.NoError(t, err)
+
+	for i := int64(0); i < 50; i++ {
+		require.NoError(t, enc.Encode(i))
+		time.Sleep(100 * time.Microsecond)
+	}
+	require.NoError(t, enc.Close())
+
+	require.Greater(t, len(files.files), 1)
+	var got []int64
+	for _, f := range files.files {
+		got = append(got, readLongs(t, f.Bytes())...)
+	}
+	assert.Len(t, got, 50)
+}
+
+func TestRollingEncoder_RotatesByAge(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create,
+		ocf.WithMaxFileAge(10*time.Millisecond),
+		ocf.WithFileClosed(files.onClosed),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, enc.Encode(int64(1)))
+	require.NoError(t, enc.Encode(int64(2)))
+
+	assert.Eventually(t, func() bool {
+		return len(files.closedFiles()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, enc.Encode(int64(3)))
+	require.NoError(t, enc.Close())
+
+	closed := files.closedFiles()
+	require.Len(t, closed, 2)
+	assert.Equal(t, []int64{1, 2}, readLongs(t, files.files[0].Bytes()))
+	assert.Equal(t, []int64{3}, readLongs(t, files.files[1].Bytes()))
+}
+
+func TestRollingEncoder_Rotate(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create)
+	require.NoError(t, err)
+
+	require.NoError(t, enc.Encode(int64(1)))
+	require.NoError(t, enc.Rotate())
+	require.NoError(t, enc.Rotate())
+	require.NoError(t, enc.Encode(int64(2)))
+	require.NoError(t, enc.Close())
+
+	require.Len(t, files.files, 2)
+	assert.Equal(t, []int64{1}, readLongs(t, files.files[0].Bytes()))
+	assert.Equal(t, []int64{2}, readLongs(t, files.files[1].Bytes()))
+}
+
+func TestRollingEncoder_CloseWithoutRecordsCreatesNoFile(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create)
+	require.NoError(t, err)
+
+	require.NoError(t, enc.Flush())
+	require.NoError(t, enc.Close())
+
+	assert.Empty(t, files.files)
+}
+
+func TestRollingEncoder_FactoryError(t *testing.T) {
+	enc, err := ocf.NewRollingEncoder(`"long"`, func(int) (io.WriteCloser, error) {
+		return nil, errors.New("test")
+	})
+	require.NoError(t, err)
+
+	err = enc.Encode(int64(1))
+
+	assert.Error(t, err)
+}
+
+func TestRollingEncoder_FileCloseError(t *testing.T) {
+	called := false
+	enc, err := ocf.NewRollingEncoder(`"long"`, func(int) (io.WriteCloser, error) {
+		return &memFile{closeErr: errors.New("test")}, nil
+	}, ocf.WithFileClosed(func(ocf.ClosedFile) { called = true }))
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(int64(1)))
+
+	err = enc.Close()
+
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func TestRollingEncoder_EncodeError(t *testing.T) {
+	files := &memFiles{}
+	enc, err := ocf.NewRollingEncoder(`"long"`, files.create)
+	require.NoError(t, err)
+
+	err = enc.Encode("test")
+
+	assert.Error(t, err)
+}
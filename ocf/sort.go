@@ -0,0 +1,604 @@
+package ocf
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	avro "github.com/xl4hub/hamba-avro"
+)
+
+// maxMergeRuns is the number of runs merged at once, bounding the number of open temporary files.
+const maxMergeRuns = 64
+
+type sortConfig struct {
+	Schema    avro.Schema
+	KeySchema avro.Schema
+	KeyFunc   func(data []byte) ([]byte, error)
+	Dedupe    bool
+	RunSize   int
+	TempDir   string
+}
+
+// SortFunc represents an configuration function for Sort and Merge.
+type SortFunc func(cfg *sortConfig)
+
+// WithSortSchema sorts records in the sort order of schema instead of the schema of the
+// container file, which must have the same canonical form. This allows sorting files
+// by field orders other than those in their header, such as files written by other
+// tools in canonical form.
+func WithSortSchema(schema avro.Schema) SortFunc {
+	return func(cfg *sortConfig) {
+		cfg.Schema = schema
+	}
+}
+
+// WithSortKey sorts records by the key returned by fn for their Avro encoding, instead of
+// by the record itself. The key must be the Avro encoding of a value of schema, and keys are
+// compared in the sort order of schema.
+func WithSortKey(schema avro.Schema, fn func(data []byte) ([]byte, error)) SortFunc {
+	return func(cfg *sortConfig) {
+		cfg.KeySchema = schema
+		cfg.KeyFunc = fn
+	}
+}
+
+// WithDedupe only keeps the first of the records with equal keys.
+func WithDedupe() SortFunc {
+	return func(cfg *sortConfig) {
+		cfg.Dedupe = true
+	}
+}
+
+// WithRunSize sets the number of bytes of records sorted in memory before they are
+// written to a temporary file as a sorted run. The size must be greater than 0.
+// This defaults to 64MB.
+func WithRunSize(size int) SortFunc {
+	return func(cfg *sortConfig) {
+		cfg.RunSize = size
+	}
+}
+
+// WithTempDir sets the directory the sorted runs are written to. This defaults to
+// the default directory for temporary files.
+func WithTempDir(dir string) SortFunc {
+	return func(cfg *sortConfig) {
+		cfg.TempDir = dir
+	}
+}
+
+func newSortConfig(opts []SortFunc) (sortConfig, error) {
+	cfg := sortConfig{RunSize: 64 << 20}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.RunSize <= 0 {
+		return sortConfig{}, fmt.Errorf("ocf: invalid run size %d", cfg.RunSize)
+	}
+	return cfg, nil
+}
+
+// Sort writes the records of the container file in src to a new container file in dst,
+// sorted in the sort order of its schema, or the schema set with WithSortSchema, or by the
+// key set with WithSortKey. Records with equal keys keep their order.
+//
+// Records are sorted in memory in runs of the size set with WithRunSize, which are written
+// to temporary files and merged if src holds more than one run. The schema, codec and
+// metadata of src are kept.
+func Sort(dst io.Writer, src io.Reader, opts ...SortFunc) error {
+	cfg, err := newSortConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	in, err := newRecordReader(src)
+	if err != nil {
+		return err
+	}
+	s, err := newSorter(cfg, in.dec.schema)
+	if err != nil {
+		return err
+	}
+	defer s.cleanup()
+
+	var run recordRun
+	for {
+		data, err := in.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		key, err := s.key(data)
+		if err != nil {
+			return err
+		}
+		run.add(data, key)
+		if run.size() >= cfg.RunSize {
+			if err = s.spill(&run, in.dec); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(s.runs) == 0 {
+		// All records fit in memory.
+		enc, err := newEncoderLike(dst, in.dec)
+		if err != nil {
+			return err
+		}
+		if err = s.sortRun(&run); err != nil {
+			return err
+		}
+		if err = s.writeRun(enc, &run); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	if err = s.spill(&run, in.dec); err != nil {
+		return err
+	}
+	return s.mergeRuns(dst, in.dec)
+}
+
+// Merge writes the records of the sorted container files in srcs to a new container file
+// in dst, in the order set by the options as for Sort. Records with equal keys are written
+// in the order of their sources.
+//
+// All sources must have the same schema. The codec and metadata of the first source are kept.
+func Merge(dst io.Writer, srcs []io.Reader, opts ...SortFunc) error {
+	if len(srcs) == 0 {
+		return errors.New("ocf: no sources to merge")
+	}
+	cfg, err := newSortConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	readers := make([]*recordReader, len(srcs))
+	for i, src := range srcs {
+		r, err := newRecordReader(src)
+		if err != nil {
+			return fmt.Errorf("ocf: source %d: %w", i, err)
+		}
+		if i > 0 && r.dec.schema.Fingerprint() != readers[0].dec.schema.Fingerprint() {
+			return fmt.Errorf("ocf: source %d: schema differs from the first source", i)
+		}
+		readers[i] = r
+	}
+
+	s, err := newSorter(cfg, readers[0].dec.schema)
+	if err != nil {
+		return err
+	}
+	enc, err := newEncoderLike(dst, readers[0].dec)
+	if err != nil {
+		return err
+	}
+	if err = s.merge(enc, readers); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// newEncoderLike returns an encoder writing a container file with the schema, codec and metadata of dec.
+func newEncoderLike(w io.Writer, dec *Decoder) (*Encoder, error) {
+	meta := make(map[string][]byte, len(dec.meta))
+	for k, v := range dec.meta {
+		meta[k] = v
+	}
+	return NewEncoder(string(dec.meta[schemaKey]), w,
+		WithCodec(CodecName(dec.meta[codecKey])),
+		WithMetadata(meta),
+	)
+}
+
+// recordReader reads the Avro encoding of each record of a container file.
+type recordReader struct {
+	dec    *Decoder
+	reader *avro.Reader
+	data   []byte
+	count  int64
+}
+
+func newRecordReader(r io.Reader) (*recordReader, error) {
+	dec, err := NewDecoder(r)
+	if err != nil {
+		return nil, err
+	}
+	return &recordReader{dec: dec, reader: avro.NewReader(nil, 0)}, nil
+}
+
+// next returns the Avro encoding of the next record, or io.EOF if there are no more records.
+// The returned slice is only valid until the next call.
+func (r *recordReader) next() ([]byte, error) {
+	for r.count == 0 {
+		blk := r.dec.next()
+		if blk == nil {
+			return nil, io.EOF
+		}
+		if blk.err != nil {
+			return nil, blk.err
+		}
+		r.data = blk.data
+		r.count = blk.count
+		r.reader.Reset(r.data)
+	}
+
+	start := r.reader.InputOffset()
+	r.reader.SkipNext(r.dec.schema)
+	if err := r.reader.Error; err != nil {
+		// The block holds fewer records than its count, which is not the end of the file.
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("decoder: invalid data: %w", err)
+	}
+	r.count--
+
+	return r.data[start:r.reader.InputOffset()], nil
+}
+
+// recordRun holds records and their keys in memory.
+type recordRun struct {
+	buf     []byte
+	records []runRecord
+}
+
+// runRecord is a record held by a run, its data and key being offsets in the run buffer.
+type runRecord struct {
+	start, end int
+	keyEnd     int
+}
+
+func (r *recordRun) add(data, key []byte) {
+	start := len(r.buf)
+	r.buf = append(r.buf, data...)
+	end := len(r.buf)
+	r.buf = append(r.buf, key...)
+
+	r.records = append(r.records, runRecord{start: start, end: end, keyEnd: len(r.buf)})
+}
+
+func (r *recordRun) data(i int) []byte {
+	rec := r.records[i]
+	return r.buf[rec.start:rec.end]
+}
+
+func (r *recordRun) key(i int) []byte {
+	rec := r.records[i]
+	return r.buf[rec.end:rec.keyEnd]
+}
+
+func (r *recordRun) size() int {
+	return len(r.buf)
+}
+
+func (r *recordRun) reset() {
+	r.buf = r.buf[:0]
+	r.records = r.records[:0]
+}
+
+type sorter struct {
+	cfg    sortConfig
+	schema avro.Schema
+
+	// runs are the files of the spilled runs, temp all temporary files written.
+	runs []string
+	temp []string
+}
+
+func newSorter(cfg sortConfig, schema avro.Schema) (*sorter, error) {
+	if cfg.Schema != nil {
+		if cfg.Schema.Fingerprint() != schema.Fingerprint() {
+			return nil, errors.New("ocf: sort schema does not match the file schema")
+		}
+		schema = cfg.Schema
+	}
+	return &sorter{cfg: cfg, schema: schema}, nil
+}
+
+// key returns the sort key of the record, or nil if records are sorted by themselves.
+func (s *sorter) key(data []byte) ([]byte, error) {
+	if s.cfg.KeyFunc == nil {
+		return nil, nil
+	}
+
+	key, err := s.cfg.KeyFunc(data)
+	if err != nil {
+		return nil, fmt.Errorf("ocf: sort key: %w", err)
+	}
+	return key, nil
+}
+
+// runKey returns the sort key of a record of the run.
+func (s *sorter) runKey(run *recordRun, i int) []byte {
+	if s.cfg.KeyFunc == nil {
+		return run.data(i)
+	}
+	return run.key(i)
+}
+
+func (s *sorter) compare(a, b []byte) (int, error) {
+	schema := s.schema
+	if s.cfg.KeySchema != nil {
+		schema = s.cfg.KeySchema
+	}
+	return avro.Compare(schema, a, b)
+}
+
+func (s *sorter) sortRun(run *recordRun) error {
+	var err error
+	sort.SliceStable(run.records, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+
+		var res int
+		res, err = s.compare(s.runKey(run, i), s.runKey(run, j))
+		return res < 0
+	})
+	return err
+}
+
+// writeRun writes the records of a sorted run to enc, skipping duplicates if needed.
+func (s *sorter) writeRun(enc *Encoder, run *recordRun) error {
+	for i := range run.records {
+		if s.cfg.Dedupe && i > 0 {
+			res, err := s.compare(s.runKey(run, i-1), s.runKey(run, i))
+			if err != nil {
+				return err
+			}
+			if res == 0 {
+				continue
+			}
+		}
+
+		if _, err := enc.Write(run.data(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spill sorts the run and writes it to a temporary file.
+func (s *sorter) spill(run *recordRun, dec *Decoder) error {
+	if len(run.records) == 0 {
+		return nil
+	}
+	if err := s.sortRun(run); err != nil {
+		return err
+	}
+
+	name, err := s.writeTemp(func(f io.Writer) error {
+		enc, err := NewEncoder(string(dec.meta[schemaKey]), f)
+		if err != nil {
+			return err
+		}
+		if err = s.writeRun(enc, run); err != nil {
+			return err
+		}
+		return enc.Close()
+	})
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, name)
+
+	run.reset()
+	return nil
+}
+
+// mergeRuns merges the spilled runs into dst, first merging them into fewer runs if there
+// are too many to merge at once.
+func (s *sorter) mergeRuns(dst io.Writer, dec *Decoder) error {
+	runs := s.runs
+	for len(runs) > maxMergeRuns {
+		var merged []string
+		for i := 0; i < len(runs); i += maxMergeRuns {
+			end := i + maxMergeRuns
+			if end > len(runs) {
+				end = len(runs)
+			}
+			group := runs[i:end]
+
+			name, err := s.writeTemp(func(f io.Writer) error {
+				enc, err := NewEncoder(string(dec.meta[schemaKey]), f)
+				if err != nil {
+					return err
+				}
+				if err = s.mergeFiles(enc, group); err != nil {
+					return err
+				}
+				return enc.Close()
+			})
+			if err != nil {
+				return err
+			}
+			merged = append(merged, name)
+
+			for _, name := range group {
+				_ = os.Remove(name)
+			}
+		}
+		runs = merged
+	}
+
+	enc, err := newEncoderLike(dst, dec)
+	if err != nil {
+		return err
+	}
+	if err = s.mergeFiles(enc, runs); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// writeTemp writes a run to a new temporary file using fn, returning the file name.
+func (s *sorter) writeTemp(fn func(f io.Writer) error) (string, error) {
+	f, err := ioutil.TempFile(s.cfg.TempDir, "ocf-sort-*.avro")
+	if err != nil {
+		return "", err
+	}
+	s.temp = append(s.temp, f.Name())
+
+	if err = fn(f); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+func (s *sorter) mergeFiles(enc *Encoder, names []string) error {
+	readers := make([]*recordReader, 0, len(names))
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		r, err := newRecordReader(f)
+		if err != nil {
+			return err
+		}
+		readers = append(readers, r)
+	}
+
+	return s.merge(enc, readers)
+}
+
+// merge writes the records of the sorted readers to enc in order.
+func (s *sorter) merge(enc *Encoder, readers []*recordReader) error {
+	h := &mergeHeap{sorter: s}
+	for i, r := range readers {
+		item := &mergeItem{reader: r, src: i}
+		if err := s.advance(item); err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return err
+		}
+		h.items = append(h.items, item)
+	}
+	heap.Init(h)
+	if h.err != nil {
+		return h.err
+	}
+
+	var last []byte
+	var hasLast bool
+	for h.Len() > 0 {
+		item := h.items[0]
+
+		write := true
+		if s.cfg.Dedupe && hasLast {
+			res, err := s.compare(last, item.key)
+			if err != nil {
+				return err
+			}
+			write = res != 0
+		}
+		if write {
+			if _, err := enc.Write(item.data); err != nil {
+				return err
+			}
+			if s.cfg.Dedupe {
+				last = append(last[:0], item.key...)
+				hasLast = true
+			}
+		}
+
+		if err := s.advance(item); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			heap.Pop(h)
+		} else {
+			heap.Fix(h, 0)
+		}
+		if h.err != nil {
+			return h.err
+		}
+	}
+	return nil
+}
+
+// advance reads the next record of the item reader.
+func (s *sorter) advance(item *mergeItem) error {
+	data, err := item.reader.next()
+	if err != nil {
+		return err
+	}
+
+	item.data = append(item.data[:0], data...)
+	key, err := s.key(item.data)
+	if err != nil {
+		return err
+	}
+	if s.cfg.KeyFunc == nil {
+		item.key = item.data
+		return nil
+	}
+	item.key = append(item.key[:0], key...)
+	return nil
+}
+
+type mergeItem struct {
+	reader *recordReader
+	src    int
+	data   []byte
+	key    []byte
+}
+
+// mergeHeap orders the current records of the merged readers, keeping the first comparison error.
+type mergeHeap struct {
+	sorter *sorter
+	items  []*mergeItem
+	err    error
+}
+
+func (h *mergeHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *mergeHeap) Less(i, j int) bool {
+	res, err := h.sorter.compare(h.items[i].key, h.items[j].key)
+	if err != nil && h.err == nil {
+		h.err = err
+	}
+	if res != 0 {
+		return res < 0
+	}
+	return h.items[i].src < h.items[j].src
+}
+
+func (h *mergeHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *mergeHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(*mergeItem))
+}
+
+func (h *mergeHeap) Pop() interface{} {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+// cleanup removes the temporary files.
+func (s *sorter) cleanup() {
+	for _, name := range s.temp {
+		_ = os.Remove(name)
+	}
+	s.runs, s.temp = nil, nil
+}
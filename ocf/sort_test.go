@@ -0,0 +1,294 @@
+package ocf_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/ocf"
+)
+
+const sortSchema = `{
+	"type": "record",
+	"name": "test",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int", "order": "descending"},
+		{"name": "seq", "type": "int", "order": "ignore"}
+	]
+}`
+
+type SortRecord struct {
+	Name string `avro:"name"`
+	Age  int    `avro:"age"`
+	Seq  int    `avro:"seq"`
+}
+
+func sortTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "ocf-sort-test")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func writeSortFile(t *testing.T, recs []SortRecord, opts ...ocf.EncoderFunc) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	enc, err := ocf.NewEncoder(sortSchema, buf, opts...)
+	require.NoError(t, err)
+	for _, rec := range recs {
+		require.NoError(t, enc.Encode(rec))
+	}
+	require.NoError(t, enc.Close())
+	return buf
+}
+
+func readSortFile(t *testing.T, r io.Reader) ([]SortRecord, map[string][]byte) {
+	t.Helper()
+
+	dec, err := ocf.NewDecoder(r)
+	require.NoError(t, err)
+
+	var recs []SortRecord
+	for dec.HasNext() {
+		var rec SortRecord
+		require.NoError(t, dec.Decode(&rec))
+		recs = append(recs, rec)
+	}
+	require.NoError(t, dec.Error())
+	return recs, dec.Metadata()
+}
+
+func TestSort_FileSchemaOrder(t *testing.T) {
+	src := writeSortFile(t, []SortRecord{
+		{Name: "b", Age: 1, Seq: 1},
+		{Name: "a", Age: 2, Seq: 2},
+		{Name: "a", Age: 1, Seq: 3},
+		{Name: "a", Age: 1, Seq: 0},
+	})
+	dst := &bytes.Buffer{}
+
+	err := ocf.Sort(dst, src)
+
+	require.NoError(t, err)
+	got, _ := readSortFile(t, dst)
+	assert.Equal(t, []SortRecord{
+		{Name: "a", Age: 2, Seq: 2},
+		{Name: "a", Age: 1, Seq: 3},
+		{Name: "a", Age: 1, Seq: 0},
+		{Name: "b", Age: 1, Seq: 1},
+	}, got)
+}
+
+func TestSort(t *testing.T) {
+	src := writeSortFile(t, []SortRecord{
+		{Name: "b", Age: 1, Seq: 0},
+		{Name: "a", Age: 1, Seq: 1},
+		{Name: "a", Age: 2, Seq: 2},
+		{Name: "b", Age: 1, Seq: 3},
+	}, ocf.WithCodec(ocf.Deflate), ocf.WithMetadata(map[string][]byte{"key": []byte("value")}))
+	dst := &bytes.Buffer{}
+
+	err := ocf.Sort(dst, src, ocf.WithSortSchema(avro.MustParse(sortSchema)))
+
+	require.NoError(t, err)
+	got, meta := readSortFile(t, dst)
+	assert.Equal(t, []SortRecord{
+		{Name: "a", Age: 2, Seq: 2},
+		{Name: "a", Age: 1, Seq: 1},
+		{Name: "b", Age: 1, Seq: 0},
+		{Name: "b", Age: 1, Seq: 3},
+	}, got)
+	assert.Equal(t, []byte("deflate"), meta["avro.codec"])
+	assert.Equal(t, []byte("value"), meta["key"])
+}
+
+func TestSort_SpillsRuns(t *testing.T) {
+	dir := sortTempDir(t)
+
+	// Enough single record runs to need more than one merge pass.
+	rng := rand.New(rand.NewSource(1))
+	recs := make([]SortRecord, 300)
+	for i := range recs {
+		recs[i] = SortRecord{Name: string(rune('a' + rng.Intn(26))), Age: rng.Intn(5), Seq: i}
+	}
+	src := writeSortFile(t, recs)
+	dst := &bytes.Buffer{}
+
+	err := ocf.Sort(dst, src,
+		ocf.WithSortSchema(avro.MustParse(sortSchema)),
+		ocf.WithRunSize(1),
+		ocf.WithTempDir(dir),
+	)
+
+	require.NoError(t, err)
+	got, _ := readSortFile(t, dst)
+	require.Len(t, got, len(recs))
+	for i := 1; i < len(got); i++ {
+		prev, curr := got[i-1], got[i]
+		switch {
+		case prev.Name != curr.Name:
+			assert.Less(t, prev.Name, curr.Name)
+		case prev.Age != curr.Age:
+			assert.Greater(t, prev.Age, curr.Age)
+		default:
+			// Equal records keep their order.
+			assert.Less(t, prev.Seq, curr.Seq)
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestSort_WithSortKeyAndDedupe(t *testing.T) {
+	recs := []SortRecord{
+		{Name: "b", Age: 1, Seq: 0},
+		{Name: "a", Age: 2, Seq: 1},
+		{Name: "c", Age: 1, Seq: 2},
+		{Name: "d", Age: 2, Seq: 3},
+		{Name: "e", Age: 3, Seq: 4},
+	}
+	schema := avro.MustParse(sortSchema)
+	keySchema := avro.MustParse(`"int"`)
+	key := func(data []byte) ([]byte, error) {
+		var rec SortRecord
+		if err := avro.Unmarshal(schema, data, &rec); err != nil {
+			return nil, err
+		}
+		return avro.Marshal(keySchema, rec.Age)
+	}
+
+	for _, runSize := range []int{1, 1 << 20} {
+		src := writeSortFile(t, recs)
+		dst := &bytes.Buffer{}
+
+		err := ocf.Sort(dst, src,
+			ocf.WithSortKey(keySchema, key),
+			ocf.WithDedupe(),
+			ocf.WithRunSize(runSize),
+			ocf.WithTempDir(sortTempDir(t)),
+		)
+
+		require.NoError(t, err)
+		got, _ := readSortFile(t, dst)
+		assert.Equal(t, []SortRecord{
+			{Name: "b", Age: 1, Seq: 0},
+			{Name: "a", Age: 2, Seq: 1},
+			{Name: "e", Age: 3, Seq: 4},
+		}, got)
+	}
+}
+
+func TestSort_SortSchemaMismatch(t *testing.T) {
+	src := writeSortFile(t, []SortRecord{{Name: "a"}})
+
+	err := ocf.Sort(&bytes.Buffer{}, src, ocf.WithSortSchema(avro.MustParse(`"string"`)))
+
+	assert.Error(t, err)
+}
+
+func TestSort_SortKeyError(t *testing.T) {
+	src := writeSortFile(t, []SortRecord{{Name: "a"}})
+
+	err := ocf.Sort(&bytes.Buffer{}, src, ocf.WithSortKey(avro.MustParse(`"int"`), func([]byte) ([]byte, error) {
+		return nil, errors.New("test")
+	}))
+
+	assert.Error(t, err)
+}
+
+func TestSort_InvalidRunSize(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		src := writeSortFile(t, []SortRecord{{Name: "a", Age: 1, Seq: 0}})
+
+		err := ocf.Sort(&bytes.Buffer{}, src, ocf.WithRunSize(size))
+
+		assert.Error(t, err)
+	}
+}
+
+func TestSort_InvalidFile(t *testing.T) {
+	err := ocf.Sort(&bytes.Buffer{}, bytes.NewReader([]byte("not an avro file")))
+
+	assert.Error(t, err)
+}
+
+func TestSort_ShortBlock(t *testing.T) {
+	src := writeSortFile(t, []SortRecord{{Name: "a", Age: 1, Seq: 0}}).Bytes()
+	// The block following the header claims to hold two records instead of one.
+	sync := src[len(src)-16:]
+	blk := bytes.Index(src, sync) + len(sync)
+	require.Equal(t, byte(0x02), src[blk])
+	src[blk] = 0x04
+
+	err := ocf.Sort(&bytes.Buffer{}, bytes.NewReader(src))
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestMerge(t *testing.T) {
+	srcA := writeSortFile(t, []SortRecord{
+		{Name: "a", Age: 1, Seq: 0},
+		{Name: "c", Age: 1, Seq: 1},
+	}, ocf.WithCodec(ocf.Snappy))
+	srcB := writeSortFile(t, []SortRecord{
+		{Name: "a", Age: 1, Seq: 2},
+		{Name: "b", Age: 1, Seq: 3},
+	})
+	dst := &bytes.Buffer{}
+
+	err := ocf.Merge(dst, []io.Reader{srcA, srcB})
+
+	require.NoError(t, err)
+	got, meta := readSortFile(t, dst)
+	assert.Equal(t, []SortRecord{
+		{Name: "a", Age: 1, Seq: 0},
+		{Name: "a", Age: 1, Seq: 2},
+		{Name: "b", Age: 1, Seq: 3},
+		{Name: "c", Age: 1, Seq: 1},
+	}, got)
+	assert.Equal(t, []byte("snappy"), meta["avro.codec"])
+}
+
+func TestMerge_WithDedupe(t *testing.T) {
+	srcA := writeSortFile(t, []SortRecord{{Name: "a", Age: 1, Seq: 0}})
+	srcB := writeSortFile(t, []SortRecord{{Name: "a", Age: 1, Seq: 1}, {Name: "b", Age: 1, Seq: 2}})
+	dst := &bytes.Buffer{}
+
+	err := ocf.Merge(dst, []io.Reader{srcA, srcB}, ocf.WithSortSchema(avro.MustParse(sortSchema)), ocf.WithDedupe())
+
+	require.NoError(t, err)
+	got, _ := readSortFile(t, dst)
+	assert.Equal(t, []SortRecord{{Name: "a", Age: 1, Seq: 0}, {Name: "b", Age: 1, Seq: 2}}, got)
+}
+
+func TestMerge_DifferentSchemas(t *testing.T) {
+	srcA := writeSortFile(t, []SortRecord{{Name: "a"}})
+	srcB := &bytes.Buffer{}
+	enc, err := ocf.NewEncoder(`"string"`, srcB)
+	require.NoError(t, err)
+	require.NoError(t, enc.Close())
+
+	err = ocf.Merge(&bytes.Buffer{}, []io.Reader{srcA, srcB})
+
+	assert.Error(t, err)
+}
+
+func TestMerge_NoSources(t *testing.T) {
+	err := ocf.Merge(&bytes.Buffer{}, nil)
+
+	assert.Error(t, err)
+}
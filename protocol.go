@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
+	"sort"
 
 	jsoniter "github.com/json-iterator/go"
 )
@@ -14,6 +15,28 @@ var (
 	messageReserved  = []string{"doc", "response", "request", "errors", "one-way"}
 )
 
+// ProtocolFunc represents an configuration function for a protocol or message.
+type ProtocolFunc func(cfg *protocolConfig)
+
+type protocolConfig struct {
+	doc string
+}
+
+// WithProtoDoc sets the documentation of a protocol or message.
+func WithProtoDoc(doc string) ProtocolFunc {
+	return func(cfg *protocolConfig) {
+		cfg.doc = doc
+	}
+}
+
+func newProtocolConfig(opts []ProtocolFunc) protocolConfig {
+	var cfg protocolConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
 // Protocol is an Avro protocol.
 type Protocol struct {
 	name
@@ -21,22 +44,26 @@ type Protocol struct {
 
 	types    []NamedSchema
 	messages map[string]*Message
+	doc      string
 
 	hash string
 }
 
 // NewProtocol creates a protocol instance.
-func NewProtocol(name, space string, types []NamedSchema, messages map[string]*Message) (*Protocol, error) {
+func NewProtocol(name, space string, types []NamedSchema, messages map[string]*Message, opts ...ProtocolFunc) (*Protocol, error) {
 	n, err := newName(name, space)
 	if err != nil {
 		return nil, err
 	}
 
+	cfg := newProtocolConfig(opts)
+
 	p := &Protocol{
 		name:       n,
 		properties: properties{reserved: protocolReserved},
 		types:      types,
 		messages:   messages,
+		doc:        cfg.doc,
 	}
 
 	b := md5.Sum([]byte(p.String()))
@@ -45,11 +72,26 @@ func NewProtocol(name, space string, types []NamedSchema, messages map[string]*M
 	return p, nil
 }
 
+// Types returns the named types of the protocol.
+func (p *Protocol) Types() []NamedSchema {
+	return p.types
+}
+
+// Messages returns the messages of the protocol by name.
+func (p *Protocol) Messages() map[string]*Message {
+	return p.messages
+}
+
 // Message returns a message with the given name or nil.
 func (p *Protocol) Message(name string) *Message {
 	return p.messages[name]
 }
 
+// Doc returns the documentation of the protocol.
+func (p *Protocol) Doc() string {
+	return p.doc
+}
+
 // Hash returns the MD5 hash of the protocol.
 func (p *Protocol) Hash() string {
 	return p.hash
@@ -65,9 +107,15 @@ func (p *Protocol) String() string {
 		types = types[:len(types)-1]
 	}
 
+	names := make([]string, 0, len(p.messages))
+	for k := range p.messages {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
 	messages := ""
-	for k, m := range p.messages {
-		messages += `"` + k + `":` + m.String() + ","
+	for _, k := range names {
+		messages += `"` + k + `":` + p.messages[k].String() + ","
 	}
 	if len(messages) > 0 {
 		messages = messages[:len(messages)-1]
@@ -86,16 +134,20 @@ type Message struct {
 	resp   Schema
 	errs   *UnionSchema
 	oneWay bool
+	doc    string
 }
 
 // NewMessage creates a protocol message instance.
-func NewMessage(req *RecordSchema, resp Schema, errors *UnionSchema, oneWay bool) *Message {
+func NewMessage(req *RecordSchema, resp Schema, errors *UnionSchema, oneWay bool, opts ...ProtocolFunc) *Message {
+	cfg := newProtocolConfig(opts)
+
 	return &Message{
 		properties: properties{reserved: messageReserved},
 		req:        req,
 		resp:       resp,
 		errs:       errors,
 		oneWay:     oneWay,
+		doc:        cfg.doc,
 	}
 }
 
@@ -114,6 +166,11 @@ func (m *Message) Errors() *UnionSchema {
 	return m.errs
 }
 
+// Doc returns the documentation of the message.
+func (m *Message) Doc() string {
+	return m.doc
+}
+
 // OneWay determines of the message is a one way message.
 func (m *Message) OneWay() bool {
 	return m.oneWay
@@ -166,13 +223,17 @@ func MustParseProtocol(protocol string) *Protocol {
 
 // ParseProtocol parses an Avro protocol.
 func ParseProtocol(protocol string) (*Protocol, error) {
-	cache := &SchemaCache{}
-
 	var m map[string]interface{}
 	if err := jsoniter.Unmarshal([]byte(protocol), &m); err != nil {
 		return nil, err
 	}
 
+	return parseProtocol(m)
+}
+
+func parseProtocol(m map[string]interface{}) (*Protocol, error) {
+	cache := &SchemaCache{}
+
 	name, err := resolveProtocolName(m)
 	if err != nil {
 		return nil, err
@@ -203,7 +264,12 @@ func ParseProtocol(protocol string) (*Protocol, error) {
 		}
 	}
 
-	proto, _ := NewProtocol(name.name, name.space, types, messages)
+	var opts []ProtocolFunc
+	if doc, ok := m["doc"].(string); ok {
+		opts = append(opts, WithProtoDoc(doc))
+	}
+
+	proto, _ := NewProtocol(name.name, name.space, types, messages, opts...)
 
 	for k, v := range m {
 		proto.AddProp(k, v)
@@ -272,7 +338,11 @@ func parseMessage(namespace string, m map[string]interface{}, cache *SchemaCache
 				return nil, err
 			}
 
-			if rec, ok := schema.(*RecordSchema); ok && !rec.IsError() {
+			errSchema := schema
+			if ref, ok := schema.(*RefSchema); ok {
+				errSchema = ref.Schema()
+			}
+			if rec, ok := errSchema.(*RecordSchema); ok && !rec.IsError() {
 				return nil, errors.New("avro: errors record schema must be of type error")
 			}
 
@@ -296,7 +366,12 @@ func parseMessage(namespace string, m map[string]interface{}, cache *SchemaCache
 		oneWay = true
 	}
 
-	msg := NewMessage(request, response, errs, oneWay)
+	var opts []ProtocolFunc
+	if doc, ok := m["doc"].(string); ok {
+		opts = append(opts, WithProtoDoc(doc))
+	}
+
+	msg := NewMessage(request, response, errs, oneWay, opts...)
 
 	for k, v := range m {
 		msg.AddProp(k, v)
@@ -19,6 +19,17 @@ func TestMustParseProtocol_PanicsOnError(t *testing.T) {
 	})
 }
 
+func TestParseProtocol_Doc(t *testing.T) {
+	proto := avro.MustParseProtocol(`{
+		"protocol": "test",
+		"doc": "protocol docs",
+		"messages": {"hello": {"doc": "message docs", "request": [], "response": "string"}}
+	}`)
+
+	assert.Equal(t, "protocol docs", proto.Doc())
+	assert.Equal(t, "message docs", proto.Message("hello").Doc())
+}
+
 func TestNewProtocol_ValidatesName(t *testing.T) {
 	_, err := avro.NewProtocol("0test", "", nil, nil)
 
@@ -180,3 +191,28 @@ func TestParseProtocolFile_InvalidPath(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestProtocol_StringSortsMessages(t *testing.T) {
+	schema := `{"protocol":"test","namespace":"org.hamba.avro","messages":{"b":{"request":[]},"c":{"request":[]},"a":{"request":[]}}}`
+
+	proto, err := avro.ParseProtocol(schema)
+
+	assert.NoError(t, err)
+	want := `{"protocol":"test","namespace":"org.hamba.avro","types":[],"messages":{"a":{"request":[]},"b":{"request":[]},"c":{"request":[]}}}`
+	assert.Equal(t, want, proto.String())
+}
+
+func TestProtocol_TypesAndMessages(t *testing.T) {
+	protocol, err := avro.ParseProtocolFile("testdata/echo.avpr")
+	assert.NoError(t, err)
+
+	types := protocol.Types()
+	if assert.Len(t, types, 3) {
+		assert.Equal(t, "org.hamba.avro.Ping", types[0].FullName())
+		assert.Equal(t, "org.hamba.avro.PongError", types[2].FullName())
+	}
+
+	msgs := protocol.Messages()
+	assert.Len(t, msgs, 1)
+	assert.Same(t, protocol.Message("ping"), msgs["ping"])
+}
@@ -29,6 +29,7 @@ type Reader struct {
 	buf    []byte
 	head   int
 	tail   int
+	offset int64
 	Error  error
 }
 
@@ -55,10 +56,16 @@ func (r *Reader) Reset(b []byte) *Reader {
 	r.buf = b
 	r.head = 0
 	r.tail = len(b)
+	r.offset = 0
 
 	return r
 }
 
+// InputOffset returns the number of bytes consumed from the input so far.
+func (r *Reader) InputOffset() int64 {
+	return r.offset + int64(r.head)
+}
+
 // ReportError record a error in iterator instance with current position.
 func (r *Reader) ReportError(operation, msg string) {
 	if r.Error != nil && !errors.Is(r.Error, io.EOF) {
@@ -92,6 +99,7 @@ func (r *Reader) loadMore() bool {
 			continue
 		}
 
+		r.offset += int64(r.tail)
 		r.head = 0
 		r.tail = n
 		return true
@@ -65,6 +65,10 @@ func (r *Reader) ReadNext(schema Schema) interface{} {
 		obj := make(map[string]interface{}, len(fields))
 		for _, field := range fields {
 			obj[field.Name()] = r.ReadNext(field.Type())
+			if decodeFailed(r) {
+				wrapDecodeError(r, fieldPath(field.Name()))
+				break
+			}
 		}
 		return obj
 
@@ -83,7 +87,14 @@ func (r *Reader) ReadNext(schema Schema) interface{} {
 	case Array:
 		arr := []interface{}{}
 		r.ReadArrayCB(func(r *Reader) bool {
+			if decodeFailed(r) {
+				return false
+			}
 			elem := r.ReadNext(schema.(*ArraySchema).Items())
+			if decodeFailed(r) {
+				wrapDecodeError(r, indexPath(len(arr)))
+				return false
+			}
 			arr = append(arr, elem)
 			return true
 		})
@@ -92,7 +103,14 @@ func (r *Reader) ReadNext(schema Schema) interface{} {
 	case Map:
 		obj := map[string]interface{}{}
 		r.ReadMapCB(func(r *Reader, field string) bool {
+			if decodeFailed(r) {
+				return false
+			}
 			elem := r.ReadNext(schema.(*MapSchema).Values())
+			if decodeFailed(r) {
+				wrapDecodeError(r, keyPath(field))
+				return false
+			}
 			obj[field] = elem
 			return true
 		})
@@ -113,6 +131,9 @@ func (r *Reader) ReadNext(schema Schema) interface{} {
 		key := schemaTypeName(schema)
 		obj := map[string]interface{}{}
 		obj[key] = r.ReadNext(types[idx])
+		if decodeFailed(r) {
+			wrapDecodeError(r, branchPath(schema))
+		}
 
 		return obj
 
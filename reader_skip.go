@@ -92,3 +92,15 @@ func (r *Reader) SkipBytes() {
 
 	r.SkipNBytes(int(size))
 }
+
+// SkipNext skips the next value of the schema in the reader.
+func (r *Reader) SkipNext(schema Schema) {
+	// Skip decoders are not tied to a type, so they are cached without one.
+	decoder := r.cfg.getDecoderFromCache(schema.Fingerprint(), 0)
+	if decoder == nil {
+		decoder = createSkipDecoder(schema)
+		r.cfg.addDecoderToCache(schema.Fingerprint(), 0, decoder)
+	}
+
+	decoder.Decode(nil, r)
+}
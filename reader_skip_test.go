@@ -152,3 +152,14 @@ func TestReader_SkipBytesEmpty(t *testing.T) {
 	assert.NoError(t, r.Error)
 	assert.Equal(t, int32(27), r.ReadInt())
 }
+
+func TestReader_SkipNext(t *testing.T) {
+	schema := avro.MustParse(`{"type":"record","name":"test","fields":[{"name":"a","type":"string"},{"name":"b","type":{"type":"array","items":"int"}}]}`)
+	data := []byte{0x06, 0x66, 0x6F, 0x6F, 0x04, 0x02, 0x04, 0x00, 0x36}
+	r := avro.NewReader(bytes.NewReader(data), 10)
+
+	r.SkipNext(schema)
+
+	assert.NoError(t, r.Error)
+	assert.Equal(t, int32(27), r.ReadInt())
+}
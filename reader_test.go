@@ -23,6 +23,20 @@ func TestReader_Reset(t *testing.T) {
 	assert.True(t, r.ReadBool())
 }
 
+func TestReader_InputOffset(t *testing.T) {
+	r := avro.NewReader(bytes.NewReader([]byte{0x36, 0x06, 'f', 'o', 'o', 0x01}), 2)
+
+	_ = r.ReadInt()
+	assert.Equal(t, int64(1), r.InputOffset())
+
+	_ = r.ReadString()
+	assert.Equal(t, int64(5), r.InputOffset())
+
+	_ = r.ReadBool()
+	assert.NoError(t, r.Error)
+	assert.Equal(t, int64(6), r.InputOffset())
+}
+
 func TestReader_ReportError(t *testing.T) {
 	r := &avro.Reader{}
 
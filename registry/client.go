@@ -9,12 +9,18 @@ package registry
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -31,63 +37,129 @@ type Registry interface {
 	// GetSchema returns the schema with the given id.
 	GetSchema(id int) (avro.Schema, error)
 
+	// GetSchemaContext returns the schema with the given id.
+	GetSchemaContext(ctx context.Context, id int) (avro.Schema, error)
+
 	// GetSubjects gets the registry subjects.
 	GetSubjects() ([]string, error)
 
+	// GetSubjectsContext gets the registry subjects.
+	GetSubjectsContext(ctx context.Context) ([]string, error)
+
 	// GetVersions gets the schema versions for a subject.
 	GetVersions(subject string) ([]int, error)
 
+	// GetVersionsContext gets the schema versions for a subject.
+	GetVersionsContext(ctx context.Context, subject string) ([]int, error)
+
 	// GetSchemaByVersion gets the schema by version.
 	GetSchemaByVersion(subject string, version int) (avro.Schema, error)
 
+	// GetSchemaByVersionContext gets the schema by version.
+	GetSchemaByVersionContext(ctx context.Context, subject string, version int) (avro.Schema, error)
+
 	// GetLatestSchema gets the latest schema for a subject.
 	GetLatestSchema(subject string) (avro.Schema, error)
 
+	// GetLatestSchemaContext gets the latest schema for a subject.
+	GetLatestSchemaContext(ctx context.Context, subject string) (avro.Schema, error)
+
 	// GetLatestSchemaInfo gets the latest schema and schema metadata for a subject.
 	GetLatestSchemaInfo(subject string) (SchemaInfo, error)
 
+	// GetLatestSchemaInfoContext gets the latest schema and schema metadata for a subject.
+	GetLatestSchemaInfoContext(ctx context.Context, subject string) (SchemaInfo, error)
+
 	// CreateSchema creates a schema in the registry, returning the schema id.
-	CreateSchema(subject, schema string) (int, avro.Schema, error)
+	CreateSchema(subject, schema string, references ...Reference) (int, avro.Schema, error)
+
+	// CreateSchemaContext creates a schema in the registry, returning the schema id.
+	CreateSchemaContext(ctx context.Context, subject, schema string, references ...Reference) (int, avro.Schema, error)
 
 	// IsRegistered determines of the schema is registered.
-	IsRegistered(subject, schema string) (int, avro.Schema, error)
+	IsRegistered(subject, schema string, references ...Reference) (int, avro.Schema, error)
+
+	// IsRegisteredContext determines of the schema is registered.
+	IsRegisteredContext(ctx context.Context, subject, schema string, references ...Reference) (int, avro.Schema, error)
+}
+
+// Reference is a reference from a schema to a schema registered under another
+// subject, declaring named types used by the referencing schema.
+type Reference struct {
+	// Name is the name the schema is referenced by, the full name of the named type.
+	Name string `json:"name"`
+
+	// Subject is the subject the referenced schema is registered under.
+	Subject string `json:"subject"`
+
+	// Version is the version of the referenced schema.
+	Version int `json:"version"`
 }
 
 type schemaPayload struct {
-	Schema string `json:"schema"`
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references,omitempty"`
 }
 
 type idPayload struct {
 	ID int `json:"id"`
 }
 
+type importPayload struct {
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references,omitempty"`
+	ID         int         `json:"id"`
+	Version    int         `json:"version"`
+}
+
+type modePayload struct {
+	Mode Mode `json:"mode"`
+}
+
+type versionPayload struct {
+	Version int `json:"version"`
+}
+
 type credentials struct {
 	username string
 	password string
 }
 
 type schemaInfoPayload struct {
-	Schema  string `json:"schema"`
-	ID      int    `json:"id"`
-	Version int    `json:"version"`
+	Schema     string      `json:"schema"`
+	ID         int         `json:"id"`
+	Version    int         `json:"version"`
+	References []Reference `json:"references,omitempty"`
 }
 
-// Parse converts the string schema registry response into a
-// SchemaInfo object with an avro.Schema schema.
-func (s *schemaInfoPayload) Parse() (info SchemaInfo, err error) {
-	info = SchemaInfo{
-		ID:      s.ID,
-		Version: s.Version,
-	}
-	info.Schema, err = avro.Parse(s.Schema)
-	return info, err
+// SubjectVersion is a version of a subject a schema is registered as.
+type SubjectVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
 }
 
+// Mode is the mode of a registry or a subject, determining the changes it allows.
+type Mode string
+
+// Registry modes.
+const (
+	// ModeReadWrite allows schemas to be registered and deleted.
+	ModeReadWrite Mode = "READWRITE"
+
+	// ModeReadOnly rejects all changes.
+	ModeReadOnly Mode = "READONLY"
+
+	// ModeImport allows schemas to be registered with an explicit id and version,
+	// without checking their compatibility.
+	ModeImport Mode = "IMPORT"
+)
+
 // SchemaInfo represents a schema and metadata information.
 type SchemaInfo struct {
-	Schema  avro.Schema
-	ID      int
-	Version int
+	Schema     avro.Schema
+	ID         int
+	Version    int
+	References []Reference
 }
 
 var defaultClient = &http.Client{
@@ -118,51 +190,136 @@ func WithBasicAuth(username, password string) ClientFunc {
 	}
 }
 
+// WithBearerToken sets the token to perform http bearer auth.
+func WithBearerToken(token string) ClientFunc {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHeader sets a header sent with every request, such as an API key.
+func WithHeader(key, value string) ClientFunc {
+	return func(c *Client) {
+		c.headers.Set(key, value)
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the http client, such as the
+// client certificates for mutual TLS and the trusted certificate authorities.
+//
+// The transport of the http client is copied, so the http client given to
+// WithHTTPClient is not modified. A transport that is not an *http.Transport
+// is replaced.
+func WithTLSConfig(cfg *tls.Config) ClientFunc {
+	return func(c *Client) {
+		c.tlsConfig = cfg
+	}
+}
+
+// WithRetries sets the number of times idempotent requests are retried after
+// a network error or a server error response.
+//
+// The wait before a retry starts at minBackoff and doubles after each retry,
+// up to maxBackoff. A random jitter of up to half the wait is applied.
+func WithRetries(retries int, minBackoff, maxBackoff time.Duration) ClientFunc {
+	return func(c *Client) {
+		c.retries = retries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
 // Client is an HTTP registry client.
 type Client struct {
 	client *http.Client
-	base   string
+	bases  []string
+
+	// current is the index of the base url requests are sent to first.
+	current int32
+
+	creds     credentials
+	token     string
+	headers   http.Header
+	tlsConfig *tls.Config
 
-	creds credentials
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
 
 	cache *concurrent.Map // map[int]avro.Schema
+	refs  *concurrent.Map // map[Reference]schemaInfoPayload
 }
 
 // NewClient creates a schema registry Client with the given base url.
+//
+// The base url may hold a comma separated list of urls, in which case requests
+// fail over to the next url when a registry cannot be reached or returns a
+// server error.
 func NewClient(baseURL string, opts ...ClientFunc) (*Client, error) {
-	if _, err := url.Parse(baseURL); err != nil {
-		return nil, err
+	var bases []string
+	for _, base := range strings.Split(baseURL, ",") {
+		base = strings.TrimSpace(base)
+		if _, err := url.Parse(base); err != nil {
+			return nil, err
+		}
+		bases = append(bases, strings.TrimSuffix(base, "/"))
 	}
-	baseURL = strings.TrimSuffix(baseURL, "/")
 
 	c := &Client{
-		client: defaultClient,
-		base:   baseURL,
-		cache:  concurrent.NewMap(),
+		client:  defaultClient,
+		bases:   bases,
+		headers: http.Header{},
+		cache:   concurrent.NewMap(),
+		refs:    concurrent.NewMap(),
 	}
 
 	for _, opt := range opts {
 		opt(c)
 	}
 
+	if c.tlsConfig != nil {
+		c.client = withTLSConfig(c.client, c.tlsConfig)
+	}
+
 	return c, nil
 }
 
+func withTLSConfig(client *http.Client, cfg *tls.Config) *http.Client {
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		transport = defaultClient.Transport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = cfg
+
+	newClient := *client
+	newClient.Transport = transport
+	return &newClient
+}
+
 // GetSchema returns the schema with the given id.
 //
 // GetSchema will cache the schema in memory after it is successfully returned,
 // allowing it to be used efficiently in a high load situation.
 func (c *Client) GetSchema(id int) (avro.Schema, error) {
+	return c.GetSchemaContext(context.Background(), id)
+}
+
+// GetSchemaContext returns the schema with the given id.
+//
+// GetSchemaContext will cache the schema in memory after it is successfully returned,
+// allowing it to be used efficiently in a high load situation.
+func (c *Client) GetSchemaContext(ctx context.Context, id int) (avro.Schema, error) {
 	if schema, ok := c.cache.Load(id); ok {
 		return schema.(avro.Schema), nil
 	}
 
 	var payload schemaPayload
-	if err := c.request(http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &payload); err != nil {
+	if err := c.request(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &payload); err != nil {
 		return nil, err
 	}
 
-	schema, err := avro.Parse(payload.Schema)
+	schema, err := c.parse(ctx, payload.Schema, payload.References)
 	if err != nil {
 		return nil, err
 	}
@@ -174,8 +331,13 @@ func (c *Client) GetSchema(id int) (avro.Schema, error) {
 
 // GetSubjects gets the registry subjects.
 func (c *Client) GetSubjects() ([]string, error) {
+	return c.GetSubjectsContext(context.Background())
+}
+
+// GetSubjectsContext gets the registry subjects.
+func (c *Client) GetSubjectsContext(ctx context.Context) ([]string, error) {
 	var subjects []string
-	err := c.request(http.MethodGet, "/subjects", nil, &subjects)
+	err := c.request(ctx, http.MethodGet, "/subjects", nil, &subjects)
 	if err != nil {
 		return nil, err
 	}
@@ -185,8 +347,13 @@ func (c *Client) GetSubjects() ([]string, error) {
 
 // GetVersions gets the schema versions for a subject.
 func (c *Client) GetVersions(subject string) ([]int, error) {
+	return c.GetVersionsContext(context.Background(), subject)
+}
+
+// GetVersionsContext gets the schema versions for a subject.
+func (c *Client) GetVersionsContext(ctx context.Context, subject string) ([]int, error) {
 	var versions []int
-	err := c.request(http.MethodGet, "/subjects/"+subject+"/versions", nil, &versions)
+	err := c.request(ctx, http.MethodGet, "/subjects/"+subject+"/versions", nil, &versions)
 	if err != nil {
 		return nil, err
 	}
@@ -196,74 +363,408 @@ func (c *Client) GetVersions(subject string) ([]int, error) {
 
 // GetSchemaByVersion gets the schema by version.
 func (c *Client) GetSchemaByVersion(subject string, version int) (avro.Schema, error) {
+	return c.GetSchemaByVersionContext(context.Background(), subject, version)
+}
+
+// GetSchemaByVersionContext gets the schema by version.
+func (c *Client) GetSchemaByVersionContext(ctx context.Context, subject string, version int) (avro.Schema, error) {
 	var payload schemaPayload
-	err := c.request(http.MethodGet, "/subjects/"+subject+"/versions/"+strconv.Itoa(version), nil, &payload)
+	err := c.request(ctx, http.MethodGet, "/subjects/"+subject+"/versions/"+strconv.Itoa(version), nil, &payload)
 	if err != nil {
 		return nil, err
 	}
 
-	return avro.Parse(payload.Schema)
+	return c.parse(ctx, payload.Schema, payload.References)
 }
 
 // GetLatestSchema gets the latest schema for a subject.
 func (c *Client) GetLatestSchema(subject string) (avro.Schema, error) {
+	return c.GetLatestSchemaContext(context.Background(), subject)
+}
+
+// GetLatestSchemaContext gets the latest schema for a subject.
+func (c *Client) GetLatestSchemaContext(ctx context.Context, subject string) (avro.Schema, error) {
 	var payload schemaPayload
-	err := c.request(http.MethodGet, "/subjects/"+subject+"/versions/latest", nil, &payload)
+	err := c.request(ctx, http.MethodGet, "/subjects/"+subject+"/versions/latest", nil, &payload)
 	if err != nil {
 		return nil, err
 	}
 
-	return avro.Parse(payload.Schema)
+	return c.parse(ctx, payload.Schema, payload.References)
 }
 
 // GetLatestSchemaInfo gets the latest schema and schema metadata for a subject.
 func (c *Client) GetLatestSchemaInfo(subject string) (SchemaInfo, error) {
+	return c.GetLatestSchemaInfoContext(context.Background(), subject)
+}
+
+// GetLatestSchemaInfoContext gets the latest schema and schema metadata for a subject.
+func (c *Client) GetLatestSchemaInfoContext(ctx context.Context, subject string) (SchemaInfo, error) {
 	var payload schemaInfoPayload
-	err := c.request(http.MethodGet, "/subjects/"+subject+"/versions/latest", nil, &payload)
+	err := c.request(ctx, http.MethodGet, "/subjects/"+subject+"/versions/latest", nil, &payload)
 	if err != nil {
 		return SchemaInfo{}, err
 	}
 
-	return payload.Parse()
+	schema, err := c.parse(ctx, payload.Schema, payload.References)
+	if err != nil {
+		return SchemaInfo{}, err
+	}
+
+	return SchemaInfo{
+		Schema:     schema,
+		ID:         payload.ID,
+		Version:    payload.Version,
+		References: payload.References,
+	}, nil
+}
+
+// GetSchemaVersion gets the version of a subject, or its latest version if version is
+// LatestVersion, with the schema as registered.
+func (c *Client) GetSchemaVersion(subject string, version int) (SchemaVersion, error) {
+	return c.GetSchemaVersionContext(context.Background(), subject, version)
+}
+
+// GetSchemaVersionContext gets the version of a subject, or its latest version if version is
+// LatestVersion, with the schema as registered.
+func (c *Client) GetSchemaVersionContext(ctx context.Context, subject string, version int) (SchemaVersion, error) {
+	v := "latest"
+	if version != LatestVersion {
+		v = strconv.Itoa(version)
+	}
+
+	var payload SchemaVersion
+	if err := c.request(ctx, http.MethodGet, "/subjects/"+subject+"/versions/"+v, nil, &payload); err != nil {
+		return SchemaVersion{}, err
+	}
+
+	return payload, nil
 }
 
 // CreateSchema creates a schema in the registry, returning the schema id.
-func (c *Client) CreateSchema(subject, schema string) (int, avro.Schema, error) {
+func (c *Client) CreateSchema(subject, schema string, references ...Reference) (int, avro.Schema, error) {
+	return c.CreateSchemaContext(context.Background(), subject, schema, references...)
+}
+
+// CreateSchemaContext creates a schema in the registry, returning the schema id.
+//
+// The request is not retried, as it is not idempotent.
+func (c *Client) CreateSchemaContext(ctx context.Context, subject, schema string, references ...Reference) (int, avro.Schema, error) {
 	var payload idPayload
-	err := c.request(http.MethodPost, "/subjects/"+subject+"/versions", schemaPayload{Schema: schema}, &payload)
+	in := schemaPayload{Schema: schema, References: references}
+	err := c.request(ctx, http.MethodPost, "/subjects/"+subject+"/versions", in, &payload)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	sch, err := avro.Parse(schema)
+	sch, err := c.parse(ctx, schema, references)
 	return payload.ID, sch, err
 }
 
 // IsRegistered determines of the schema is registered.
-func (c *Client) IsRegistered(subject, schema string) (int, avro.Schema, error) {
+func (c *Client) IsRegistered(subject, schema string, references ...Reference) (int, avro.Schema, error) {
+	return c.IsRegisteredContext(context.Background(), subject, schema, references...)
+}
+
+// IsRegisteredContext determines of the schema is registered.
+func (c *Client) IsRegisteredContext(ctx context.Context, subject, schema string, references ...Reference) (int, avro.Schema, error) {
 	var payload idPayload
-	err := c.request(http.MethodPost, "/subjects/"+subject, schemaPayload{Schema: schema}, &payload)
+	in := schemaPayload{Schema: schema, References: references}
+	// Looking up a schema does not change the registry, so it can be retried.
+	err := c.do(ctx, http.MethodPost, "/subjects/"+subject, in, &payload, true)
 	if err != nil {
 		return 0, nil, err
 	}
 
-	sch, err := avro.Parse(schema)
+	sch, err := c.parse(ctx, schema, references)
 	return payload.ID, sch, err
 }
 
-func (c *Client) request(method, uri string, in, out interface{}) error {
-	var body io.Reader
+// GetSubjectsIncludingDeleted gets the registry subjects, including the soft deleted subjects.
+func (c *Client) GetSubjectsIncludingDeleted() ([]string, error) {
+	return c.GetSubjectsIncludingDeletedContext(context.Background())
+}
+
+// GetSubjectsIncludingDeletedContext gets the registry subjects, including the soft deleted subjects.
+func (c *Client) GetSubjectsIncludingDeletedContext(ctx context.Context) ([]string, error) {
+	var subjects []string
+	err := c.request(ctx, http.MethodGet, "/subjects?deleted=true", nil, &subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	return subjects, err
+}
+
+// GetSubjectsForSchemaID gets the subjects the schema with the given id is registered under.
+func (c *Client) GetSubjectsForSchemaID(id int) ([]string, error) {
+	return c.GetSubjectsForSchemaIDContext(context.Background(), id)
+}
+
+// GetSubjectsForSchemaIDContext gets the subjects the schema with the given id is registered under.
+func (c *Client) GetSubjectsForSchemaIDContext(ctx context.Context, id int) ([]string, error) {
+	var subjects []string
+	err := c.request(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id)+"/subjects", nil, &subjects)
+	if err != nil {
+		return nil, err
+	}
+
+	return subjects, err
+}
+
+// GetVersionsForSchemaID gets the subject versions the schema with the given id is registered as.
+func (c *Client) GetVersionsForSchemaID(id int) ([]SubjectVersion, error) {
+	return c.GetVersionsForSchemaIDContext(context.Background(), id)
+}
+
+// GetVersionsForSchemaIDContext gets the subject versions the schema with the given id is registered as.
+func (c *Client) GetVersionsForSchemaIDContext(ctx context.Context, id int) ([]SubjectVersion, error) {
+	var versions []SubjectVersion
+	err := c.request(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id)+"/versions", nil, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, err
+}
+
+// ImportSchema registers a schema under a subject with the given id and version.
+//
+// The registry or the subject must be in ModeImport.
+func (c *Client) ImportSchema(subject string, id, version int, schema string, references ...Reference) (avro.Schema, error) {
+	return c.ImportSchemaContext(context.Background(), subject, id, version, schema, references...)
+}
+
+// ImportSchemaContext registers a schema under a subject with the given id and version.
+//
+// The registry or the subject must be in ModeImport.
+func (c *Client) ImportSchemaContext(
+	ctx context.Context,
+	subject string,
+	id, version int,
+	schema string,
+	references ...Reference,
+) (avro.Schema, error) {
+	var payload idPayload
+	in := importPayload{Schema: schema, References: references, ID: id, Version: version}
+	// Registering the same schema with the same id and version again has no effect, so it can be retried.
+	err := c.do(ctx, http.MethodPost, "/subjects/"+subject+"/versions", in, &payload, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.parse(ctx, schema, references)
+}
+
+// DeleteSubject deletes the versions of a subject, returning the deleted versions.
+//
+// A subject is soft deleted unless permanent is set, in which case it must have
+// been soft deleted before.
+func (c *Client) DeleteSubject(subject string, permanent bool) ([]int, error) {
+	return c.DeleteSubjectContext(context.Background(), subject, permanent)
+}
+
+// DeleteSubjectContext deletes the versions of a subject, returning the deleted versions.
+//
+// A subject is soft deleted unless permanent is set, in which case it must have
+// been soft deleted before.
+func (c *Client) DeleteSubjectContext(ctx context.Context, subject string, permanent bool) ([]int, error) {
+	var versions []int
+	err := c.request(ctx, http.MethodDelete, "/subjects/"+subject+permanentQuery(permanent), nil, &versions)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, err
+}
+
+// DeleteSchemaVersion deletes a version of a subject.
+//
+// A version is soft deleted unless permanent is set, in which case it must have
+// been soft deleted before.
+func (c *Client) DeleteSchemaVersion(subject string, version int, permanent bool) error {
+	return c.DeleteSchemaVersionContext(context.Background(), subject, version, permanent)
+}
+
+// DeleteSchemaVersionContext deletes a version of a subject.
+//
+// A version is soft deleted unless permanent is set, in which case it must have
+// been soft deleted before.
+func (c *Client) DeleteSchemaVersionContext(ctx context.Context, subject string, version int, permanent bool) error {
+	var payload int
+	uri := "/subjects/" + subject + "/versions/" + strconv.Itoa(version) + permanentQuery(permanent)
+	return c.request(ctx, http.MethodDelete, uri, nil, &payload)
+}
+
+func permanentQuery(permanent bool) string {
+	if permanent {
+		return "?permanent=true"
+	}
+	return ""
+}
+
+// GetMode gets the mode of a subject, or the mode of the registry if the subject is empty.
+func (c *Client) GetMode(subject string) (Mode, error) {
+	return c.GetModeContext(context.Background(), subject)
+}
+
+// GetModeContext gets the mode of a subject, or the mode of the registry if the subject is empty.
+func (c *Client) GetModeContext(ctx context.Context, subject string) (Mode, error) {
+	var payload modePayload
+	if err := c.request(ctx, http.MethodGet, modeURI(subject), nil, &payload); err != nil {
+		return "", err
+	}
+
+	return payload.Mode, nil
+}
+
+// SetMode sets the mode of a subject, or the mode of the registry if the subject is empty.
+func (c *Client) SetMode(subject string, mode Mode) error {
+	return c.SetModeContext(context.Background(), subject, mode)
+}
+
+// SetModeContext sets the mode of a subject, or the mode of the registry if the subject is empty.
+func (c *Client) SetModeContext(ctx context.Context, subject string, mode Mode) error {
+	var payload modePayload
+	// Setting the mode again has no effect, so it can be retried.
+	return c.do(ctx, http.MethodPut, modeURI(subject), modePayload{Mode: mode}, &payload, true)
+}
+
+func modeURI(subject string) string {
+	if subject == "" {
+		return "/mode"
+	}
+	return "/mode/" + subject
+}
+
+// parse parses the schema in its own cache, after fetching and parsing the
+// schemas it references.
+func (c *Client) parse(ctx context.Context, schema string, references []Reference) (avro.Schema, error) {
+	cache := &avro.SchemaCache{}
+	if err := c.resolveReferences(ctx, references, cache, map[Reference]bool{}); err != nil {
+		return nil, err
+	}
+
+	return avro.ParseWithCache(schema, "", cache)
+}
+
+// resolveReferences fetches the referenced schemas and the schemas they reference
+// in turn, parsing them into the cache.
+func (c *Client) resolveReferences(ctx context.Context, references []Reference, cache *avro.SchemaCache, seen map[Reference]bool) error {
+	for _, ref := range references {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		payload, err := c.fetchReference(ctx, ref)
+		if err != nil {
+			return err
+		}
+
+		if err = c.resolveReferences(ctx, payload.References, cache, seen); err != nil {
+			return err
+		}
+
+		if _, err = avro.ParseWithCache(payload.Schema, "", cache); err != nil {
+			return fmt.Errorf("registry: parsing reference %s: %w", ref.Name, err)
+		}
+	}
+	return nil
+}
+
+// fetchReference fetches the referenced schema version.
+func (c *Client) fetchReference(ctx context.Context, ref Reference) (schemaInfoPayload, error) {
+	// A registered version never changes, so it is only fetched once.
+	if payload, ok := c.refs.Load(ref); ok {
+		return payload.(schemaInfoPayload), nil
+	}
+
+	var payload schemaInfoPayload
+	uri := "/subjects/" + ref.Subject + "/versions/" + strconv.Itoa(ref.Version)
+	if err := c.request(ctx, http.MethodGet, uri, nil, &payload); err != nil {
+		return schemaInfoPayload{}, fmt.Errorf("registry: fetching reference %s: %w", ref.Name, err)
+	}
+
+	if ref.Version > 0 {
+		c.refs.Store(ref, payload)
+	}
+	return payload, nil
+}
+
+// request sends a request, retrying it if it is idempotent.
+func (c *Client) request(ctx context.Context, method, uri string, in, out interface{}) error {
+	return c.do(ctx, method, uri, in, out, method == http.MethodGet)
+}
+
+func (c *Client) do(ctx context.Context, method, uri string, in, out interface{}, idempotent bool) error {
+	var body []byte
 	if in != nil {
-		b, _ := jsoniter.Marshal(in)
-		body = bytes.NewReader(b)
+		body, _ = jsoniter.Marshal(in)
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts += c.retries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		var retry bool
+		if retry, err = c.failover(ctx, method, uri, body, out, idempotent); !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// failover sends the request to each base url in turn, starting with the
+// current one, until a request succeeds or fails with an error that should
+// not be retried. It returns if the request may be retried.
+func (c *Client) failover(ctx context.Context, method, uri string, body []byte, out interface{}, idempotent bool) (bool, error) {
+	start := int(atomic.LoadInt32(&c.current))
+
+	var err error
+	for i := range c.bases {
+		idx := (start + i) % len(c.bases)
+
+		err = c.send(ctx, method, c.bases[idx]+uri, body, out)
+		if err == nil {
+			atomic.StoreInt32(&c.current, int32(idx))
+			return false, nil
+		}
+		if !retryable(ctx, err, idempotent) {
+			return false, err
+		}
+	}
+	return true, err
+}
+
+func (c *Client) send(ctx context.Context, method, uri string, body []byte, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
 	}
 
-	req, _ := http.NewRequest(method, c.base+uri, body) // This error is not possible as we already parsed the url
+	req, _ := http.NewRequest(method, uri, r) // This error is not possible as we already parsed the url
+	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", contentType)
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
 
 	if len(c.creds.username) > 0 || len(c.creds.password) > 0 {
 		req.SetBasicAuth(c.creds.username, c.creds.password)
 	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -283,6 +784,77 @@ func (c *Client) request(method, uri string, in, out interface{}) error {
 	return jsoniter.NewDecoder(resp.Body).Decode(out)
 }
 
+// retryable determines if a request failing with err may be retried, or sent to another base url.
+//
+// Requests that are not idempotent are only retried if the registry could not be reached.
+func retryable(ctx context.Context, err error, idempotent bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var regErr Error
+	if errors.As(err, &regErr) {
+		return idempotent && (regErr.StatusCode >= 500 || regErr.StatusCode == http.StatusTooManyRequests)
+	}
+
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		// The response could not be decoded.
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return idempotent
+}
+
+// wait waits before the given retry of a request.
+func (c *Client) wait(ctx context.Context, retry int) error {
+	backoff := c.minBackoff
+	for i := 1; i < retry && backoff < c.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > c.maxBackoff {
+		backoff = c.maxBackoff
+	}
+	if backoff > 1 {
+		backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)))
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Registry error codes, as returned by Confluent Schema Registry in Error.Code.
+const (
+	ErrCodeSubjectNotFound         = 40401
+	ErrCodeVersionNotFound         = 40402
+	ErrCodeSchemaNotFound          = 40403
+	ErrCodeSubjectSoftDeleted      = 40404
+	ErrCodeSubjectNotSoftDeleted   = 40405
+	ErrCodeVersionSoftDeleted      = 40406
+	ErrCodeVersionNotSoftDeleted   = 40407
+	ErrCodeIncompatibleSchema      = 409
+	ErrCodeInvalidSchema           = 42201
+	ErrCodeInvalidVersion          = 42202
+	ErrCodeInvalidCompatibility    = 42203
+	ErrCodeInvalidMode             = 42204
+	ErrCodeOperationNotPermitted   = 42205
+	ErrCodeReferenceExists         = 42206
+	ErrCodeStoreError              = 50001
+	ErrCodeOperationTimeout        = 50002
+	ErrCodeRequestForwardingFailed = 50003
+)
+
 // Error is returned by the registry when there is an error.
 type Error struct {
 	StatusCode int `json:"-"`
@@ -1,8 +1,11 @@
 package registry
 
 import (
+	"context"
+	"crypto/tls"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,3 +25,31 @@ func TestNewClient_WithBasicAuth(t *testing.T) {
 
 	assert.Equal(t, client.creds, creds)
 }
+
+func TestNewClient_WithTLSConfig(t *testing.T) {
+	httpClient := &http.Client{Transport: &http.Transport{}, Timeout: time.Second}
+	tlsConfig := &tls.Config{ServerName: "example.com"}
+
+	client, _ := NewClient("http://example.com", WithHTTPClient(httpClient), WithTLSConfig(tlsConfig))
+
+	assert.NotSame(t, httpClient, client.client)
+	assert.Equal(t, time.Second, client.client.Timeout)
+	assert.Same(t, tlsConfig, client.client.Transport.(*http.Transport).TLSClientConfig)
+}
+
+func TestNewClient_MultipleURLs(t *testing.T) {
+	client, _ := NewClient("http://a.example.com/, http://b.example.com")
+
+	assert.Equal(t, []string{"http://a.example.com", "http://b.example.com"}, client.bases)
+}
+
+func TestClient_WaitBacksOff(t *testing.T) {
+	client, _ := NewClient("http://example.com", WithRetries(5, 10*time.Millisecond, 30*time.Millisecond))
+
+	start := time.Now()
+	_ = client.wait(context.Background(), 3)
+	elapsed := time.Since(start)
+
+	assert.GreaterOrEqual(t, int64(elapsed), int64(15*time.Millisecond))
+	assert.Less(t, int64(elapsed), int64(time.Second))
+}
@@ -1,12 +1,17 @@
 package registry_test
 
 import (
+	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/xl4hub/hamba-avro/registry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/registry"
 )
 
 func TestNewClient(t *testing.T) {
@@ -59,6 +64,193 @@ func TestNewClient_BasicAuth(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewClient_BearerToken(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithBearerToken("token"))
+
+	_, err := client.GetSubjects()
+
+	assert.NoError(t, err)
+}
+
+func TestNewClient_Header(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", r.Header.Get("Content-Type"))
+
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithHeader("X-Api-Key", "secret"))
+
+	_, err := client.GetSubjects()
+
+	assert.NoError(t, err)
+}
+
+func TestNewClient_TLSConfig(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+	tlsConfig := s.Client().Transport.(*http.Transport).TLSClientConfig
+	client, _ := registry.NewClient(s.URL, registry.WithTLSConfig(tlsConfig))
+
+	_, err := client.GetSubjects()
+
+	assert.NoError(t, err)
+}
+
+func TestNewClient_TLSConfigUntrusted(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.GetSubjects()
+
+	assert.Error(t, err)
+}
+
+func TestClient_RetriesIdempotentRequests(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		if count < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`["foobar"]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(2, time.Millisecond, 2*time.Millisecond))
+
+	subjects, err := client.GetSubjects()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foobar"}, subjects)
+	assert.Equal(t, 3, count)
+}
+
+func TestClient_RetriesGivesUp(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(2, time.Millisecond, time.Millisecond))
+
+	_, err := client.GetSubjects()
+
+	assert.Error(t, err)
+	assert.Equal(t, 500, err.(registry.Error).StatusCode)
+	assert.Equal(t, 3, count)
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(2, time.Millisecond, time.Millisecond))
+
+	_, err := client.GetVersions("test")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestClient_DoesNotRetryCreateSchema(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(2, time.Millisecond, time.Millisecond))
+
+	_, _, err := client.CreateSchema("test", `"int"`)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestClient_FailsOver(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+	count := 0
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		_, _ = w.Write([]byte(`{"id":10}`))
+	}))
+	defer up.Close()
+	client, _ := registry.NewClient(down.URL + "," + up.URL + "/")
+
+	id, _, err := client.CreateSchema("test", `"int"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, id)
+
+	_, _, err = client.CreateSchema("test", `"int"`)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestClient_FailsOverOnServerError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[1, 2]`))
+	}))
+	defer up.Close()
+	client, _ := registry.NewClient(failing.URL + ", " + up.URL)
+
+	versions, err := client.GetVersions("test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(10, time.Hour, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetSubjectsContext(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_ContextCanceledBeforeRequest(t *testing.T) {
+	count := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL, registry.WithRetries(2, time.Millisecond, time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetSchemaContext(ctx, 5)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, count)
+}
+
 func TestClient_GetSchema(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
@@ -331,6 +523,106 @@ func TestClient_GetLatestSchemaInfo(t *testing.T) {
 	assert.Equal(t, 1, schemaInfo.Version)
 }
 
+func TestClient_GetSchemaResolvesReferences(t *testing.T) {
+	counts := map[string]int{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		counts[r.URL.Path]++
+
+		switch r.URL.Path {
+		case "/schemas/ids/7", "/subjects/user/versions/latest":
+			_, _ = w.Write([]byte(`{
+				"subject":"user","version":4,"id":7,
+				"schema":"{\"type\":\"record\",\"name\":\"org.hamba.User\",\"fields\":[{\"name\":\"address\",\"type\":\"Address\"}]}",
+				"references":[{"name":"org.hamba.Address","subject":"address","version":1}]
+			}`))
+		case "/subjects/address/versions/1":
+			_, _ = w.Write([]byte(`{
+				"subject":"address","version":1,"id":5,
+				"schema":"{\"type\":\"record\",\"name\":\"org.hamba.Address\",\"fields\":[{\"name\":\"country\",\"type\":\"Country\"}]}",
+				"references":[{"name":"org.hamba.Country","subject":"country","version":2}]
+			}`))
+		case "/subjects/country/versions/2":
+			_, _ = w.Write([]byte(`{"subject":"country","version":2,"id":6,"schema":"{\"type\":\"enum\",\"name\":\"org.hamba.Country\",\"symbols\":[\"NL\"]}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	schema, err := client.GetSchema(7)
+	require.NoError(t, err)
+	info, err := client.GetLatestSchemaInfo("user")
+	require.NoError(t, err)
+
+	assert.Equal(t, schema.String(), info.Schema.String())
+	assert.Equal(t, []registry.Reference{{Name: "org.hamba.Address", Subject: "address", Version: 1}}, info.References)
+	rec := schema.(*avro.RecordSchema)
+	addr := rec.Fields()[0].Type().(*avro.RefSchema).Schema().(*avro.RecordSchema)
+	assert.Equal(t, "org.hamba.Country", addr.Fields()[0].Type().(*avro.EnumSchema).FullName())
+	assert.Equal(t, 1, counts["/subjects/address/versions/1"])
+	assert.Equal(t, 1, counts["/subjects/country/versions/2"])
+}
+
+func TestClient_GetSchemaReferenceVersions(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schemas/ids/10", "/schemas/ids/12":
+			_, _ = w.Write([]byte(`{"schema":"{\"type\":\"array\",\"items\":\"org.hamba.T\"}","references":[{"name":"org.hamba.T","subject":"t","version":1}]}`))
+		case "/schemas/ids/11":
+			_, _ = w.Write([]byte(`{"schema":"{\"type\":\"array\",\"items\":\"org.hamba.T\"}","references":[{"name":"org.hamba.T","subject":"t","version":2}]}`))
+		case "/subjects/t/versions/1":
+			_, _ = w.Write([]byte(`{"subject":"t","version":1,"id":1,"schema":"{\"type\":\"record\",\"name\":\"org.hamba.T\",\"fields\":[{\"name\":\"a\",\"type\":\"int\"}]}"}`))
+		case "/subjects/t/versions/2":
+			_, _ = w.Write([]byte(`{"subject":"t","version":2,"id":2,"schema":"{\"type\":\"record\",\"name\":\"org.hamba.T\",\"fields\":[{\"name\":\"b\",\"type\":\"int\"}]}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	v1, err := client.GetSchema(10)
+	require.NoError(t, err)
+	v2, err := client.GetSchema(11)
+	require.NoError(t, err)
+	v1Again, err := client.GetSchema(12)
+	require.NoError(t, err)
+
+	fieldName := func(schema avro.Schema) string {
+		items := schema.(*avro.ArraySchema).Items()
+		if ref, ok := items.(*avro.RefSchema); ok {
+			items = ref.Schema()
+		}
+		return items.(*avro.RecordSchema).Fields()[0].Name()
+	}
+	assert.Equal(t, "a", fieldName(v1))
+	assert.Equal(t, "b", fieldName(v2))
+	assert.Equal(t, "a", fieldName(v1Again))
+}
+
+func TestClient_GetSchemaReferenceError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subjects/foobar/versions/3" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40402,"message":"Version not found."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"schema":"\"org.hamba.Missing\"","references":[{"name":"org.hamba.Missing","subject":"missing","version":1}]}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.GetSchemaByVersion("foobar", 3)
+
+	require.Error(t, err)
+	assert.Equal(t, "registry: fetching reference org.hamba.Missing: Version not found.", err.Error())
+	var regErr registry.Error
+	require.ErrorAs(t, err, &regErr)
+	assert.Equal(t, 404, regErr.StatusCode)
+}
+
 func TestClient_GetLatestSchemaInfoRequestError(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
@@ -367,6 +659,44 @@ func TestClient_GetLatestSchemaInfoSchemaError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestClient_GetSchemaVersion(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects/foobar/versions/latest", r.URL.Path)
+
+		_, _ = w.Write([]byte(`{"subject":"foobar","version":3,"id":42,"schema":"{\"type\":\"string\"}","references":[{"name":"Kind","subject":"kind","version":1}]}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	v, err := client.GetSchemaVersion("foobar", registry.LatestVersion)
+
+	assert.NoError(t, err)
+	assert.Equal(t, registry.SchemaVersion{
+		Subject:    "foobar",
+		Version:    3,
+		ID:         42,
+		Schema:     `{"type":"string"}`,
+		References: []registry.Reference{{Name: "Kind", Subject: "kind", Version: 1}},
+	}, v)
+}
+
+func TestClient_GetSchemaVersionRequestError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/foobar/versions/2", r.URL.Path)
+
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"error_code":40402,"message":"Version not found."}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.GetSchemaVersion("foobar", 2)
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeVersionNotFound, err.(registry.Error).Code)
+}
+
 func TestClient_CreateSchema(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "POST", r.Method)
@@ -384,6 +714,31 @@ func TestClient_CreateSchema(t *testing.T) {
 	assert.Equal(t, `["null","string","int"]`, schema.String())
 }
 
+func TestClient_CreateSchemaWithReferences(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subjects/foobar/versions":
+			b, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, `{"schema":"{\"type\":\"array\",\"items\":\"org.hamba.Kind\"}","references":[{"name":"org.hamba.Kind","subject":"kind","version":1}]}`, string(b))
+
+			_, _ = w.Write([]byte(`{"id":10}`))
+		case "/subjects/kind/versions/1":
+			_, _ = w.Write([]byte(`{"subject":"kind","version":1,"id":3,"schema":"{\"type\":\"enum\",\"name\":\"org.hamba.Kind\",\"symbols\":[\"A\"]}"}`))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	id, schema, err := client.CreateSchema("foobar", `{"type":"array","items":"org.hamba.Kind"}`, ref)
+
+	require.NoError(t, err)
+	assert.Equal(t, 10, id)
+	assert.Equal(t, `{"type":"array","items":{"name":"org.hamba.Kind","type":"enum","symbols":["A"]}}`, schema.String())
+}
+
 func TestClient_CreateSchemaRequestError(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
@@ -473,6 +828,253 @@ func TestClient_IsRegisteredSchemaError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestClient_GetSubjectsIncludingDeleted(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("deleted"))
+
+		_, _ = w.Write([]byte(`["foobar","deleted"]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	subs, err := client.GetSubjectsIncludingDeleted()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foobar", "deleted"}, subs)
+}
+
+func TestClient_GetSubjectsForSchemaID(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/schemas/ids/5/subjects", r.URL.Path)
+
+		_, _ = w.Write([]byte(`["foo","bar"]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	subs, err := client.GetSubjectsForSchemaID(5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"foo", "bar"}, subs)
+}
+
+func TestClient_GetSubjectsForSchemaIDRequestError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.GetSubjectsForSchemaID(5)
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeSchemaNotFound, err.(registry.Error).Code)
+}
+
+func TestClient_GetVersionsForSchemaID(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/schemas/ids/5/versions", r.URL.Path)
+
+		_, _ = w.Write([]byte(`[{"subject":"foo","version":1},{"subject":"bar","version":3}]`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	versions, err := client.GetVersionsForSchemaID(5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.SubjectVersion{{Subject: "foo", Version: 1}, {Subject: "bar", Version: 3}}, versions)
+}
+
+func TestClient_ImportSchema(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/subjects/foobar/versions", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"schema":"[\"null\",\"string\"]","id":42,"version":3}`, string(body))
+
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	schema, err := client.ImportSchema("foobar", 42, 3, `["null","string"]`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `["null","string"]`, schema.String())
+}
+
+func TestClient_ImportSchemaNotPermitted(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		_, _ = w.Write([]byte(`{"error_code":42205,"message":"Operation not permitted"}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.ImportSchema("foobar", 42, 3, `["null","string"]`)
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeOperationNotPermitted, err.(registry.Error).Code)
+}
+
+func TestClient_DeleteSubject(t *testing.T) {
+	tests := []struct {
+		name      string
+		permanent bool
+		wantQuery string
+	}{
+		{
+			name:      "Soft",
+			permanent: false,
+			wantQuery: "",
+		},
+		{
+			name:      "Permanent",
+			permanent: true,
+			wantQuery: "permanent=true",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "DELETE", r.Method)
+				assert.Equal(t, "/subjects/foobar", r.URL.Path)
+				assert.Equal(t, test.wantQuery, r.URL.RawQuery)
+
+				_, _ = w.Write([]byte(`[1,2]`))
+			}))
+			defer s.Close()
+			client, _ := registry.NewClient(s.URL)
+
+			versions, err := client.DeleteSubject("foobar", test.permanent)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []int{1, 2}, versions)
+		})
+	}
+}
+
+func TestClient_DeleteSubjectNotSoftDeleted(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"error_code":40405,"message":"Subject 'foobar' was not deleted first before being permanently deleted"}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	_, err := client.DeleteSubject("foobar", true)
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeSubjectNotSoftDeleted, err.(registry.Error).Code)
+}
+
+func TestClient_DeleteSchemaVersion(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/subjects/foobar/versions/2", r.URL.Path)
+		assert.Equal(t, "permanent=true", r.URL.RawQuery)
+
+		_, _ = w.Write([]byte(`2`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	err := client.DeleteSchemaVersion("foobar", 2, true)
+
+	assert.NoError(t, err)
+}
+
+func TestClient_DeleteSchemaVersionRequestError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		_, _ = w.Write([]byte(`{"error_code":40402,"message":"Version not found."}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	err := client.DeleteSchemaVersion("foobar", 2, false)
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeVersionNotFound, err.(registry.Error).Code)
+}
+
+func TestClient_GetMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		wantPath string
+	}{
+		{
+			name:     "Global",
+			subject:  "",
+			wantPath: "/mode",
+		},
+		{
+			name:     "Subject",
+			subject:  "foobar",
+			wantPath: "/mode/foobar",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "GET", r.Method)
+				assert.Equal(t, test.wantPath, r.URL.Path)
+
+				_, _ = w.Write([]byte(`{"mode":"READONLY"}`))
+			}))
+			defer s.Close()
+			client, _ := registry.NewClient(s.URL)
+
+			mode, err := client.GetMode(test.subject)
+
+			assert.NoError(t, err)
+			assert.Equal(t, registry.ModeReadOnly, mode)
+		})
+	}
+}
+
+func TestClient_SetMode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/mode/foobar", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"mode":"IMPORT"}`, string(body))
+
+		_, _ = w.Write([]byte(`{"mode":"IMPORT"}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	err := client.SetMode("foobar", registry.ModeImport)
+
+	assert.NoError(t, err)
+}
+
+func TestClient_SetModeInvalidMode(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(422)
+		_, _ = w.Write([]byte(`{"error_code":42204,"message":"Invalid mode"}`))
+	}))
+	defer s.Close()
+	client, _ := registry.NewClient(s.URL)
+
+	err := client.SetMode("", "SIDEWAYS")
+
+	assert.Error(t, err)
+	assert.Equal(t, registry.ErrCodeInvalidMode, err.(registry.Error).Code)
+}
+
 func TestClient_HandlesServerError(t *testing.T) {
 	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 	s.Close()
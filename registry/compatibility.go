@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// CompatibilityLevel is the compatibility enforced between the versions of a subject.
+type CompatibilityLevel string
+
+// Compatibility levels.
+const (
+	// CompatibilityNone does not check compatibility.
+	CompatibilityNone CompatibilityLevel = "NONE"
+
+	// CompatibilityBackward checks that the new schema can read data written with the latest schema.
+	CompatibilityBackward CompatibilityLevel = "BACKWARD"
+
+	// CompatibilityBackwardTransitive checks that the new schema can read data written with all schemas.
+	CompatibilityBackwardTransitive CompatibilityLevel = "BACKWARD_TRANSITIVE"
+
+	// CompatibilityForward checks that data written with the new schema can be read with the latest schema.
+	CompatibilityForward CompatibilityLevel = "FORWARD"
+
+	// CompatibilityForwardTransitive checks that data written with the new schema can be read with all schemas.
+	CompatibilityForwardTransitive CompatibilityLevel = "FORWARD_TRANSITIVE"
+
+	// CompatibilityFull checks both backward and forward compatibility with the latest schema.
+	CompatibilityFull CompatibilityLevel = "FULL"
+
+	// CompatibilityFullTransitive checks both backward and forward compatibility with all schemas.
+	CompatibilityFullTransitive CompatibilityLevel = "FULL_TRANSITIVE"
+)
+
+// Valid determines if the compatibility level is known.
+func (l CompatibilityLevel) Valid() bool {
+	switch l {
+	case CompatibilityNone, CompatibilityBackward, CompatibilityBackwardTransitive,
+		CompatibilityForward, CompatibilityForwardTransitive, CompatibilityFull, CompatibilityFullTransitive:
+		return true
+	}
+	return false
+}
+
+// Check checks that the schema is compatible with the existing versions of a subject,
+// ordered from the oldest to the latest version.
+func (l CompatibilityLevel) Check(compat *avro.SchemaCompatibility, schema avro.Schema, versions []avro.Schema) error {
+	if l == CompatibilityNone || len(versions) == 0 {
+		return nil
+	}
+
+	var backward, forward bool
+	switch l {
+	case CompatibilityBackward, CompatibilityBackwardTransitive:
+		backward = true
+	case CompatibilityForward, CompatibilityForwardTransitive:
+		forward = true
+	case CompatibilityFull, CompatibilityFullTransitive:
+		backward, forward = true, true
+	default:
+		return fmt.Errorf("registry: unknown compatibility level %q", l)
+	}
+
+	switch l {
+	case CompatibilityBackward, CompatibilityForward, CompatibilityFull:
+		versions = versions[len(versions)-1:]
+	}
+
+	for _, existing := range versions {
+		if backward {
+			if err := compat.Compatible(schema, existing); err != nil {
+				return err
+			}
+		}
+		if forward {
+			if err := compat.Compatible(existing, schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
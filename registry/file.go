@@ -0,0 +1,283 @@
+package registry
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// configFile is the file the compatibility levels are stored in.
+type configFile struct {
+	Compatibility CompatibilityLevel            `json:"compatibility"`
+	Subjects      map[string]CompatibilityLevel `json:"subjects,omitempty"`
+}
+
+// FileRegistry is a Registry storing schemas in a directory, for offline use.
+//
+// Each version of a subject is stored as a JSON file holding the subject, version, id,
+// schema and references of the version, at subjects/SUBJECT/VERSION.json in the
+// directory. The compatibility levels are stored in config.json. Otherwise a
+// FileRegistry behaves as a MemoryRegistry.
+type FileRegistry struct {
+	*MemoryRegistry
+
+	dir string
+}
+
+// NewFileRegistry returns a registry storing schemas in the directory, loading the
+// schemas already stored in it. The directory is created if it does not exist.
+func NewFileRegistry(dir string) (*FileRegistry, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	r := &FileRegistry{MemoryRegistry: NewMemoryRegistry(), dir: dir}
+	if err := r.loadConfig(); err != nil {
+		return nil, err
+	}
+	if err := r.loadVersions(); err != nil {
+		return nil, err
+	}
+
+	r.persist = r.writeVersion
+	r.persistLevels = r.writeConfig
+
+	return r, nil
+}
+
+func (r *FileRegistry) configPath() string {
+	return filepath.Join(r.dir, "config.json")
+}
+
+func (r *FileRegistry) subjectsDir() string {
+	return filepath.Join(r.dir, "subjects")
+}
+
+// subjectDir returns the directory the versions of the subject are stored in.
+func (r *FileRegistry) subjectDir(subject string) string {
+	return filepath.Join(r.subjectsDir(), subjectDirName(subject))
+}
+
+// subjectDirName returns the name of a directory holding the versions of the subject.
+func subjectDirName(subject string) string {
+	name := url.PathEscape(subject)
+	if strings.Trim(name, ".") == "" {
+		name = strings.Replace(name, ".", "%2E", -1)
+	}
+	return name
+}
+
+func (r *FileRegistry) loadConfig() error {
+	b, err := ioutil.ReadFile(r.configPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var cfg configFile
+	if err = jsoniter.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("registry: %s: %w", r.configPath(), err)
+	}
+
+	levels := map[string]CompatibilityLevel{}
+	for subject, level := range cfg.Subjects {
+		levels[subject] = level
+	}
+	if cfg.Compatibility != "" {
+		levels[""] = cfg.Compatibility
+	}
+	for subject, level := range levels {
+		if !level.Valid() {
+			return fmt.Errorf("registry: %s: invalid compatibility level %q", r.configPath(), level)
+		}
+		r.levels[subject] = level
+	}
+	return nil
+}
+
+func (r *FileRegistry) loadVersions() error {
+	dirs, err := ioutil.ReadDir(r.subjectsDir())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var files []SchemaVersion
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(r.subjectsDir(), d.Name())
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			if info.IsDir() || filepath.Ext(info.Name()) != ".json" {
+				continue
+			}
+
+			path := filepath.Join(dir, info.Name())
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			var f SchemaVersion
+			if err = jsoniter.Unmarshal(b, &f); err != nil {
+				return fmt.Errorf("registry: %s: %w", path, err)
+			}
+			files = append(files, f)
+		}
+	}
+
+	// Imported schemas may reference schemas with greater ids, so referenced
+	// versions are loaded first, otherwise versions are loaded in the order of their ids.
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].ID != files[j].ID {
+			return files[i].ID < files[j].ID
+		}
+		if files[i].Subject != files[j].Subject {
+			return files[i].Subject < files[j].Subject
+		}
+		return files[i].Version < files[j].Version
+	})
+
+	l := versionLoader{
+		r:         r,
+		files:     files,
+		versions:  make(map[SubjectVersion]int, len(files)),
+		subjects:  map[string][]int{},
+		loaded:    make([]bool, len(files)),
+		resolving: make([]bool, len(files)),
+	}
+	for i, f := range files {
+		l.versions[SubjectVersion{Subject: f.Subject, Version: f.Version}] = i
+		l.subjects[f.Subject] = append(l.subjects[f.Subject], i)
+	}
+	for i := range files {
+		if err = l.load(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionLoader loads the stored versions of a registry, loading the versions
+// referenced by a version before it.
+type versionLoader struct {
+	r     *FileRegistry
+	files []SchemaVersion
+
+	versions map[SubjectVersion]int
+	subjects map[string][]int
+
+	loaded    []bool
+	resolving []bool
+}
+
+func (l *versionLoader) load(i int) error {
+	// A version referencing itself in turn fails to parse below.
+	if l.loaded[i] || l.resolving[i] {
+		return nil
+	}
+	l.resolving[i] = true
+
+	f := l.files[i]
+	for _, ref := range f.References {
+		deps := l.subjects[ref.Subject]
+		if ref.Version != LatestVersion {
+			dep, ok := l.versions[SubjectVersion{Subject: ref.Subject, Version: ref.Version}]
+			if !ok {
+				continue
+			}
+			deps = []int{dep}
+		}
+
+		for _, dep := range deps {
+			if err := l.load(dep); err != nil {
+				return err
+			}
+		}
+	}
+
+	s, ok := l.r.ids[f.ID]
+	if !ok {
+		parsed, err := l.r.parse(f.Schema, f.References)
+		if err != nil {
+			return fmt.Errorf("registry: subject %s version %d: %w", f.Subject, f.Version, err)
+		}
+		s = &storedSchema{id: f.ID, schema: f.Schema, refs: f.References, parsed: parsed}
+	}
+
+	if err := l.r.add(f.Subject, f.Version, s); err != nil {
+		return err
+	}
+	l.resolving[i] = false
+	l.loaded[i] = true
+	return nil
+}
+
+func (r *FileRegistry) writeVersion(subject string, version int, s *storedSchema) error {
+	b, err := jsoniter.MarshalIndent(SchemaVersion{
+		Subject:    subject,
+		Version:    version,
+		ID:         s.id,
+		Schema:     s.schema,
+		References: s.refs,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := r.subjectDir(subject)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, strconv.Itoa(version)+".json"), b)
+}
+
+func (r *FileRegistry) writeConfig(levels map[string]CompatibilityLevel) error {
+	cfg := configFile{Compatibility: levels[""], Subjects: map[string]CompatibilityLevel{}}
+	for subject, level := range levels {
+		if subject != "" {
+			cfg.Subjects[subject] = level
+		}
+	}
+
+	b, err := jsoniter.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(r.configPath(), b)
+}
+
+// writeFile writes the file through a temporary file, so the file is never partially written.
+func writeFile(path string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
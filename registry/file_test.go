@@ -0,0 +1,139 @@
+package registry_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro/registry"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "registry")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestNewFileRegistry(t *testing.T) {
+	dir := filepath.Join(tempDir(t), "schemas")
+
+	reg, err := registry.NewFileRegistry(dir)
+
+	require.NoError(t, err)
+	assert.Implements(t, (*registry.Registry)(nil), reg)
+	assert.DirExists(t, dir)
+}
+
+func TestFileRegistry_Reopen(t *testing.T) {
+	dir := tempDir(t)
+
+	reg, err := registry.NewFileRegistry(dir)
+	require.NoError(t, err)
+	require.NoError(t, reg.SetCompatibilityLevel("", registry.CompatibilityFull))
+	require.NoError(t, reg.SetCompatibilityLevel("user", registry.CompatibilityNone))
+	_, _, err = reg.CreateSchema("kind", `{"type":"enum","name":"org.hamba.Kind","symbols":["A","B"]}`)
+	require.NoError(t, err)
+	_, _, err = reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	_, _, err = reg.CreateSchema("user", userV3)
+	require.NoError(t, err)
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	_, _, err = reg.CreateSchema("org/item", `{"type":"record","name":"org.hamba.Item","fields":[{"name":"kind","type":"Kind"}]}`, ref)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(dir, "subjects", "user", "1.json"))
+	assert.FileExists(t, filepath.Join(dir, "subjects", "org%2Fitem", "1.json"))
+	assert.FileExists(t, filepath.Join(dir, "config.json"))
+
+	got, err := registry.NewFileRegistry(dir)
+	require.NoError(t, err)
+
+	subjects, err := got.GetSubjects()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind", "org/item", "user"}, subjects)
+	versions, err := got.GetVersions("user")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+	info, err := got.GetLatestSchemaInfo("user")
+	require.NoError(t, err)
+	assert.Equal(t, 3, info.ID)
+	info, err = got.GetLatestSchemaInfo("org/item")
+	require.NoError(t, err)
+	assert.Equal(t, 4, info.ID)
+	assert.Equal(t, []registry.Reference{ref}, info.References)
+	assert.Equal(t, registry.CompatibilityFull, got.GetCompatibilityLevel(""))
+	assert.Equal(t, registry.CompatibilityNone, got.GetCompatibilityLevel("user"))
+
+	id, _, err := got.CreateSchema("other", `{"type":"array","items":"int"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 5, id)
+}
+
+func TestFileRegistry_ReopenWithReferenceToGreaterID(t *testing.T) {
+	dir := tempDir(t)
+
+	reg, err := registry.NewFileRegistry(dir)
+	require.NoError(t, err)
+	_, err = reg.ImportSchema("kind", 20, 1, `{"type":"enum","name":"org.hamba.Kind","symbols":["A","B"]}`)
+	require.NoError(t, err)
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	_, err = reg.ImportSchema("item", 10, 1, `{"type":"record","name":"org.hamba.Item","fields":[{"name":"kind","type":"Kind"}]}`, ref)
+	require.NoError(t, err)
+
+	got, err := registry.NewFileRegistry(dir)
+
+	require.NoError(t, err)
+	info, err := got.GetLatestSchemaInfo("item")
+	require.NoError(t, err)
+	assert.Equal(t, 10, info.ID)
+	assert.Equal(t, []registry.Reference{ref}, info.References)
+}
+
+func TestNewFileRegistry_InvalidFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+	}{
+		{
+			name: "Invalid Config",
+			path: "config.json",
+			data: `{"compatibility":`,
+		},
+		{
+			name: "Invalid Compatibility Level",
+			path: "config.json",
+			data: `{"compatibility":"SIDEWAYS"}`,
+		},
+		{
+			name: "Invalid Version File",
+			path: filepath.Join("subjects", "user", "1.json"),
+			data: `{"subject":`,
+		},
+		{
+			name: "Invalid Schema",
+			path: filepath.Join("subjects", "user", "1.json"),
+			data: `{"subject":"user","version":1,"id":1,"schema":"{\"type\":\"record\"}"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir := tempDir(t)
+			path := filepath.Join(dir, test.path)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, ioutil.WriteFile(path, []byte(test.data), 0644))
+
+			_, err := registry.NewFileRegistry(dir)
+
+			assert.Error(t, err)
+		})
+	}
+}
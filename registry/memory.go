@@ -0,0 +1,586 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/xl4hub/hamba-avro"
+)
+
+// LatestVersion refers to the latest version of a subject.
+const LatestVersion = -1
+
+// SchemaVersion is a version of a subject, holding the schema as registered.
+type SchemaVersion struct {
+	Subject    string      `json:"subject"`
+	Version    int         `json:"version"`
+	ID         int         `json:"id"`
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references,omitempty"`
+}
+
+func errSubjectNotFound(subject string) error {
+	return Error{StatusCode: http.StatusNotFound, Code: ErrCodeSubjectNotFound, Message: "Subject '" + subject + "' not found."}
+}
+
+func errVersionNotFound() error {
+	return Error{StatusCode: http.StatusNotFound, Code: ErrCodeVersionNotFound, Message: "Version not found."}
+}
+
+func errSchemaNotFound() error {
+	return Error{StatusCode: http.StatusNotFound, Code: ErrCodeSchemaNotFound, Message: "Schema not found"}
+}
+
+func errInvalidSchema(err error) error {
+	return Error{StatusCode: http.StatusUnprocessableEntity, Code: ErrCodeInvalidSchema, Message: "Invalid schema: " + err.Error()}
+}
+
+func errIncompatibleSchema(err error) error {
+	return Error{
+		StatusCode: http.StatusConflict,
+		Code:       ErrCodeIncompatibleSchema,
+		Message:    "Schema being registered is incompatible with an earlier schema: " + err.Error(),
+	}
+}
+
+func errOperationNotPermitted(msg string) error {
+	return Error{StatusCode: http.StatusUnprocessableEntity, Code: ErrCodeOperationNotPermitted, Message: msg}
+}
+
+func errInvalidCompatibility(level CompatibilityLevel) error {
+	return Error{
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       ErrCodeInvalidCompatibility,
+		Message:    "Invalid compatibility level: " + string(level),
+	}
+}
+
+type storedSchema struct {
+	id     int
+	schema string
+	refs   []Reference
+	parsed avro.Schema
+}
+
+type subjectVersion struct {
+	version int
+	schema  *storedSchema
+}
+
+func (v subjectVersion) info(subject string) SchemaVersion {
+	return SchemaVersion{
+		Subject:    subject,
+		Version:    v.version,
+		ID:         v.schema.id,
+		Schema:     v.schema.schema,
+		References: v.schema.refs,
+	}
+}
+
+// MemoryRegistry is a Registry holding schemas in memory, for use in tests and offline.
+//
+// Schemas are assigned increasing ids. A schema registered again, under the same or
+// another subject, keeps its id, and is only added as a new version if it is not the
+// schema of a version of the subject already. New versions are checked against the
+// compatibility level of the subject, BACKWARD by default.
+type MemoryRegistry struct {
+	mu sync.RWMutex
+
+	lastID       int
+	ids          map[int]*storedSchema
+	fingerprints map[[32]byte]*storedSchema
+	subjects     map[string][]subjectVersion
+
+	// levels holds the compatibility levels by subject, the global level by the empty subject.
+	levels map[string]CompatibilityLevel
+	compat *avro.SchemaCompatibility
+
+	// persist is called with a new version before it is added, failing the registration on error.
+	persist func(subject string, version int, schema *storedSchema) error
+
+	// persistLevels is called with the new compatibility levels before they are set.
+	persistLevels func(levels map[string]CompatibilityLevel) error
+}
+
+// NewMemoryRegistry returns an empty in-memory registry.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		ids:          map[int]*storedSchema{},
+		fingerprints: map[[32]byte]*storedSchema{},
+		subjects:     map[string][]subjectVersion{},
+		levels:       map[string]CompatibilityLevel{"": CompatibilityBackward},
+		compat:       avro.NewSchemaCompatibility(),
+	}
+}
+
+// GetCompatibilityLevel returns the compatibility level of the subject, or the global
+// compatibility level if the subject is empty or has no level of its own.
+func (r *MemoryRegistry) GetCompatibilityLevel(subject string) CompatibilityLevel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.level(subject)
+}
+
+// SetCompatibilityLevel sets the compatibility level of the subject, or the global
+// compatibility level if the subject is empty.
+func (r *MemoryRegistry) SetCompatibilityLevel(subject string, level CompatibilityLevel) error {
+	if !level.Valid() {
+		return errInvalidCompatibility(level)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.persistLevels != nil {
+		levels := make(map[string]CompatibilityLevel, len(r.levels)+1)
+		for k, v := range r.levels {
+			levels[k] = v
+		}
+		levels[subject] = level
+
+		if err := r.persistLevels(levels); err != nil {
+			return err
+		}
+	}
+
+	r.levels[subject] = level
+	return nil
+}
+
+func (r *MemoryRegistry) level(subject string) CompatibilityLevel {
+	if level, ok := r.levels[subject]; ok {
+		return level
+	}
+	return r.levels[""]
+}
+
+// GetSchema returns the schema with the given id.
+func (r *MemoryRegistry) GetSchema(id int) (avro.Schema, error) {
+	return r.GetSchemaContext(context.Background(), id)
+}
+
+// GetSchemaContext returns the schema with the given id.
+func (r *MemoryRegistry) GetSchemaContext(ctx context.Context, id int) (avro.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.ids[id]
+	if !ok {
+		return nil, errSchemaNotFound()
+	}
+	return s.parsed, nil
+}
+
+// GetSubjects gets the registry subjects.
+func (r *MemoryRegistry) GetSubjects() ([]string, error) {
+	return r.GetSubjectsContext(context.Background())
+}
+
+// GetSubjectsContext gets the registry subjects.
+func (r *MemoryRegistry) GetSubjectsContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subjects := make([]string, 0, len(r.subjects))
+	for subject, versions := range r.subjects {
+		if len(versions) > 0 {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+// GetVersions gets the schema versions for a subject.
+func (r *MemoryRegistry) GetVersions(subject string) ([]int, error) {
+	return r.GetVersionsContext(context.Background(), subject)
+}
+
+// GetVersionsContext gets the schema versions for a subject.
+func (r *MemoryRegistry) GetVersionsContext(ctx context.Context, subject string) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.subjects[subject]
+	if !ok || len(versions) == 0 {
+		return nil, errSubjectNotFound(subject)
+	}
+
+	nums := make([]int, len(versions))
+	for i, v := range versions {
+		nums[i] = v.version
+	}
+	return nums, nil
+}
+
+// GetSchemaByVersion gets the schema by version.
+func (r *MemoryRegistry) GetSchemaByVersion(subject string, version int) (avro.Schema, error) {
+	return r.GetSchemaByVersionContext(context.Background(), subject, version)
+}
+
+// GetSchemaByVersionContext gets the schema by version.
+func (r *MemoryRegistry) GetSchemaByVersionContext(ctx context.Context, subject string, version int) (avro.Schema, error) {
+	info, err := r.getSchemaInfo(ctx, subject, version)
+	if err != nil {
+		return nil, err
+	}
+	return info.Schema, nil
+}
+
+// GetLatestSchema gets the latest schema for a subject.
+func (r *MemoryRegistry) GetLatestSchema(subject string) (avro.Schema, error) {
+	return r.GetLatestSchemaContext(context.Background(), subject)
+}
+
+// GetLatestSchemaContext gets the latest schema for a subject.
+func (r *MemoryRegistry) GetLatestSchemaContext(ctx context.Context, subject string) (avro.Schema, error) {
+	return r.GetSchemaByVersionContext(ctx, subject, LatestVersion)
+}
+
+// GetLatestSchemaInfo gets the latest schema and schema metadata for a subject.
+func (r *MemoryRegistry) GetLatestSchemaInfo(subject string) (SchemaInfo, error) {
+	return r.GetLatestSchemaInfoContext(context.Background(), subject)
+}
+
+// GetLatestSchemaInfoContext gets the latest schema and schema metadata for a subject.
+func (r *MemoryRegistry) GetLatestSchemaInfoContext(ctx context.Context, subject string) (SchemaInfo, error) {
+	return r.getSchemaInfo(ctx, subject, LatestVersion)
+}
+
+func (r *MemoryRegistry) getSchemaInfo(ctx context.Context, subject string, version int) (SchemaInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return SchemaInfo{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, err := r.version(subject, version)
+	if err != nil {
+		return SchemaInfo{}, err
+	}
+	return SchemaInfo{Schema: v.schema.parsed, ID: v.schema.id, Version: v.version, References: v.schema.refs}, nil
+}
+
+// GetRawSchema returns the schema with the given id as registered, with its references.
+func (r *MemoryRegistry) GetRawSchema(id int) (string, []Reference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.ids[id]
+	if !ok {
+		return "", nil, errSchemaNotFound()
+	}
+	return s.schema, s.refs, nil
+}
+
+// GetSchemaVersion returns the version of the subject, or its latest version
+// if version is LatestVersion.
+func (r *MemoryRegistry) GetSchemaVersion(subject string, version int) (SchemaVersion, error) {
+	return r.GetSchemaVersionContext(context.Background(), subject, version)
+}
+
+// GetSchemaVersionContext returns the version of the subject, or its latest version
+// if version is LatestVersion.
+func (r *MemoryRegistry) GetSchemaVersionContext(ctx context.Context, subject string, version int) (SchemaVersion, error) {
+	if err := ctx.Err(); err != nil {
+		return SchemaVersion{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, err := r.version(subject, version)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	return v.info(subject), nil
+}
+
+// version returns the version of the subject, or its latest version if version is LatestVersion.
+func (r *MemoryRegistry) version(subject string, version int) (subjectVersion, error) {
+	versions, ok := r.subjects[subject]
+	if !ok || len(versions) == 0 {
+		return subjectVersion{}, errSubjectNotFound(subject)
+	}
+
+	if version == LatestVersion {
+		return versions[len(versions)-1], nil
+	}
+	for _, v := range versions {
+		if v.version == version {
+			return v, nil
+		}
+	}
+	return subjectVersion{}, errVersionNotFound()
+}
+
+// CreateSchema creates a schema in the registry, returning the schema id.
+func (r *MemoryRegistry) CreateSchema(subject, schema string, references ...Reference) (int, avro.Schema, error) {
+	return r.CreateSchemaContext(context.Background(), subject, schema, references...)
+}
+
+// CreateSchemaContext creates a schema in the registry, returning the schema id.
+func (r *MemoryRegistry) CreateSchemaContext(
+	ctx context.Context,
+	subject, schema string,
+	references ...Reference,
+) (int, avro.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsed, err := r.parse(schema, references)
+	if err != nil {
+		return 0, nil, err
+	}
+	fp := parsed.Fingerprint()
+
+	versions := r.subjects[subject]
+	for _, v := range versions {
+		if v.schema.parsed.Fingerprint() == fp {
+			return v.schema.id, v.schema.parsed, nil
+		}
+	}
+
+	existing := make([]avro.Schema, len(versions))
+	for i, v := range versions {
+		existing[i] = v.schema.parsed
+	}
+	if err = r.level(subject).Check(r.compat, parsed, existing); err != nil {
+		return 0, nil, errIncompatibleSchema(err)
+	}
+
+	s, ok := r.fingerprints[fp]
+	if !ok {
+		s = &storedSchema{id: r.lastID + 1, schema: schema, refs: references, parsed: parsed}
+	}
+
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1].version + 1
+	}
+
+	if err = r.add(subject, version, s); err != nil {
+		return 0, nil, err
+	}
+	return s.id, s.parsed, nil
+}
+
+// ImportSchema registers a schema under a subject with the given id and version,
+// without checking its compatibility.
+//
+// Importing a version again with the same schema has no effect. It fails if the id
+// or the version is registered with another schema, or the schema with another id.
+func (r *MemoryRegistry) ImportSchema(subject string, id, version int, schema string, references ...Reference) (avro.Schema, error) {
+	return r.ImportSchemaContext(context.Background(), subject, id, version, schema, references...)
+}
+
+// ImportSchemaContext registers a schema under a subject with the given id and version,
+// without checking its compatibility.
+//
+// Importing a version again with the same schema has no effect. It fails if the id
+// or the version is registered with another schema, or the schema with another id.
+func (r *MemoryRegistry) ImportSchemaContext(
+	ctx context.Context,
+	subject string,
+	id, version int,
+	schema string,
+	references ...Reference,
+) (avro.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if id < 1 || version < 1 {
+		return nil, errOperationNotPermitted(fmt.Sprintf("Invalid id %d or version %d", id, version))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parsed, err := r.parse(schema, references)
+	if err != nil {
+		return nil, err
+	}
+	fp := parsed.Fingerprint()
+
+	s, ok := r.ids[id]
+	switch {
+	case ok && s.parsed.Fingerprint() != fp:
+		return nil, errOperationNotPermitted(fmt.Sprintf("Overwrite new schema with id %d is not permitted.", id))
+	case !ok:
+		if existing, found := r.fingerprints[fp]; found {
+			return nil, errOperationNotPermitted(fmt.Sprintf("Schema is already registered with id %d.", existing.id))
+		}
+		s = &storedSchema{id: id, schema: schema, refs: references, parsed: parsed}
+	}
+
+	if v, err := r.version(subject, version); err == nil {
+		if v.schema.id != id {
+			return nil, errOperationNotPermitted(fmt.Sprintf("Overwrite new schema for version %d is not permitted.", version))
+		}
+		return v.schema.parsed, nil
+	}
+
+	if err = r.add(subject, version, s); err != nil {
+		return nil, err
+	}
+	return s.parsed, nil
+}
+
+// add adds a version of a subject, persisting it first if needed.
+func (r *MemoryRegistry) add(subject string, version int, s *storedSchema) error {
+	if r.persist != nil {
+		if err := r.persist(subject, version, s); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := r.ids[s.id]; !ok {
+		r.ids[s.id] = s
+		r.fingerprints[s.parsed.Fingerprint()] = s
+	}
+	if s.id > r.lastID {
+		r.lastID = s.id
+	}
+
+	versions := append(r.subjects[subject], subjectVersion{version: version, schema: s})
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].version < versions[j].version
+	})
+	r.subjects[subject] = versions
+	return nil
+}
+
+// IsRegistered determines of the schema is registered.
+func (r *MemoryRegistry) IsRegistered(subject, schema string, references ...Reference) (int, avro.Schema, error) {
+	return r.IsRegisteredContext(context.Background(), subject, schema, references...)
+}
+
+// IsRegisteredContext determines of the schema is registered.
+func (r *MemoryRegistry) IsRegisteredContext(
+	ctx context.Context,
+	subject, schema string,
+	references ...Reference,
+) (int, avro.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, err := r.lookup(subject, schema, references)
+	if err != nil {
+		return 0, nil, err
+	}
+	return v.schema.id, v.schema.parsed, nil
+}
+
+// LookupSchema returns the version of the subject the schema is registered as.
+func (r *MemoryRegistry) LookupSchema(subject, schema string, references ...Reference) (SchemaVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, err := r.lookup(subject, schema, references)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	return v.info(subject), nil
+}
+
+func (r *MemoryRegistry) lookup(subject, schema string, references []Reference) (subjectVersion, error) {
+	versions, ok := r.subjects[subject]
+	if !ok || len(versions) == 0 {
+		return subjectVersion{}, errSubjectNotFound(subject)
+	}
+
+	parsed, err := r.parse(schema, references)
+	if err != nil {
+		return subjectVersion{}, err
+	}
+
+	fp := parsed.Fingerprint()
+	for _, v := range versions {
+		if v.schema.parsed.Fingerprint() == fp {
+			return v, nil
+		}
+	}
+	return subjectVersion{}, errSchemaNotFound()
+}
+
+// IsCompatible determines if the schema is compatible with the version of the subject,
+// or its latest version if version is LatestVersion, under the compatibility level
+// of the subject.
+func (r *MemoryRegistry) IsCompatible(subject string, version int, schema string, references ...Reference) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, err := r.version(subject, version)
+	if err != nil {
+		return false, err
+	}
+
+	parsed, err := r.parse(schema, references)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.level(subject).Check(r.compat, parsed, []avro.Schema{v.schema.parsed})
+	return err == nil, nil
+}
+
+// parse parses the schema in its own cache, after the schemas it references.
+func (r *MemoryRegistry) parse(schema string, references []Reference) (avro.Schema, error) {
+	cache := &avro.SchemaCache{}
+	if err := r.resolveReferences(references, cache, map[Reference]bool{}); err != nil {
+		return nil, err
+	}
+
+	parsed, err := avro.ParseWithCache(schema, "", cache)
+	if err != nil {
+		return nil, errInvalidSchema(err)
+	}
+	return parsed, nil
+}
+
+func (r *MemoryRegistry) resolveReferences(references []Reference, cache *avro.SchemaCache, seen map[Reference]bool) error {
+	for _, ref := range references {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		v, err := r.version(ref.Subject, ref.Version)
+		if err != nil {
+			return errInvalidSchema(fmt.Errorf("reference %s: %w", ref.Name, err))
+		}
+
+		if err = r.resolveReferences(v.schema.refs, cache, seen); err != nil {
+			return err
+		}
+		if _, err = avro.ParseWithCache(v.schema.schema, "", cache); err != nil {
+			return errInvalidSchema(fmt.Errorf("reference %s: %w", ref.Name, err))
+		}
+	}
+	return nil
+}
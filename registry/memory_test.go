@@ -0,0 +1,292 @@
+package registry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/registry"
+)
+
+const (
+	userV1 = `{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"long"}]}`
+	userV2 = `{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"long"},{"name":"name","type":"string","default":""}]}`
+	userV3 = `{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"long"},{"name":"email","type":"string"}]}`
+)
+
+func requireCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	require.Error(t, err)
+	regErr, ok := err.(registry.Error)
+	require.True(t, ok, "expected a registry.Error, got %T", err)
+	assert.Equal(t, code, regErr.Code)
+}
+
+func TestNewMemoryRegistry(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	assert.Implements(t, (*registry.Registry)(nil), reg)
+	assert.Equal(t, registry.CompatibilityBackward, reg.GetCompatibilityLevel(""))
+}
+
+func TestMemoryRegistry_CreateSchema(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	id1, schema, err := reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	id2, _, err := reg.CreateSchema("user", userV2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, id1)
+	assert.Equal(t, 2, id2)
+	assert.Equal(t, "org.hamba.User", schema.(*avro.RecordSchema).FullName())
+
+	versions, err := reg.GetVersions("user")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	got, err := reg.GetSchema(id1)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Fingerprint(), got.Fingerprint())
+
+	info, err := reg.GetLatestSchemaInfo("user")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.ID)
+	assert.Equal(t, 2, info.Version)
+
+	latest, err := reg.GetLatestSchema("user")
+	require.NoError(t, err)
+	assert.Len(t, latest.(*avro.RecordSchema).Fields(), 2)
+
+	byVersion, err := reg.GetSchemaByVersion("user", 1)
+	require.NoError(t, err)
+	assert.Len(t, byVersion.(*avro.RecordSchema).Fields(), 1)
+}
+
+func TestMemoryRegistry_CreateSchemaDeduplicates(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	id1, _, err := reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	// The same schema in another form is the same schema.
+	id2, _, err := reg.CreateSchema("user", `{"name":"User","namespace":"org.hamba","type":"record","doc":"A user.","fields":[{"name":"id","type":"long"}]}`)
+	require.NoError(t, err)
+	id3, _, err := reg.CreateSchema("customer", userV1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, id1)
+	assert.Equal(t, 1, id2)
+	assert.Equal(t, 1, id3)
+	versions, _ := reg.GetVersions("user")
+	assert.Equal(t, []int{1}, versions)
+	subjects, _ := reg.GetSubjects()
+	assert.Equal(t, []string{"customer", "user"}, subjects)
+}
+
+func TestMemoryRegistry_CreateSchemaInvalidSchema(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	_, _, err := reg.CreateSchema("user", `{"type":"record"}`)
+
+	requireCode(t, err, 42201)
+	assert.Equal(t, 422, err.(registry.Error).StatusCode)
+}
+
+func TestMemoryRegistry_CreateSchemaEnforcesCompatibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   registry.CompatibilityLevel
+		schemas []string
+		wantErr bool
+	}{
+		{
+			name:    "Backward",
+			level:   registry.CompatibilityBackward,
+			schemas: []string{userV1, userV2},
+		},
+		{
+			name:    "Backward Incompatible",
+			level:   registry.CompatibilityBackward,
+			schemas: []string{userV1, userV3},
+			wantErr: true,
+		},
+		{
+			name:    "None",
+			level:   registry.CompatibilityNone,
+			schemas: []string{userV1, userV3},
+		},
+		{
+			name:    "Forward",
+			level:   registry.CompatibilityForward,
+			schemas: []string{userV2, userV1},
+		},
+		{
+			name:    "Forward Incompatible",
+			level:   registry.CompatibilityForward,
+			schemas: []string{userV1, `{"type":"record","name":"org.hamba.User","fields":[]}`},
+			wantErr: true,
+		},
+		{
+			name:    "Full Incompatible",
+			level:   registry.CompatibilityFull,
+			schemas: []string{userV2, userV3},
+			wantErr: true,
+		},
+		{
+			name:  "Backward Only Checks Latest",
+			level: registry.CompatibilityBackward,
+			schemas: []string{
+				`{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"int"}]}`,
+				`{"type":"record","name":"org.hamba.User","fields":[]}`,
+				`{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"string","default":"1"}]}`,
+			},
+		},
+		{
+			name:  "Backward Transitive",
+			level: registry.CompatibilityBackwardTransitive,
+			schemas: []string{
+				`{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"int"}]}`,
+				`{"type":"record","name":"org.hamba.User","fields":[]}`,
+				`{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"string","default":"1"}]}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			reg := registry.NewMemoryRegistry()
+			require.NoError(t, reg.SetCompatibilityLevel("user", test.level))
+
+			var err error
+			for _, schema := range test.schemas {
+				if _, _, err = reg.CreateSchema("user", schema); err != nil {
+					break
+				}
+			}
+
+			if test.wantErr {
+				requireCode(t, err, 409)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMemoryRegistry_SetCompatibilityLevel(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+
+	require.NoError(t, reg.SetCompatibilityLevel("", registry.CompatibilityFull))
+	require.NoError(t, reg.SetCompatibilityLevel("user", registry.CompatibilityNone))
+	err := reg.SetCompatibilityLevel("user", "SIDEWAYS")
+
+	requireCode(t, err, 42203)
+	assert.Equal(t, registry.CompatibilityFull, reg.GetCompatibilityLevel(""))
+	assert.Equal(t, registry.CompatibilityFull, reg.GetCompatibilityLevel("other"))
+	assert.Equal(t, registry.CompatibilityNone, reg.GetCompatibilityLevel("user"))
+}
+
+func TestMemoryRegistry_IsRegistered(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	_, _, err := reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	_, _, err = reg.CreateSchema("user", userV2)
+	require.NoError(t, err)
+
+	id, schema, err := reg.IsRegistered("user", userV1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Len(t, schema.(*avro.RecordSchema).Fields(), 1)
+
+	_, _, err = reg.IsRegistered("user", userV3)
+	requireCode(t, err, 40403)
+
+	_, _, err = reg.IsRegistered("unknown", userV1)
+	requireCode(t, err, 40401)
+}
+
+func TestMemoryRegistry_NotFound(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	_, _, err := reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+
+	_, err = reg.GetSchema(42)
+	requireCode(t, err, 40403)
+
+	_, err = reg.GetVersions("unknown")
+	requireCode(t, err, 40401)
+
+	_, err = reg.GetSchemaByVersion("user", 2)
+	requireCode(t, err, 40402)
+
+	_, err = reg.GetLatestSchema("unknown")
+	requireCode(t, err, 40401)
+
+	_, err = reg.GetLatestSchemaInfo("unknown")
+	requireCode(t, err, 40401)
+}
+
+func TestMemoryRegistry_References(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	_, _, err := reg.CreateSchema("kind", `{"type":"enum","name":"org.hamba.Kind","symbols":["A","B"]}`)
+	require.NoError(t, err)
+
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	id, schema, err := reg.CreateSchema("item", `{"type":"record","name":"org.hamba.Item","fields":[{"name":"kind","type":"Kind"}]}`, ref)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, id)
+	assert.Equal(t, "org.hamba.Kind", schema.(*avro.RecordSchema).Fields()[0].Type().(*avro.EnumSchema).FullName())
+	info, err := reg.GetLatestSchemaInfo("item")
+	require.NoError(t, err)
+	assert.Equal(t, []registry.Reference{ref}, info.References)
+
+	_, _, err = reg.CreateSchema("other", `{"type":"array","items":"org.hamba.Kind"}`, registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 5})
+	requireCode(t, err, 42201)
+}
+
+func TestMemoryRegistry_ContextCanceled(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := reg.CreateSchemaContext(ctx, "user", userV1)
+
+	assert.Equal(t, context.Canceled, err)
+	_, err = reg.GetSubjectsContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestMemoryRegistry_RawSchemas(t *testing.T) {
+	reg := registry.NewMemoryRegistry()
+	_, _, err := reg.CreateSchema("user", userV1)
+	require.NoError(t, err)
+
+	schema, refs, err := reg.GetRawSchema(1)
+	require.NoError(t, err)
+	assert.Equal(t, userV1, schema)
+	assert.Empty(t, refs)
+
+	v, err := reg.GetSchemaVersion("user", registry.LatestVersion)
+	require.NoError(t, err)
+	assert.Equal(t, registry.SchemaVersion{Subject: "user", Version: 1, ID: 1, Schema: userV1}, v)
+
+	v, err = reg.LookupSchema("user", userV1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v.Version)
+
+	ok, err := reg.IsCompatible("user", 1, userV2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = reg.IsCompatible("user", 1, userV3)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = reg.GetRawSchema(2)
+	requireCode(t, err, 40403)
+}
@@ -0,0 +1,351 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xl4hub/hamba-avro"
+)
+
+// manifestFile is the name of the manifest of a snapshot directory.
+const manifestFile = "manifest.json"
+
+// snapshotManifest lists the versions stored in a snapshot directory.
+type snapshotManifest struct {
+	Versions []snapshotVersion `json:"versions"`
+}
+
+// snapshotVersion is a version stored in a snapshot directory, its schema
+// stored in File, relative to the directory.
+type snapshotVersion struct {
+	Subject    string      `json:"subject"`
+	Version    int         `json:"version"`
+	ID         int         `json:"id"`
+	File       string      `json:"file"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// versionGetter is a registry returning versions with the schema as registered.
+type versionGetter interface {
+	GetSchemaVersionContext(ctx context.Context, subject string, version int) (SchemaVersion, error)
+}
+
+// importer is a registry registering schemas with a given id and version.
+type importer interface {
+	ImportSchemaContext(
+		ctx context.Context,
+		subject string,
+		id, version int,
+		schema string,
+		references ...Reference,
+	) (avro.Schema, error)
+}
+
+type migrateConfig struct {
+	subjects []string
+	dryRun   bool
+	create   bool
+}
+
+// MigrateFunc is a function used to customize a migration.
+type MigrateFunc func(*migrateConfig)
+
+// WithSubjects only migrates the subjects matching one of the patterns, as matched
+// by path.Match.
+//
+// The subjects referenced by the migrated schemas must be migrated too, or already
+// be in the destination.
+func WithSubjects(patterns ...string) MigrateFunc {
+	return func(cfg *migrateConfig) {
+		cfg.subjects = append(cfg.subjects, patterns...)
+	}
+}
+
+// WithDryRun returns the versions that would be migrated, without writing them.
+func WithDryRun() MigrateFunc {
+	return func(cfg *migrateConfig) {
+		cfg.dryRun = true
+	}
+}
+
+// WithCreate creates the versions in the destination even if it can import schemas,
+// letting it assign their ids and version numbers. This allows migrating to a Client
+// that is not in ModeImport.
+func WithCreate() MigrateFunc {
+	return func(cfg *migrateConfig) {
+		cfg.create = true
+	}
+}
+
+func newMigrateConfig(opts []MigrateFunc) (migrateConfig, error) {
+	var cfg migrateConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	for _, pattern := range cfg.subjects {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return migrateConfig{}, fmt.Errorf("registry: invalid subject pattern %q: %w", pattern, err)
+		}
+	}
+	return cfg, nil
+}
+
+func (cfg migrateConfig) match(subject string) bool {
+	if len(cfg.subjects) == 0 {
+		return true
+	}
+
+	for _, pattern := range cfg.subjects {
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Migrate copies the versions of the subjects of src to dst, returning the copied versions
+// in the order they were written.
+//
+// The versions are read with their ids and schemas as registered if src can return them,
+// such as a Client or a MemoryRegistry. The versions are written in the order they were
+// registered, with their ids and version numbers if dst can import schemas, in which case
+// a Client must be in ModeImport. Otherwise, or WithCreate, they are created in dst, which
+// assigns them their ids and version numbers.
+func Migrate(ctx context.Context, src, dst Registry, opts ...MigrateFunc) ([]SchemaVersion, error) {
+	cfg, err := newMigrateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := readVersions(ctx, src, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.dryRun {
+		return versions, nil
+	}
+	return versions, writeVersions(ctx, dst, versions, cfg)
+}
+
+// Export writes the versions of the subjects of src to a snapshot directory, returning
+// the written versions.
+//
+// Each schema is written to SUBJECT/VERSION.avsc in the directory, and the versions
+// are listed in manifest.json, in the order they were registered.
+func Export(ctx context.Context, src Registry, dir string, opts ...MigrateFunc) ([]SchemaVersion, error) {
+	cfg, err := newMigrateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := readVersions(ctx, src, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.dryRun {
+		return versions, nil
+	}
+	return versions, writeSnapshot(dir, versions)
+}
+
+// Import copies the versions stored in a snapshot directory written by Export to dst,
+// returning the copied versions. The versions are written as by Migrate.
+func Import(ctx context.Context, dir string, dst Registry, opts ...MigrateFunc) ([]SchemaVersion, error) {
+	cfg, err := newMigrateConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := readSnapshot(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.dryRun {
+		return versions, nil
+	}
+	return versions, writeVersions(ctx, dst, versions, cfg)
+}
+
+func readVersions(ctx context.Context, src Registry, cfg migrateConfig) ([]SchemaVersion, error) {
+	subjects, err := src.GetSubjectsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []SchemaVersion
+	for _, subject := range subjects {
+		if !cfg.match(subject) {
+			continue
+		}
+
+		nums, err := src.GetVersionsContext(ctx, subject)
+		if err != nil {
+			return nil, err
+		}
+		for _, num := range nums {
+			v, err := readVersion(ctx, src, subject, num)
+			if err != nil {
+				return nil, fmt.Errorf("registry: reading subject %s version %d: %w", subject, num, err)
+			}
+			versions = append(versions, v)
+		}
+	}
+
+	sortVersions(versions)
+	return versions, nil
+}
+
+func readVersion(ctx context.Context, src Registry, subject string, version int) (SchemaVersion, error) {
+	if getter, ok := src.(versionGetter); ok {
+		return getter.GetSchemaVersionContext(ctx, subject, version)
+	}
+
+	schema, err := src.GetSchemaByVersionContext(ctx, subject, version)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	b, err := jsoniter.Marshal(schema)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	return SchemaVersion{Subject: subject, Version: version, Schema: string(b)}, nil
+}
+
+// sortVersions sorts the versions in the order they were registered, as far as it is known.
+//
+// A version is ordered by the greatest id of the versions of its subject up to it, so the
+// versions of a subject stay in order when a version reuses the id of an older schema.
+func sortVersions(versions []SchemaVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].Subject != versions[j].Subject {
+			return versions[i].Subject < versions[j].Subject
+		}
+		return versions[i].Version < versions[j].Version
+	})
+
+	keys := make(map[SubjectVersion]int, len(versions))
+	for i, v := range versions {
+		key := v.ID
+		if i > 0 && versions[i-1].Subject == v.Subject {
+			prev := versions[i-1]
+			if k := keys[SubjectVersion{Subject: prev.Subject, Version: prev.Version}]; k > key {
+				key = k
+			}
+		}
+		keys[SubjectVersion{Subject: v.Subject, Version: v.Version}] = key
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		ki := keys[SubjectVersion{Subject: versions[i].Subject, Version: versions[i].Version}]
+		kj := keys[SubjectVersion{Subject: versions[j].Subject, Version: versions[j].Version}]
+		if ki != kj {
+			return ki < kj
+		}
+		return versions[i].ID < versions[j].ID
+	})
+}
+
+func writeVersions(ctx context.Context, dst Registry, versions []SchemaVersion, cfg migrateConfig) error {
+	imp, canImport := dst.(importer)
+	canImport = canImport && !cfg.create
+
+	// Created versions may be numbered differently, so references to them are renumbered.
+	renumbered := map[SubjectVersion]int{}
+	for _, v := range versions {
+		if canImport && v.ID > 0 {
+			if _, err := imp.ImportSchemaContext(ctx, v.Subject, v.ID, v.Version, v.Schema, v.References...); err != nil {
+				return fmt.Errorf("registry: importing subject %s version %d: %w", v.Subject, v.Version, err)
+			}
+			continue
+		}
+
+		refs := make([]Reference, len(v.References))
+		for i, ref := range v.References {
+			if num, ok := renumbered[SubjectVersion{Subject: ref.Subject, Version: ref.Version}]; ok {
+				ref.Version = num
+			}
+			refs[i] = ref
+		}
+
+		id, _, err := dst.CreateSchemaContext(ctx, v.Subject, v.Schema, refs...)
+		if err != nil {
+			return fmt.Errorf("registry: creating subject %s version %d: %w", v.Subject, v.Version, err)
+		}
+		if info, err := dst.GetLatestSchemaInfoContext(ctx, v.Subject); err == nil && info.ID == id {
+			renumbered[SubjectVersion{Subject: v.Subject, Version: v.Version}] = info.Version
+		}
+	}
+	return nil
+}
+
+func writeSnapshot(dir string, versions []SchemaVersion) error {
+	manifest := snapshotManifest{Versions: make([]snapshotVersion, 0, len(versions))}
+	for _, v := range versions {
+		file := path.Join(subjectDirName(v.Subject), strconv.Itoa(v.Version)+".avsc")
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(path.Dir(file))), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, filepath.FromSlash(file)), []byte(v.Schema)); err != nil {
+			return err
+		}
+
+		manifest.Versions = append(manifest.Versions, snapshotVersion{
+			Subject:    v.Subject,
+			Version:    v.Version,
+			ID:         v.ID,
+			File:       file,
+			References: v.References,
+		})
+	}
+
+	b, err := jsoniter.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, manifestFile), b)
+}
+
+func readSnapshot(dir string, cfg migrateConfig) ([]SchemaVersion, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest snapshotManifest
+	if err = jsoniter.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("registry: %s: %w", filepath.Join(dir, manifestFile), err)
+	}
+
+	var versions []SchemaVersion
+	for _, v := range manifest.Versions {
+		if !cfg.match(v.Subject) {
+			continue
+		}
+
+		schema, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+v.File))))
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, SchemaVersion{
+			Subject:    v.Subject,
+			Version:    v.Version,
+			ID:         v.ID,
+			Schema:     string(schema),
+			References: v.References,
+		})
+	}
+
+	sortVersions(versions)
+	return versions, nil
+}
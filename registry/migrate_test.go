@@ -0,0 +1,226 @@
+package registry_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro/registry"
+)
+
+const kindSchema = `{"type":"enum","name":"org.hamba.Kind","symbols":["A","B"]}`
+
+// plainRegistry hides the optional methods of a registry.
+type plainRegistry struct {
+	registry.Registry
+}
+
+// newSourceRegistry returns a registry whose ids and versions do not follow each other.
+func newSourceRegistry(t *testing.T) *registry.MemoryRegistry {
+	t.Helper()
+
+	reg := registry.NewMemoryRegistry()
+	_, err := reg.ImportSchema("kind", 3, 1, kindSchema)
+	require.NoError(t, err)
+	_, err = reg.ImportSchema("user", 10, 2, userV1)
+	require.NoError(t, err)
+	_, err = reg.ImportSchema("user", 12, 3, userV2)
+	require.NoError(t, err)
+	_, err = reg.ImportSchema("customer", 10, 1, userV1)
+	require.NoError(t, err)
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	_, err = reg.ImportSchema("item", 11, 1, `{"type":"record","name":"org.hamba.Item","fields":[{"name":"kind","type":"Kind"}]}`, ref)
+	require.NoError(t, err)
+	return reg
+}
+
+func TestMemoryRegistry_ImportSchema(t *testing.T) {
+	reg := newSourceRegistry(t)
+
+	v, err := reg.GetSchemaVersion("user", registry.LatestVersion)
+	require.NoError(t, err)
+	assert.Equal(t, 12, v.ID)
+	assert.Equal(t, 3, v.Version)
+	id, _, err := reg.CreateSchema("other", `"string"`)
+	require.NoError(t, err)
+	assert.Equal(t, 13, id)
+
+	// Importing the same version again has no effect.
+	_, err = reg.ImportSchema("user", 10, 2, userV1)
+	assert.NoError(t, err)
+
+	_, err = reg.ImportSchema("user", 10, 4, userV3)
+	requireCode(t, err, registry.ErrCodeOperationNotPermitted)
+	_, err = reg.ImportSchema("user", 20, 4, userV1)
+	requireCode(t, err, registry.ErrCodeOperationNotPermitted)
+	_, err = reg.ImportSchema("user", 20, 2, userV3)
+	requireCode(t, err, registry.ErrCodeOperationNotPermitted)
+	_, err = reg.ImportSchema("user", 0, 4, userV3)
+	requireCode(t, err, registry.ErrCodeOperationNotPermitted)
+}
+
+func TestMigrate(t *testing.T) {
+	src := newSourceRegistry(t)
+	dst := registry.NewMemoryRegistry()
+
+	got, err := registry.Migrate(context.Background(), src, dst)
+
+	require.NoError(t, err)
+	var order []int
+	for _, v := range got {
+		order = append(order, v.ID)
+	}
+	assert.Equal(t, []int{3, 10, 10, 11, 12}, order)
+
+	for _, v := range got {
+		want, err := dst.GetSchemaVersion(v.Subject, v.Version)
+		require.NoError(t, err)
+		assert.Equal(t, v, want)
+	}
+}
+
+func TestMigrate_CreatesWhenImportIsNotSupported(t *testing.T) {
+	src := newSourceRegistry(t)
+	dst := registry.NewMemoryRegistry()
+
+	_, err := registry.Migrate(context.Background(), src, plainRegistry{dst})
+
+	require.NoError(t, err)
+	versions, err := dst.GetVersions("user")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+	item, err := dst.GetSchemaVersion("item", 1)
+	require.NoError(t, err)
+	kind, err := dst.GetSchemaVersion("kind", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, kind.ID)
+	assert.Equal(t, 3, item.ID)
+	assert.Equal(t, []registry.Reference{{Name: "org.hamba.Kind", Subject: "kind", Version: 1}}, item.References)
+}
+
+func TestMigrate_WithCreate(t *testing.T) {
+	src := newSourceRegistry(t)
+	dst := registry.NewMemoryRegistry()
+
+	_, err := registry.Migrate(context.Background(), src, dst, registry.WithCreate())
+
+	require.NoError(t, err)
+	versions, err := dst.GetVersions("user")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+	kind, err := dst.GetSchemaVersion("kind", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, kind.ID)
+}
+
+func TestMigrate_PlainSource(t *testing.T) {
+	src := registry.NewMemoryRegistry()
+	_, _, err := src.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	_, _, err = src.CreateSchema("user", userV2)
+	require.NoError(t, err)
+	dst := registry.NewMemoryRegistry()
+
+	got, err := registry.Migrate(context.Background(), plainRegistry{src}, dst)
+
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	schema, err := dst.GetSchemaByVersion("user", 2)
+	require.NoError(t, err)
+	want, err := src.GetSchemaByVersion("user", 2)
+	require.NoError(t, err)
+	assert.Equal(t, want.Fingerprint(), schema.Fingerprint())
+}
+
+func TestMigrate_WithSubjects(t *testing.T) {
+	src := newSourceRegistry(t)
+	dst := registry.NewMemoryRegistry()
+
+	got, err := registry.Migrate(context.Background(), src, dst, registry.WithSubjects("us*", "kind"))
+
+	require.NoError(t, err)
+	assert.Len(t, got, 3)
+	subjects, err := dst.GetSubjects()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kind", "user"}, subjects)
+}
+
+func TestMigrate_WithInvalidSubjectPattern(t *testing.T) {
+	_, err := registry.Migrate(context.Background(), newSourceRegistry(t), registry.NewMemoryRegistry(), registry.WithSubjects("["))
+
+	assert.Error(t, err)
+}
+
+func TestMigrate_WithDryRun(t *testing.T) {
+	src := newSourceRegistry(t)
+	dst := registry.NewMemoryRegistry()
+
+	got, err := registry.Migrate(context.Background(), src, dst, registry.WithDryRun())
+
+	require.NoError(t, err)
+	assert.Len(t, got, 5)
+	subjects, err := dst.GetSubjects()
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestMigrate_Incompatible(t *testing.T) {
+	src := registry.NewMemoryRegistry()
+	_, _, err := src.CreateSchema("user", userV3)
+	require.NoError(t, err)
+	dst := registry.NewMemoryRegistry()
+	_, _, err = dst.CreateSchema("user", userV1)
+	require.NoError(t, err)
+
+	_, err = registry.Migrate(context.Background(), src, plainRegistry{dst})
+
+	assert.Error(t, err)
+}
+
+func TestExportImport(t *testing.T) {
+	dir := tempDir(t)
+	src := newSourceRegistry(t)
+
+	exported, err := registry.Export(context.Background(), src, dir)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(dir, "manifest.json"))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "user", "3.avsc"))
+	require.NoError(t, err)
+	assert.Equal(t, userV2, string(b))
+
+	dst := registry.NewMemoryRegistry()
+	imported, err := registry.Import(context.Background(), dir, dst)
+
+	require.NoError(t, err)
+	assert.Equal(t, exported, imported)
+	for _, v := range imported {
+		want, err := dst.GetSchemaVersion(v.Subject, v.Version)
+		require.NoError(t, err)
+		assert.Equal(t, v, want)
+	}
+}
+
+func TestImport_WithSubjectsAndDryRun(t *testing.T) {
+	dir := tempDir(t)
+	_, err := registry.Export(context.Background(), newSourceRegistry(t), dir)
+	require.NoError(t, err)
+	dst := registry.NewMemoryRegistry()
+
+	got, err := registry.Import(context.Background(), dir, dst, registry.WithSubjects("user"), registry.WithDryRun())
+
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	subjects, err := dst.GetSubjects()
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestImport_MissingManifest(t *testing.T) {
+	_, err := registry.Import(context.Background(), tempDir(t), registry.NewMemoryRegistry())
+
+	assert.Error(t, err)
+}
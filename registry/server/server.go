@@ -0,0 +1,399 @@
+/*
+Package server implements a Confluent Schema Registry compliant server.
+
+The server serves the schemas of a Store, such as a registry.MemoryRegistry or a
+registry.FileRegistry, allowing registry clients to be tested without running a
+schema registry. It implements the schema, subject, compatibility, config and mode
+resources of the API: https://docs.confluent.io/current/schema-registry/docs/api.html
+*/
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/registry"
+)
+
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// Store stores the schemas served by a Server.
+type Store interface {
+	registry.Registry
+
+	// GetRawSchema returns the schema with the given id as registered, with its references.
+	GetRawSchema(id int) (string, []registry.Reference, error)
+
+	// GetSchemaVersion returns the version of the subject, or its latest version
+	// if version is registry.LatestVersion.
+	GetSchemaVersion(subject string, version int) (registry.SchemaVersion, error)
+
+	// LookupSchema returns the version of the subject the schema is registered as.
+	LookupSchema(subject, schema string, references ...registry.Reference) (registry.SchemaVersion, error)
+
+	// IsCompatible determines if the schema is compatible with the version of the subject.
+	IsCompatible(subject string, version int, schema string, references ...registry.Reference) (bool, error)
+
+	// ImportSchemaContext registers a schema under a subject with the given id and version.
+	ImportSchemaContext(
+		ctx context.Context,
+		subject string,
+		id, version int,
+		schema string,
+		references ...registry.Reference,
+	) (avro.Schema, error)
+
+	// GetCompatibilityLevel returns the compatibility level of the subject, or the
+	// global compatibility level if the subject is empty.
+	GetCompatibilityLevel(subject string) registry.CompatibilityLevel
+
+	// SetCompatibilityLevel sets the compatibility level of the subject, or the
+	// global compatibility level if the subject is empty.
+	SetCompatibilityLevel(subject string, level registry.CompatibilityLevel) error
+}
+
+type schemaPayload struct {
+	Schema     string               `json:"schema"`
+	References []registry.Reference `json:"references,omitempty"`
+	ID         int                  `json:"id,omitempty"`
+	Version    int                  `json:"version,omitempty"`
+}
+
+type idPayload struct {
+	ID int `json:"id"`
+}
+
+type compatibilityPayload struct {
+	IsCompatible bool `json:"is_compatible"`
+}
+
+type configPayload struct {
+	Compatibility registry.CompatibilityLevel `json:"compatibility"`
+}
+
+type configLevelPayload struct {
+	CompatibilityLevel registry.CompatibilityLevel `json:"compatibilityLevel"`
+}
+
+type modePayload struct {
+	Mode registry.Mode `json:"mode"`
+}
+
+// Server is an HTTP schema registry serving the schemas of a Store.
+//
+// The modes of the registry and its subjects are held by the server, the registry
+// starting in registry.ModeReadWrite. Schemas can only be registered with an id and
+// version in registry.ModeImport, and not at all in registry.ModeReadOnly.
+type Server struct {
+	store Store
+
+	mu sync.RWMutex
+	// modes holds the modes by subject, the registry mode by the empty subject.
+	modes map[string]registry.Mode
+}
+
+// New returns a server serving the schemas of the store.
+func New(store Store) *Server {
+	return &Server{
+		store: store,
+		modes: map[string]registry.Mode{"": registry.ModeReadWrite},
+	}
+}
+
+// mode returns the mode of the subject, or the registry mode if the subject has no mode of its own.
+func (s *Server) mode(subject string) registry.Mode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if mode, ok := s.modes[subject]; ok {
+		return mode
+	}
+	return s.modes[""]
+}
+
+// ServeHTTP serves a registry API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, err := splitPath(r.URL)
+	if err != nil {
+		writeError(w, errNotFound())
+		return
+	}
+
+	switch {
+	case len(path) == 3 && path[0] == "schemas" && path[1] == "ids":
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) { return s.getSchema(path[2]) })
+
+	case len(path) == 1 && path[0] == "subjects":
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) { return s.store.GetSubjects() })
+
+	case len(path) == 2 && path[0] == "subjects":
+		s.handle(w, r, http.MethodPost, func() (interface{}, error) { return s.lookupSchema(r, path[1]) })
+
+	case len(path) == 3 && path[0] == "subjects" && path[2] == "versions":
+		if r.Method == http.MethodPost {
+			s.handle(w, r, http.MethodPost, func() (interface{}, error) { return s.createSchema(r, path[1]) })
+			return
+		}
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) { return s.store.GetVersions(path[1]) })
+
+	case len(path) == 4 && path[0] == "subjects" && path[2] == "versions":
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) { return s.getVersion(path[1], path[3]) })
+
+	case len(path) == 5 && path[0] == "subjects" && path[2] == "versions" && path[4] == "schema":
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) {
+			v, err := s.getVersion(path[1], path[3])
+			if err != nil {
+				return nil, err
+			}
+			return jsoniter.RawMessage(v.Schema), nil
+		})
+
+	case len(path) == 5 && path[0] == "compatibility" && path[1] == "subjects" && path[3] == "versions":
+		s.handle(w, r, http.MethodPost, func() (interface{}, error) { return s.checkCompatibility(r, path[2], path[4]) })
+
+	case len(path) <= 2 && len(path) > 0 && path[0] == "config":
+		subject := ""
+		if len(path) == 2 {
+			subject = path[1]
+		}
+		if r.Method == http.MethodPut {
+			s.handle(w, r, http.MethodPut, func() (interface{}, error) { return s.setConfig(r, subject) })
+			return
+		}
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) {
+			return configLevelPayload{CompatibilityLevel: s.store.GetCompatibilityLevel(subject)}, nil
+		})
+
+	case len(path) <= 2 && len(path) > 0 && path[0] == "mode":
+		subject := ""
+		if len(path) == 2 {
+			subject = path[1]
+		}
+		if r.Method == http.MethodPut {
+			s.handle(w, r, http.MethodPut, func() (interface{}, error) { return s.setMode(r, subject) })
+			return
+		}
+		s.handle(w, r, http.MethodGet, func() (interface{}, error) {
+			return modePayload{Mode: s.mode(subject)}, nil
+		})
+
+	default:
+		writeError(w, errNotFound())
+	}
+}
+
+// handle writes the result of fn as the response if the request method is allowed.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, method string, fn func() (interface{}, error)) {
+	if r.Method != method {
+		writeError(w, registry.Error{
+			StatusCode: http.StatusMethodNotAllowed,
+			Code:       http.StatusMethodNotAllowed,
+			Message:    "HTTP 405 Method Not Allowed",
+		})
+		return
+	}
+
+	v, err := fn()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) getSchema(id string) (interface{}, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errNotFound()
+	}
+
+	schema, refs, err := s.store.GetRawSchema(n)
+	if err != nil {
+		return nil, err
+	}
+	return schemaPayload{Schema: schema, References: refs}, nil
+}
+
+func (s *Server) getVersion(subject, version string) (registry.SchemaVersion, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return registry.SchemaVersion{}, err
+	}
+	return s.store.GetSchemaVersion(subject, v)
+}
+
+func (s *Server) createSchema(r *http.Request, subject string) (interface{}, error) {
+	in, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := s.mode(subject)
+	switch {
+	case mode == registry.ModeReadOnly:
+		return nil, errNotPermitted("Subject " + subject + " is in read-only mode")
+	case in.ID != 0 || in.Version != 0:
+		if mode != registry.ModeImport {
+			return nil, errNotPermitted("Subject " + subject + " is not in import mode")
+		}
+		if _, err = s.store.ImportSchemaContext(r.Context(), subject, in.ID, in.Version, in.Schema, in.References...); err != nil {
+			return nil, err
+		}
+		return idPayload{ID: in.ID}, nil
+	}
+
+	id, _, err := s.store.CreateSchemaContext(r.Context(), subject, in.Schema, in.References...)
+	if err != nil {
+		return nil, err
+	}
+	return idPayload{ID: id}, nil
+}
+
+func (s *Server) lookupSchema(r *http.Request, subject string) (interface{}, error) {
+	in, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.LookupSchema(subject, in.Schema, in.References...)
+}
+
+func (s *Server) checkCompatibility(r *http.Request, subject, version string) (interface{}, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	in, err := readSchema(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.store.IsCompatible(subject, v, in.Schema, in.References...)
+	if err != nil {
+		return nil, err
+	}
+	return compatibilityPayload{IsCompatible: ok}, nil
+}
+
+func (s *Server) setConfig(r *http.Request, subject string) (interface{}, error) {
+	var in configPayload
+	if err := jsoniter.NewDecoder(r.Body).Decode(&in); err != nil {
+		return nil, errBadRequest()
+	}
+
+	if err := s.store.SetCompatibilityLevel(subject, in.Compatibility); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (s *Server) setMode(r *http.Request, subject string) (interface{}, error) {
+	var in modePayload
+	if err := jsoniter.NewDecoder(r.Body).Decode(&in); err != nil {
+		return nil, errBadRequest()
+	}
+
+	switch in.Mode {
+	case registry.ModeReadWrite, registry.ModeReadOnly, registry.ModeImport:
+	default:
+		return nil, registry.Error{
+			StatusCode: http.StatusUnprocessableEntity,
+			Code:       registry.ErrCodeInvalidMode,
+			Message:    "Invalid mode: " + string(in.Mode),
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.modes[subject] = in.Mode
+	return in, nil
+}
+
+func readSchema(r *http.Request) (schemaPayload, error) {
+	var in schemaPayload
+	if err := jsoniter.NewDecoder(r.Body).Decode(&in); err != nil {
+		return schemaPayload{}, errBadRequest()
+	}
+	return in, nil
+}
+
+// splitPath returns the unescaped segments of the url path.
+func splitPath(u *url.URL) ([]string, error) {
+	path := strings.Trim(u.EscapedPath(), "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		var err error
+		if segments[i], err = url.PathUnescape(seg); err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+func parseVersion(version string) (int, error) {
+	if version == "latest" {
+		return registry.LatestVersion, nil
+	}
+
+	v, err := strconv.Atoi(version)
+	if err != nil || v < 1 {
+		return 0, registry.Error{
+			StatusCode: http.StatusUnprocessableEntity,
+			Code:       registry.ErrCodeInvalidVersion,
+			Message: "The specified version '" + version + "' is not a valid version id. " +
+				"Allowed values are between [1, 2^31-1] and the string \"latest\"",
+		}
+	}
+	return v, nil
+}
+
+func errNotFound() error {
+	return registry.Error{StatusCode: http.StatusNotFound, Code: http.StatusNotFound, Message: "HTTP 404 Not Found"}
+}
+
+func errNotPermitted(msg string) error {
+	return registry.Error{
+		StatusCode: http.StatusUnprocessableEntity,
+		Code:       registry.ErrCodeOperationNotPermitted,
+		Message:    msg,
+	}
+}
+
+func errBadRequest() error {
+	return registry.Error{StatusCode: http.StatusBadRequest, Code: http.StatusBadRequest, Message: "Unrecognized request body"}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var regErr registry.Error
+	if !errors.As(err, &regErr) {
+		regErr = registry.Error{
+			StatusCode: http.StatusInternalServerError,
+			Code:       registry.ErrCodeStoreError,
+			Message:    "Error in the backend data store: " + err.Error(),
+		}
+	}
+	writeJSON(w, regErr.StatusCode, regErr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	b, err := jsoniter.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		b = []byte(`{"error_code":50001,"message":"Error in the backend data store"}`)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,330 @@
+package server_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+	"github.com/xl4hub/hamba-avro/registry"
+	"github.com/xl4hub/hamba-avro/registry/server"
+)
+
+const (
+	userV1 = `{"type":"record","name":"org.hamba.User","doc":"A user.","fields":[{"name":"id","type":"long"}]}`
+	userV2 = `{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"long"},{"name":"name","type":"string","default":""}]}`
+	userV3 = `{"type":"record","name":"org.hamba.User","fields":[{"name":"id","type":"long"},{"name":"email","type":"string"}]}`
+)
+
+func newServer(t *testing.T) (*httptest.Server, *registry.Client) {
+	t.Helper()
+
+	s := httptest.NewServer(server.New(registry.NewMemoryRegistry()))
+	t.Cleanup(s.Close)
+
+	client, err := registry.NewClient(s.URL)
+	require.NoError(t, err)
+	return s, client
+}
+
+func send(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.schemaregistry.v1+json", resp.Header.Get("Content-Type"))
+	return resp.StatusCode, string(b)
+}
+
+func requireCode(t *testing.T, err error, status, code int) {
+	t.Helper()
+
+	require.Error(t, err)
+	regErr, ok := err.(registry.Error)
+	require.True(t, ok, "expected a registry.Error, got %T", err)
+	assert.Equal(t, status, regErr.StatusCode)
+	assert.Equal(t, code, regErr.Code)
+}
+
+func TestServer_Client(t *testing.T) {
+	_, client := newServer(t)
+
+	id, schema, err := client.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, "org.hamba.User", schema.(*avro.RecordSchema).FullName())
+	id, _, err = client.CreateSchema("user", userV2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	got, err := client.GetSchema(1)
+	require.NoError(t, err)
+	assert.Equal(t, "A user.", got.(*avro.RecordSchema).Doc())
+
+	subjects, err := client.GetSubjects()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, subjects)
+
+	versions, err := client.GetVersions("user")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+
+	byVersion, err := client.GetSchemaByVersion("user", 1)
+	require.NoError(t, err)
+	assert.Len(t, byVersion.(*avro.RecordSchema).Fields(), 1)
+
+	latest, err := client.GetLatestSchema("user")
+	require.NoError(t, err)
+	assert.Len(t, latest.(*avro.RecordSchema).Fields(), 2)
+
+	info, err := client.GetLatestSchemaInfo("user")
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.ID)
+	assert.Equal(t, 2, info.Version)
+
+	id, _, err = client.IsRegistered("user", userV1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+}
+
+func TestServer_ClientReferences(t *testing.T) {
+	s, client := newServer(t)
+
+	_, _, err := client.CreateSchema("kind", `{"type":"enum","name":"org.hamba.Kind","symbols":["A","B"]}`)
+	require.NoError(t, err)
+	ref := registry.Reference{Name: "org.hamba.Kind", Subject: "kind", Version: 1}
+	id, _, err := client.CreateSchema("item", `{"type":"record","name":"org.hamba.Item","fields":[{"name":"kind","type":"Kind"}]}`, ref)
+	require.NoError(t, err)
+
+	other, err := registry.NewClient(s.URL)
+	require.NoError(t, err)
+	got, err := other.GetSchema(id)
+	require.NoError(t, err)
+	assert.Equal(t, "org.hamba.Kind", got.(*avro.RecordSchema).Fields()[0].Type().(*avro.EnumSchema).FullName())
+}
+
+func TestServer_ClientErrors(t *testing.T) {
+	_, client := newServer(t)
+	_, _, err := client.CreateSchema("user", userV1)
+	require.NoError(t, err)
+
+	_, err = client.GetSchema(42)
+	requireCode(t, err, 404, 40403)
+
+	_, err = client.GetVersions("unknown")
+	requireCode(t, err, 404, 40401)
+
+	_, err = client.GetSchemaByVersion("user", 5)
+	requireCode(t, err, 404, 40402)
+
+	_, _, err = client.CreateSchema("user", userV3)
+	requireCode(t, err, 409, 409)
+
+	_, _, err = client.CreateSchema("user", `{"type":"record"}`)
+	requireCode(t, err, 422, 42201)
+
+	_, _, err = client.IsRegistered("user", userV2)
+	requireCode(t, err, 404, 40403)
+
+	_, err = client.ImportSchema("user", 5, 5, userV2)
+	requireCode(t, err, 422, 42205)
+}
+
+func TestServer_Config(t *testing.T) {
+	s, client := newServer(t)
+
+	status, body := send(t, http.MethodGet, s.URL+"/config", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"compatibilityLevel":"BACKWARD"}`, body)
+
+	status, body = send(t, http.MethodPut, s.URL+"/config/user", `{"compatibility":"NONE"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"compatibility":"NONE"}`, body)
+
+	status, body = send(t, http.MethodGet, s.URL+"/config/user", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"compatibilityLevel":"NONE"}`, body)
+
+	status, body = send(t, http.MethodPut, s.URL+"/config", `{"compatibility":"SIDEWAYS"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, `"error_code":42203`)
+
+	// The compatibility level of the subject is enforced.
+	_, _, err := client.CreateSchema("user", userV1)
+	require.NoError(t, err)
+	_, _, err = client.CreateSchema("user", userV3)
+	assert.NoError(t, err)
+}
+
+func TestServer_Compatibility(t *testing.T) {
+	s, client := newServer(t)
+	_, _, err := client.CreateSchema("user", userV1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		version    string
+		schema     string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "Compatible",
+			version:    "latest",
+			schema:     userV2,
+			wantStatus: http.StatusOK,
+			want:       `{"is_compatible":true}`,
+		},
+		{
+			name:       "Incompatible",
+			version:    "1",
+			schema:     userV3,
+			wantStatus: http.StatusOK,
+			want:       `{"is_compatible":false}`,
+		},
+		{
+			name:       "Invalid Version",
+			version:    "first",
+			schema:     userV2,
+			wantStatus: http.StatusUnprocessableEntity,
+			want:       `"error_code":42202`,
+		},
+		{
+			name:       "Version Not Found",
+			version:    "2",
+			schema:     userV2,
+			wantStatus: http.StatusNotFound,
+			want:       `"error_code":40402`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			body := `{"schema":` + strconv.Quote(test.schema) + `}`
+
+			status, got := send(t, http.MethodPost, s.URL+"/compatibility/subjects/user/versions/"+test.version, body)
+
+			assert.Equal(t, test.wantStatus, status)
+			assert.Contains(t, got, test.want)
+		})
+	}
+}
+
+func TestServer_Routes(t *testing.T) {
+	s, _ := newServer(t)
+	status, _ := send(t, http.MethodPost, s.URL+"/subjects/org%2Fuser/versions", `{"schema":`+strconv.Quote(userV1)+`}`)
+	require.Equal(t, http.StatusOK, status)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "Escaped Subject",
+			method:     http.MethodGet,
+			path:       "/subjects/org%2Fuser/versions/latest",
+			wantStatus: http.StatusOK,
+			want:       `"subject":"org/user"`,
+		},
+		{
+			name:       "Version Schema",
+			method:     http.MethodGet,
+			path:       "/subjects/org%2Fuser/versions/1/schema",
+			wantStatus: http.StatusOK,
+			want:       `"doc":"A user."`,
+		},
+		{
+			name:       "Lookup",
+			method:     http.MethodPost,
+			path:       "/subjects/org%2Fuser",
+			body:       `{"schema":` + strconv.Quote(userV1) + `}`,
+			wantStatus: http.StatusOK,
+			want:       `"version":1`,
+		},
+		{
+			name:       "Invalid Body",
+			method:     http.MethodPost,
+			path:       "/subjects/org%2Fuser/versions",
+			body:       `{"schema":`,
+			wantStatus: http.StatusBadRequest,
+			want:       `"error_code":400`,
+		},
+		{
+			name:       "Unknown Path",
+			method:     http.MethodGet,
+			path:       "/contexts",
+			wantStatus: http.StatusNotFound,
+			want:       `"error_code":404`,
+		},
+		{
+			name:       "Unknown Schema ID",
+			method:     http.MethodGet,
+			path:       "/schemas/ids/one",
+			wantStatus: http.StatusNotFound,
+			want:       `"error_code":404`,
+		},
+		{
+			name:       "Method Not Allowed",
+			method:     http.MethodDelete,
+			path:       "/subjects",
+			wantStatus: http.StatusMethodNotAllowed,
+			want:       `"error_code":405`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			status, got := send(t, test.method, s.URL+test.path, test.body)
+
+			assert.Equal(t, test.wantStatus, status)
+			assert.Contains(t, got, test.want)
+		})
+	}
+}
+
+func TestServer_Mode(t *testing.T) {
+	_, client := newServer(t)
+
+	mode, err := client.GetMode("")
+	require.NoError(t, err)
+	assert.Equal(t, registry.ModeReadWrite, mode)
+
+	require.NoError(t, client.SetMode("user", registry.ModeImport))
+	mode, err = client.GetMode("user")
+	require.NoError(t, err)
+	assert.Equal(t, registry.ModeImport, mode)
+
+	_, err = client.ImportSchema("user", 5, 3, userV1)
+	require.NoError(t, err)
+	info, err := client.GetLatestSchemaInfo("user")
+	require.NoError(t, err)
+	assert.Equal(t, 5, info.ID)
+	assert.Equal(t, 3, info.Version)
+
+	_, err = client.ImportSchema("other", 6, 1, userV2)
+	requireCode(t, err, 422, 42205)
+
+	require.NoError(t, client.SetMode("", registry.ModeReadOnly))
+	_, _, err = client.CreateSchema("other", userV2)
+	requireCode(t, err, 422, 42205)
+
+	err = client.SetMode("", "SIDEWAYS")
+	requireCode(t, err, 422, 42204)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -55,6 +56,16 @@ const (
 	Duration        LogicalType = "duration"
 )
 
+// Order is the sort order of a record field.
+type Order string
+
+// Field order constants.
+const (
+	Asc    Order = "ascending"
+	Desc   Order = "descending"
+	Ignore Order = "ignore"
+)
+
 // FingerprintType is a fingerprinting algorithm.
 type FingerprintType string
 
@@ -72,7 +83,15 @@ var fingerprinters = map[FingerprintType]hash.Hash{
 }
 
 // SchemaCache is a cache of schemas.
+//
+// Parsing into a cache replaces cached schemas of the same name, unless ConflictError
+// is set, so separate caches should be used to isolate schemas that share names.
 type SchemaCache struct {
+	// ConflictError determines if an error will be returned when a schema
+	// is parsed into the cache with the name of a cached schema with a
+	// different canonical form, instead of replacing it.
+	ConflictError bool
+
 	cache concurrent.Map // map[string]Schema
 }
 
@@ -81,6 +100,54 @@ func (c *SchemaCache) Add(name string, schema Schema) {
 	c.cache.Store(name, schema)
 }
 
+// AddChecked adds a schema to the cache with the given name.
+//
+// AddChecked returns an error if a schema with a different canonical form is already
+// cached with the given name, leaving the cached schema in place.
+func (c *SchemaCache) AddChecked(name string, schema Schema) error {
+	v, loaded := c.cache.LoadOrStore(name, schema)
+	if !loaded {
+		return nil
+	}
+
+	return checkConflict(name, v.(Schema), schema)
+}
+
+// checkConflict returns an error if schema does not have the canonical form of the
+// existing schema with the given name.
+func checkConflict(name string, existing, schema Schema) error {
+	if existing != schema && existing.Fingerprint() != schema.Fingerprint() {
+		return fmt.Errorf("avro: schema %s conflicts with the cached schema", name)
+	}
+	return nil
+}
+
+// Remove removes the schema with the given name from the cache.
+func (c *SchemaCache) Remove(name string) {
+	c.cache.Delete(name)
+}
+
+// Names returns the sorted names of the cached schemas.
+func (c *SchemaCache) Names() []string {
+	var names []string
+	c.cache.Range(func(key, _ interface{}) bool {
+		names = append(names, key.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of the cache.
+func (c *SchemaCache) Clone() *SchemaCache {
+	clone := &SchemaCache{ConflictError: c.ConflictError}
+	c.cache.Range(func(key, value interface{}) bool {
+		clone.cache.Store(key, value)
+		return true
+	})
+	return clone
+}
+
 // Get returns the Schema if it exists.
 func (c *SchemaCache) Get(name string) Schema {
 	if v, ok := c.cache.Load(name); ok {
@@ -160,10 +227,51 @@ type LogicalTypeSchema interface {
 	Logical() LogicalSchema
 }
 
+// SchemaFunc represents an configuration function for a schema.
+type SchemaFunc func(cfg *schemaConfig)
+
+type schemaConfig struct {
+	aliases []string
+	doc     string
+	order   Order
+}
+
+// WithAliases sets the aliases of a named schema or field.
+//
+// The aliases of a named schema are resolved relative to its namespace.
+func WithAliases(aliases []string) SchemaFunc {
+	return func(cfg *schemaConfig) {
+		cfg.aliases = aliases
+	}
+}
+
+// WithDoc sets the documentation of a named schema or field.
+func WithDoc(doc string) SchemaFunc {
+	return func(cfg *schemaConfig) {
+		cfg.doc = doc
+	}
+}
+
+// WithOrder sets the sort order of a field.
+func WithOrder(order Order) SchemaFunc {
+	return func(cfg *schemaConfig) {
+		cfg.order = order
+	}
+}
+
+func newSchemaConfig(opts []SchemaFunc) schemaConfig {
+	var cfg schemaConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
 type name struct {
-	name  string
-	space string
-	full  string
+	name    string
+	space   string
+	full    string
+	aliases []string
 }
 
 func newName(n, s string) (name, error) {
@@ -190,6 +298,29 @@ func newName(n, s string) (name, error) {
 	}, nil
 }
 
+// newNamedName creates a name with the given aliases, resolved relative to the namespace of the name.
+func newNamedName(n, s string, aliases []string) (name, error) {
+	nm, err := newName(n, s)
+	if err != nil {
+		return name{}, err
+	}
+
+	if len(aliases) == 0 {
+		return nm, nil
+	}
+
+	nm.aliases = make([]string, len(aliases))
+	for i, alias := range aliases {
+		a, err := newName(alias, nm.space)
+		if err != nil {
+			return name{}, fmt.Errorf("avro: invalid alias %q: %w", alias, err)
+		}
+		nm.aliases[i] = a.full
+	}
+
+	return nm, nil
+}
+
 // Name returns the name of a schema.
 func (n name) Name() string {
 	return n.name
@@ -205,6 +336,11 @@ func (n name) FullName() string {
 	return n.full
 }
 
+// Aliases returns the full qualified aliases of a schema.
+func (n name) Aliases() []string {
+	return n.aliases
+}
+
 type fingerprinter struct {
 	fingerprint atomic.Value   // [32]byte
 	cache       concurrent.Map // map[FingerprintType][]byte
@@ -277,6 +413,16 @@ func (p *properties) Prop(name string) interface{} {
 	return p.props[name]
 }
 
+// Props returns a copy of the properties of the schema.
+func (p *properties) Props() map[string]interface{} {
+	props := make(map[string]interface{}, len(p.props))
+	for k, v := range p.props {
+		props[k] = v
+	}
+
+	return props
+}
+
 // PrimitiveSchema is an Avro primitive type schema.
 type PrimitiveSchema struct {
 	fingerprinter
@@ -335,11 +481,14 @@ type RecordSchema struct {
 
 	isError bool
 	fields  []*Field
+	doc     string
 }
 
 // NewRecordSchema creates a new record schema instance.
-func NewRecordSchema(name, space string, fields []*Field) (*RecordSchema, error) {
-	n, err := newName(name, space)
+func NewRecordSchema(name, space string, fields []*Field, opts ...SchemaFunc) (*RecordSchema, error) {
+	cfg := newSchemaConfig(opts)
+
+	n, err := newNamedName(name, space, cfg.aliases)
 	if err != nil {
 		return nil, err
 	}
@@ -348,22 +497,19 @@ func NewRecordSchema(name, space string, fields []*Field) (*RecordSchema, error)
 		name:       n,
 		properties: properties{reserved: schemaReserved},
 		fields:     fields,
+		doc:        cfg.doc,
 	}, nil
 }
 
 // NewErrorRecordSchema creates a new error record schema instance.
-func NewErrorRecordSchema(name, space string, fields []*Field) (*RecordSchema, error) {
-	n, err := newName(name, space)
+func NewErrorRecordSchema(name, space string, fields []*Field, opts ...SchemaFunc) (*RecordSchema, error) {
+	rec, err := NewRecordSchema(name, space, fields, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RecordSchema{
-		name:       n,
-		properties: properties{reserved: schemaReserved},
-		isError:    true,
-		fields:     fields,
-	}, nil
+	rec.isError = true
+	return rec, nil
 }
 
 // Type returns the type of the schema.
@@ -381,6 +527,11 @@ func (s *RecordSchema) Fields() []*Field {
 	return s.fields
 }
 
+// Doc returns the documentation of a record.
+func (s *RecordSchema) Doc() string {
+	return s.doc
+}
+
 // String returns the canonical form of the schema.
 func (s *RecordSchema) String() string {
 	typ := "record"
@@ -433,10 +584,13 @@ func (s *RecordSchema) FingerprintUsing(typ FingerprintType) ([]byte, error) {
 type Field struct {
 	properties
 
-	name   string
-	typ    Schema
-	hasDef bool
-	def    interface{}
+	name    string
+	aliases []string
+	doc     string
+	typ     Schema
+	order   Order
+	hasDef  bool
+	def     interface{}
 }
 
 type noDef struct{}
@@ -445,15 +599,33 @@ type noDef struct{}
 var NoDefault = noDef{}
 
 // NewField creates a new field instance.
-func NewField(name string, typ Schema, def interface{}) (*Field, error) {
+func NewField(name string, typ Schema, def interface{}, opts ...SchemaFunc) (*Field, error) {
 	if err := validateName(name); err != nil {
 		return nil, err
 	}
 
+	cfg := newSchemaConfig(opts)
+	for _, alias := range cfg.aliases {
+		if err := validateName(alias); err != nil {
+			return nil, fmt.Errorf("avro: invalid alias %q: %w", alias, err)
+		}
+	}
+
+	switch cfg.order {
+	case "":
+		cfg.order = Asc
+	case Asc, Desc, Ignore:
+	default:
+		return nil, fmt.Errorf("avro: field %s has invalid order %q", name, cfg.order)
+	}
+
 	f := &Field{
 		properties: properties{reserved: fieldReserved},
 		name:       name,
+		aliases:    cfg.aliases,
+		doc:        cfg.doc,
 		typ:        typ,
+		order:      cfg.order,
 	}
 
 	if def != NoDefault {
@@ -473,11 +645,26 @@ func (f *Field) Name() string {
 	return f.name
 }
 
+// Aliases returns the aliases of a field.
+func (f *Field) Aliases() []string {
+	return f.aliases
+}
+
+// Doc returns the documentation of a field.
+func (f *Field) Doc() string {
+	return f.doc
+}
+
 // Type returns the schema of a field.
 func (f *Field) Type() Schema {
 	return f.typ
 }
 
+// Order returns the sort order of a field.
+func (f *Field) Order() Order {
+	return f.order
+}
+
 // HasDefault determines if the field has a default value.
 func (f *Field) HasDefault() bool {
 	return f.hasDef
@@ -505,6 +692,7 @@ func (f *Field) MarshalJSON() ([]byte, error) {
 		Name    string      `json:"name"`
 		Type    Schema      `json:"type"`
 		Default interface{} `json:"default,omitempty"`
+		Order   Order       `json:"order,omitempty"`
 	}{
 		Name: f.name,
 		Type: f.typ,
@@ -512,6 +700,9 @@ func (f *Field) MarshalJSON() ([]byte, error) {
 	if f.hasDef {
 		s.Default = f.def
 	}
+	if f.order != Asc {
+		s.Order = f.order
+	}
 	return jsoniter.Marshal(s)
 }
 
@@ -523,11 +714,14 @@ type EnumSchema struct {
 
 	symbols []string
 	def     string
+	doc     string
 }
 
 // NewEnumSchema creates a new enum schema instance.
-func NewEnumSchema(name, namespace string, symbols []string) (*EnumSchema, error) {
-	n, err := newName(name, namespace)
+func NewEnumSchema(name, namespace string, symbols []string, opts ...SchemaFunc) (*EnumSchema, error) {
+	cfg := newSchemaConfig(opts)
+
+	n, err := newNamedName(name, namespace, cfg.aliases)
 	if err != nil {
 		return nil, err
 	}
@@ -545,6 +739,7 @@ func NewEnumSchema(name, namespace string, symbols []string) (*EnumSchema, error
 		name:       n,
 		properties: properties{reserved: schemaReserved},
 		symbols:    symbols,
+		doc:        cfg.doc,
 	}, nil
 }
 
@@ -558,6 +753,11 @@ func (s *EnumSchema) Symbols() []string {
 	return s.symbols
 }
 
+// Doc returns the documentation of an enum.
+func (s *EnumSchema) Doc() string {
+	return s.doc
+}
+
 // String returns the canonical form of the schema.
 func (s *EnumSchema) String() string {
 	symbols := ""
@@ -799,11 +999,14 @@ type FixedSchema struct {
 
 	size    int
 	logical LogicalSchema
+	doc     string
 }
 
 // NewFixedSchema creates a new fixed schema instance.
-func NewFixedSchema(name, namespace string, size int, logical LogicalSchema) (*FixedSchema, error) {
-	n, err := newName(name, namespace)
+func NewFixedSchema(name, namespace string, size int, logical LogicalSchema, opts ...SchemaFunc) (*FixedSchema, error) {
+	cfg := newSchemaConfig(opts)
+
+	n, err := newNamedName(name, namespace, cfg.aliases)
 	if err != nil {
 		return nil, err
 	}
@@ -813,6 +1016,7 @@ func NewFixedSchema(name, namespace string, size int, logical LogicalSchema) (*F
 		properties: properties{reserved: schemaReserved},
 		size:       size,
 		logical:    logical,
+		doc:        cfg.doc,
 	}, nil
 }
 
@@ -831,6 +1035,11 @@ func (s *FixedSchema) Logical() LogicalSchema {
 	return s.logical
 }
 
+// Doc returns the documentation of a fixed.
+func (s *FixedSchema) Doc() string {
+	return s.doc
+}
+
 // String returns the canonical form of the schema.
 func (s *FixedSchema) String() string {
 	size := strconv.Itoa(s.size)
@@ -3,6 +3,7 @@ package avro
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/modern-go/concurrent"
 )
@@ -32,9 +33,89 @@ func NewSchemaCompatibility() *SchemaCompatibility {
 
 // Compatible determines the compatibility if the reader and writer schemas.
 func (c *SchemaCompatibility) Compatible(reader, writer Schema) error {
+	return c.check(reader, writer)
+}
+
+// check determines the compatibility of the reader and writer schemas.
+//
+// Results are cached by schema fingerprints, which do not cover the field aliases used
+// to match reader fields, so readers with field aliases are checked with a fresh cache.
+func (c *SchemaCompatibility) check(reader, writer Schema) error {
+	if hasFieldAliases(reader) {
+		return NewSchemaCompatibility().compatible(reader, writer)
+	}
 	return c.compatible(reader, writer)
 }
 
+var errFieldAliases = errors.New("avro: schema has field aliases")
+
+// fieldAliasVisitor returns an error at the first field with aliases.
+type fieldAliasVisitor struct{}
+
+func (fieldAliasVisitor) Schema(Schema) error {
+	return nil
+}
+
+func (fieldAliasVisitor) Field(_ *RecordSchema, field *Field) error {
+	if len(field.Aliases()) > 0 {
+		return errFieldAliases
+	}
+	return nil
+}
+
+func hasFieldAliases(schema Schema) bool {
+	return Walk(schema, fieldAliasVisitor{}) != nil
+}
+
+// ProtocolCompatible determines if clients using the client protocol can communicate
+// with a server using the server protocol.
+//
+// Every client message must exist on the server with the same one-way setting. The server
+// must be able to read the client requests, and the client must be able to read the server
+// responses and errors.
+func (c *SchemaCompatibility) ProtocolCompatible(server, client *Protocol) error {
+	names := make([]string, 0, len(client.Messages()))
+	for name := range client.Messages() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cm := client.Message(name)
+		sm := server.Message(name)
+		if sm == nil {
+			return fmt.Errorf("message %s is missing in server protocol", name)
+		}
+
+		if cm.OneWay() != sm.OneWay() {
+			return fmt.Errorf("message %s one-way does not match", name)
+		}
+
+		if err := c.check(sm.Request(), cm.Request()); err != nil {
+			return fmt.Errorf("message %s request: %w", name, err)
+		}
+
+		if err := c.check(messageResponse(cm), messageResponse(sm)); err != nil {
+			return fmt.Errorf("message %s response: %w", name, err)
+		}
+
+		if cm.Errors() != nil && sm.Errors() != nil {
+			if err := c.check(cm.Errors(), sm.Errors()); err != nil {
+				return fmt.Errorf("message %s errors: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func messageResponse(msg *Message) Schema {
+	if msg.Response() == nil {
+		return &NullSchema{}
+	}
+	return msg.Response()
+}
+
 func (c *SchemaCompatibility) compatible(reader, writer Schema) error {
 	key := compatKey{reader: reader.Fingerprint(), writer: writer.Fingerprint()}
 	if err, ok := c.cache.Load(key); ok {
@@ -240,5 +321,12 @@ func (c *SchemaCompatibility) getField(a []*Field, f *Field) (*Field, bool) {
 		}
 	}
 
+	// The reader field may have been renamed, in which case the writer uses one of its aliases.
+	for _, field := range a {
+		if c.contains(f.Aliases(), field.Name()) {
+			return field, true
+		}
+	}
+
 	return nil, false
 }
@@ -218,6 +218,12 @@ func TestSchemaCompatibility_Compatible(t *testing.T) {
 			writer:  `{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "a", "type": "int"}]}`,
 			wantErr: true,
 		},
+		{
+			name:    "Record Reader Field Renamed With Alias",
+			reader:  `{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "a", "type": "int"}, {"name": "c", "type": "string", "aliases": ["b"]}]}`,
+			writer:  `{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "a", "type": "int"}, {"name": "b", "type": "string"}]}`,
+			wantErr: false,
+		},
 		{
 			name:    "Ref Dereference",
 			reader:  `{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "a", "type": {"type":"record", "name":"test1", "namespace": "org.hamba.avro", "fields":[{"name": "b", "type": "int"}]}}, {"name": "b", "type": "test1"}]}`,
@@ -279,3 +285,127 @@ func TestSchemaCompatibility_CompatibleUsesCacheWithError(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestSchemaCompatibility_CompatibleCacheRespectsAliases(t *testing.T) {
+	aliased := avro.MustParse(`{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "c", "type": "string", "aliases": ["b"]}]}`)
+	unaliased := avro.MustParse(`{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "c", "type": "string"}]}`)
+	writer := avro.MustParse(`{"type":"record", "name":"test", "namespace": "org.hamba.avro", "fields":[{"name": "b", "type": "string"}]}`)
+	sc := avro.NewSchemaCompatibility()
+
+	assert.NoError(t, sc.Compatible(aliased, writer))
+
+	assert.Error(t, sc.Compatible(unaliased, writer))
+	assert.NoError(t, sc.Compatible(aliased, writer))
+}
+
+func TestSchemaCompatibility_ProtocolCompatible(t *testing.T) {
+	client := `{
+		"protocol": "test",
+		"namespace": "org.hamba.avro",
+		"types": [{"name": "Failure", "type": "error", "fields": [{"name": "reason", "type": "string"}]}],
+		"messages": {
+			"get": {"request": [{"name": "id", "type": "int"}], "response": "int", "errors": ["Failure"]},
+			"log": {"request": [{"name": "msg", "type": "string"}], "one-way": true}
+		}
+	}`
+
+	tests := []struct {
+		name    string
+		server  string
+		wantErr bool
+	}{
+		{
+			name:    "Same Protocol",
+			server:  client,
+			wantErr: false,
+		},
+		{
+			name: "Compatible Evolution",
+			server: `{
+				"protocol": "test",
+				"namespace": "org.hamba.avro",
+				"types": [{"name": "Failure", "type": "error", "fields": [{"name": "reason", "type": "string"}]}],
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "long"}, {"name": "all", "type": "boolean", "default": false}], "response": "int"},
+					"log": {"request": [{"name": "msg", "type": "string"}], "one-way": true},
+					"put": {"request": [{"name": "id", "type": "int"}], "response": "null"}
+				}
+			}`,
+			wantErr: false,
+		},
+		{
+			name: "Missing Message",
+			server: `{
+				"protocol": "test",
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "int"}], "response": "int"}
+				}
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "One Way Mismatch",
+			server: `{
+				"protocol": "test",
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "int"}], "response": "int"},
+					"log": {"request": [{"name": "msg", "type": "string"}], "response": "string"}
+				}
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "Incompatible Request",
+			server: `{
+				"protocol": "test",
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "int"}, {"name": "all", "type": "boolean"}], "response": "int"},
+					"log": {"request": [{"name": "msg", "type": "string"}], "one-way": true}
+				}
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "Incompatible Response",
+			server: `{
+				"protocol": "test",
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "int"}], "response": "long"},
+					"log": {"request": [{"name": "msg", "type": "string"}], "one-way": true}
+				}
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "Incompatible Errors",
+			server: `{
+				"protocol": "test",
+				"namespace": "org.hamba.avro",
+				"types": [{"name": "Other", "type": "error", "fields": [{"name": "reason", "type": "string"}]}],
+				"messages": {
+					"get": {"request": [{"name": "id", "type": "int"}], "response": "int", "errors": ["Other"]},
+					"log": {"request": [{"name": "msg", "type": "string"}], "one-way": true}
+				}
+			}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			c := avro.MustParseProtocol(client)
+			s := avro.MustParseProtocol(test.server)
+			sc := avro.NewSchemaCompatibility()
+
+			err := sc.ProtocolCompatible(s, c)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -165,7 +165,7 @@ func TestSchema_JSON(t *testing.T) {
            {"order":"descending","name":"f2","doc":"Hello","type":"int"}],
  "type":"record", "name":"foo"
 }`,
-			json: `{"name":"foo","type":"record","fields":[{"name":"f1","type":"boolean","default":true},{"name":"f2","type":"int"}]}`,
+			json: `{"name":"foo","type":"record","fields":[{"name":"f1","type":"boolean","default":true},{"name":"f2","type":"int","order":"descending"}]}`,
 		},
 		{
 			input: `{"type":"enum", "name":"foo", "symbols":["A1"]}`,
@@ -0,0 +1,274 @@
+package avro
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// FileSystem is a read-only file system containing schema files.
+//
+// File names are slash separated and relative to the root of the file system.
+type FileSystem interface {
+	// ReadDir returns the entries of the named directory.
+	ReadDir(name string) ([]os.FileInfo, error)
+
+	// ReadFile returns the contents of the named file.
+	ReadFile(name string) ([]byte, error)
+}
+
+type dirFS string
+
+// DirFS returns a file system for the directory tree rooted at dir.
+func DirFS(dir string) FileSystem {
+	return dirFS(dir)
+}
+
+func (d dirFS) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// SchemaSet is a set of named schemas keyed by full name.
+type SchemaSet struct {
+	schemas map[string]NamedSchema
+}
+
+// Get returns the named schema with the given full name or nil.
+func (s *SchemaSet) Get(name string) NamedSchema {
+	return s.schemas[name]
+}
+
+// Names returns the sorted full names of the schemas in the set.
+func (s *SchemaSet) Names() []string {
+	names := make([]string, 0, len(s.schemas))
+	for name := range s.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseDir parses all schema files with an .avsc extension in the directory tree rooted at dir.
+//
+// See LoadSchemas for details.
+func ParseDir(dir string) (*SchemaSet, error) {
+	return LoadSchemas(DirFS(dir))
+}
+
+// LoadSchemas parses all schema files with an .avsc extension in the file system.
+//
+// The files may reference named types declared in other files, regardless of the order
+// of the files. A named type declared in several files must have the same definition in
+// each of them.
+func LoadSchemas(fsys FileSystem) (*SchemaSet, error) {
+	paths, err := findSchemaFiles(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	files := make([]*schemaFile, len(paths))
+	declaredIn := map[string]*schemaFile{}
+	for i, p := range paths {
+		b, err := fsys.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		f := &schemaFile{path: p, defs: map[string]interface{}{}, refs: map[string]bool{}}
+		if err = jsoniter.Unmarshal(b, &f.json); err != nil {
+			return nil, fmt.Errorf("avro: %s: %w", p, err)
+		}
+		collectNames(f.json, "", f.defs, f.refs)
+
+		for name := range f.defs {
+			if _, ok := declaredIn[name]; !ok {
+				declaredIn[name] = f
+			}
+		}
+		files[i] = f
+	}
+
+	ordered, err := sortSchemaFiles(files, declaredIn)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &SchemaCache{}
+	set := &SchemaSet{schemas: map[string]NamedSchema{}}
+	definedIn := map[string]string{}
+	for _, f := range ordered {
+		if _, err = parseType("", f.json, cache); err != nil {
+			return nil, fmt.Errorf("avro: %s: %w", f.path, err)
+		}
+
+		for name := range f.defs {
+			schema := cache.Get(name)
+			if ref, ok := schema.(*RefSchema); ok {
+				schema = ref.Schema()
+			}
+			named, ok := schema.(NamedSchema)
+			if !ok {
+				continue
+			}
+
+			// Definitions only differing in docs, aliases or how their names are spelled are the same.
+			existing, ok := set.schemas[name]
+			switch {
+			case !ok:
+				set.schemas[name] = named
+				definedIn[name] = f.path
+			case existing.Fingerprint() != named.Fingerprint():
+				return nil, fmt.Errorf("avro: %s is declared with conflicting definitions in %s and %s", name, definedIn[name], f.path)
+			}
+		}
+	}
+
+	return set, nil
+}
+
+type schemaFile struct {
+	path string
+	json interface{}
+
+	// defs holds the named types declared in the file, refs the named types it references.
+	defs map[string]interface{}
+	refs map[string]bool
+}
+
+func findSchemaFiles(fsys FileSystem, dir string) ([]string, error) {
+	infos, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, info := range infos {
+		p := path.Join(dir, info.Name())
+		if info.IsDir() {
+			sub, err := findSchemaFiles(fsys, p)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, sub...)
+			continue
+		}
+
+		if strings.HasSuffix(info.Name(), ".avsc") {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// sortSchemaFiles orders the files so that every file comes after the files declaring the types it references.
+func sortSchemaFiles(files []*schemaFile, declaredIn map[string]*schemaFile) ([]*schemaFile, error) {
+	deps := make(map[*schemaFile][]*schemaFile, len(files))
+	for _, f := range files {
+		for name := range f.refs {
+			if _, ok := f.defs[name]; ok {
+				continue
+			}
+			if dep, ok := declaredIn[name]; ok && dep != f {
+				deps[f] = append(deps[f], dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*schemaFile]int{}
+	ordered := make([]*schemaFile, 0, len(files))
+
+	var visit func(f *schemaFile) error
+	visit = func(f *schemaFile) error {
+		switch state[f] {
+		case visiting:
+			return fmt.Errorf("avro: %s has a circular reference to itself through other schema files", f.path)
+		case visited:
+			return nil
+		}
+
+		state[f] = visiting
+		for _, dep := range deps[f] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[f] = visited
+		ordered = append(ordered, f)
+		return nil
+	}
+
+	for _, f := range files {
+		if err := visit(f); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// collectNames collects the named types declared in a json schema by full name, as well
+// as the full names of the named types it references. Either map may be nil.
+func collectNames(v interface{}, space string, defs map[string]interface{}, refs map[string]bool) {
+	switch val := v.(type) {
+	case string:
+		if refs != nil && !isPrimitiveTypeName(val) {
+			refs[fullName(space, val)] = true
+		}
+
+	case []interface{}:
+		for _, t := range val {
+			collectNames(t, space, defs, refs)
+		}
+
+	case map[string]interface{}:
+		switch typ := val["type"].(type) {
+		case string:
+			switch Type(typ) {
+			case Record, Error, Enum, Fixed:
+				if s, ok := val["namespace"].(string); ok && s != "" {
+					space = s
+				}
+				n, _ := val["name"].(string)
+				if nm, err := newName(n, space); err == nil {
+					if defs != nil {
+						defs[nm.full] = val
+					}
+					space = nm.space
+				}
+			case Array, Map:
+			default:
+				collectNames(typ, space, defs, refs)
+			}
+		default:
+			collectNames(typ, space, defs, refs)
+		}
+
+		if fs, ok := val["fields"].([]interface{}); ok {
+			for _, f := range fs {
+				if fm, ok := f.(map[string]interface{}); ok {
+					collectNames(fm["type"], space, defs, refs)
+				}
+			}
+		}
+		if items, ok := val["items"]; ok {
+			collectNames(items, space, defs, refs)
+		}
+		if values, ok := val["values"]; ok {
+			collectNames(values, space, defs, refs)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package avro_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+func TestParseDir(t *testing.T) {
+	set, err := avro.ParseDir("testdata/schemas")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"org.hamba.common.Status",
+		"org.hamba.shop.Address",
+		"org.hamba.shop.Customer",
+		"org.hamba.shop.Order",
+	}, set.Names())
+
+	order := set.Get("org.hamba.shop.Order")
+	require.NotNil(t, order)
+	assert.Equal(t, avro.Record, order.Type())
+	fields := order.(*avro.RecordSchema).Fields()
+	assert.Equal(t, set.Get("org.hamba.shop.Customer"), fields[1].Type().(*avro.RefSchema).Schema())
+	assert.Equal(t, set.Get("org.hamba.common.Status"), fields[2].Type())
+	assert.Nil(t, set.Get("org.hamba.shop.Unknown"))
+}
+
+func TestParseDir_ConflictingDefinitions(t *testing.T) {
+	_, err := avro.ParseDir("testdata/schemas-conflict")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "org.hamba.common.Status")
+}
+
+func TestParseDir_DirDoesntExist(t *testing.T) {
+	_, err := avro.ParseDir("testdata/missing")
+
+	assert.Error(t, err)
+}
+
+func TestLoadSchemas(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Reversed Order",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "fields": [{"name": "b", "type": "B"}]}`,
+				"b.avsc": `{"type": "fixed", "name": "B", "size": 4}`,
+			},
+			want: []string{"A", "B"},
+		},
+		{
+			name: "Union And Collection References",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "namespace": "org.hamba", "fields": [
+					{"name": "b", "type": ["null", "B"]},
+					{"name": "c", "type": {"type": "array", "items": "C"}},
+					{"name": "d", "type": {"type": "map", "values": "org.other.D"}}
+				]}`,
+				"b.avsc": `{"type": "fixed", "name": "org.hamba.B", "size": 4}`,
+				"c.avsc": `{"type": "enum", "name": "org.hamba.C", "symbols": ["X"]}`,
+				"d.avsc": `{"type": "record", "name": "org.other.D", "fields": [{"name": "c", "type": "org.hamba.C"}]}`,
+			},
+			want: []string{"org.hamba.A", "org.hamba.B", "org.hamba.C", "org.other.D"},
+		},
+		{
+			name: "Same Definitions",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "fields": [
+					{"name": "b", "type": {"type": "enum", "name": "org.hamba.B", "symbols": ["X"]}}
+				]}`,
+				"b.avsc": `{"type": "enum", "name": "B", "namespace": "org.hamba", "doc": "A B.", "symbols": ["X"]}`,
+			},
+			want: []string{"A", "org.hamba.B"},
+		},
+		{
+			name: "Conflicting Nested Definitions",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "fields": [
+					{"name": "b", "type": {"type": "enum", "name": "org.hamba.B", "symbols": ["X"]}}
+				]}`,
+				"b.avsc": `{"type": "enum", "name": "B", "namespace": "org.hamba", "symbols": ["Y"]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown Reference",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "fields": [{"name": "b", "type": "B"}]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Circular Reference",
+			files: map[string]string{
+				"a.avsc": `{"type": "record", "name": "A", "fields": [{"name": "b", "type": "B"}]}`,
+				"b.avsc": `{"type": "record", "name": "B", "fields": [{"name": "a", "type": "A"}]}`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Json",
+			files: map[string]string{
+				"a.avsc": `{"type": "record"`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			set, err := avro.LoadSchemas(mapFS(test.files))
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, set.Names())
+		})
+	}
+}
+
+type mapFS map[string]string
+
+func (m mapFS) ReadDir(name string) ([]os.FileInfo, error) {
+	if name != "." {
+		return nil, errors.New("not found")
+	}
+
+	infos := make([]os.FileInfo, 0, len(m))
+	for file := range m {
+		infos = append(infos, fileInfo(file))
+	}
+	return infos, nil
+}
+
+func (m mapFS) ReadFile(name string) ([]byte, error) {
+	s, ok := m[name]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(s), nil
+}
+
+type fileInfo string
+
+func (f fileInfo) Name() string       { return string(f) }
+func (f fileInfo) Size() int64        { return 0 }
+func (f fileInfo) Mode() os.FileMode  { return 0 }
+func (f fileInfo) ModTime() time.Time { return time.Time{} }
+func (f fileInfo) IsDir() bool        { return false }
+func (f fileInfo) Sys() interface{}   { return nil }
@@ -27,6 +27,15 @@ func Parse(schema string) (Schema, error) {
 	return ParseWithCache(schema, "", DefaultSchemaCache)
 }
 
+// ParseWithConfig parses a schema string using the schema cache of the API, set with
+// Config.SchemaCache. APIs not created by Config.Freeze use DefaultSchemaCache.
+func ParseWithConfig(schema string, api API) (Schema, error) {
+	if cfg, ok := api.(*frozenConfig); ok {
+		return cfg.parse(schema)
+	}
+	return Parse(schema)
+}
+
 // ParseWithCache parses a schema string using the given namespace and  schema cache.
 func ParseWithCache(schema, namespace string, cache *SchemaCache) (Schema, error) {
 	var json interface{}
@@ -190,19 +199,28 @@ func parseRecord(typ Type, namespace string, m map[string]interface{}, cache *Sc
 	}
 	fields := make([]*Field, len(fs))
 
+	opts, err := resolveSchemaOpts(m)
+	if err != nil {
+		return nil, err
+	}
+
 	var rec *RecordSchema
 	switch typ {
 	case Record:
-		rec, err = NewRecordSchema(name, namespace, fields)
+		rec, err = NewRecordSchema(name, namespace, fields, opts...)
 	case Error:
-		rec, err = NewErrorRecordSchema(name, namespace, fields)
+		rec, err = NewErrorRecordSchema(name, namespace, fields, opts...)
 	}
 	if err != nil {
 		return nil, err
 	}
 
+	existing := cache.Get(rec.FullName())
 	cache.Add(rec.FullName(), NewRefSchema(rec))
 
+	// A full name sets the namespace of the fields.
+	namespace = rec.Namespace()
+
 	for k, v := range m {
 		rec.AddProp(k, v)
 	}
@@ -216,6 +234,14 @@ func parseRecord(typ Type, namespace string, m map[string]interface{}, cache *Sc
 		fields[i] = field
 	}
 
+	// The conflict can only be checked once the fields are parsed.
+	if cache.ConflictError && existing != nil {
+		if err = checkConflict(rec.FullName(), existing, rec); err != nil {
+			cache.Add(rec.FullName(), existing)
+			return nil, err
+		}
+	}
+
 	return rec, nil
 }
 
@@ -243,7 +269,20 @@ func parseField(namespace string, v interface{}, cache *SchemaCache) (*Field, er
 		def = NoDefault
 	}
 
-	field, err := NewField(name, typ, def)
+	opts, err := resolveSchemaOpts(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := m["order"]; ok {
+		order, ok := v.(string)
+		if !ok {
+			return nil, errors.New("avro: order must be a string")
+		}
+		opts = append(opts, WithOrder(Order(order)))
+	}
+
+	field, err := NewField(name, typ, def, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -279,12 +318,19 @@ func parseEnum(namespace string, m map[string]interface{}, cache *SchemaCache) (
 		symbols[i] = str
 	}
 
-	enum, err := NewEnumSchema(name, namespace, symbols)
+	opts, err := resolveSchemaOpts(m)
+	if err != nil {
+		return nil, err
+	}
+
+	enum, err := NewEnumSchema(name, namespace, symbols, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	cache.Add(enum.FullName(), enum)
+	if err = addToCache(cache, enum); err != nil {
+		return nil, err
+	}
 
 	for k, v := range m {
 		enum.AddProp(k, v)
@@ -362,12 +408,19 @@ func parseFixed(namespace string, m map[string]interface{}, cache *SchemaCache)
 
 	logical := parseFixedLogicalType(int(size), m)
 
-	fixed, err := NewFixedSchema(name, namespace, int(size), logical)
+	opts, err := resolveSchemaOpts(m)
 	if err != nil {
 		return nil, err
 	}
 
-	cache.Add(fixed.FullName(), fixed)
+	fixed, err := NewFixedSchema(name, namespace, int(size), logical, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = addToCache(cache, fixed); err != nil {
+		return nil, err
+	}
 
 	for k, v := range m {
 		fixed.AddProp(k, v)
@@ -453,3 +506,44 @@ func resolveFullName(m map[string]interface{}) (string, string, error) {
 
 	return name, namespace, nil
 }
+
+func resolveSchemaOpts(m map[string]interface{}) ([]SchemaFunc, error) {
+	var opts []SchemaFunc
+
+	if v, ok := m["doc"]; ok {
+		doc, ok := v.(string)
+		if !ok {
+			return nil, errors.New("avro: doc must be a string")
+		}
+		opts = append(opts, WithDoc(doc))
+	}
+
+	if v, ok := m["aliases"]; ok {
+		as, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.New("avro: aliases must be an array of strings")
+		}
+
+		aliases := make([]string, len(as))
+		for i, a := range as {
+			alias, ok := a.(string)
+			if !ok {
+				return nil, fmt.Errorf("avro: invalid alias: %+v", a)
+			}
+			aliases[i] = alias
+		}
+		opts = append(opts, WithAliases(aliases))
+	}
+
+	return opts, nil
+}
+
+// addToCache adds a named schema to the cache, checking it for conflicts if the cache requires it.
+func addToCache(cache *SchemaCache, schema NamedSchema) error {
+	if cache.ConflictError {
+		return cache.AddChecked(schema.FullName(), schema)
+	}
+
+	cache.Add(schema.FullName(), schema)
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/xl4hub/hamba-avro"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParse_InvalidType(t *testing.T) {
@@ -51,6 +52,103 @@ func TestParseFiles_InvalidSchema(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSchemaCache(t *testing.T) {
+	cache := &avro.SchemaCache{}
+	fixed, _ := avro.NewFixedSchema("test", "org.hamba.avro", 4, nil)
+	enum, _ := avro.NewEnumSchema("other", "org.hamba.avro", []string{"A"})
+
+	cache.Add(fixed.FullName(), fixed)
+	cache.Add(enum.FullName(), enum)
+
+	assert.Equal(t, []string{"org.hamba.avro.other", "org.hamba.avro.test"}, cache.Names())
+	assert.Equal(t, fixed, cache.Get("org.hamba.avro.test"))
+
+	clone := cache.Clone()
+	cache.Remove("org.hamba.avro.test")
+
+	assert.Nil(t, cache.Get("org.hamba.avro.test"))
+	assert.Equal(t, []string{"org.hamba.avro.other"}, cache.Names())
+	assert.Equal(t, fixed, clone.Get("org.hamba.avro.test"))
+	assert.Equal(t, []string{"org.hamba.avro.other", "org.hamba.avro.test"}, clone.Names())
+}
+
+func TestSchemaCache_AddCheckedDetectsConflicts(t *testing.T) {
+	cache := &avro.SchemaCache{}
+	fixed, _ := avro.NewFixedSchema("test", "org.hamba.avro", 4, nil)
+	same, _ := avro.NewFixedSchema("test", "org.hamba.avro", 4, nil)
+	conflict, _ := avro.NewFixedSchema("test", "org.hamba.avro", 8, nil)
+
+	require.NoError(t, cache.AddChecked(fixed.FullName(), fixed))
+
+	assert.NoError(t, cache.AddChecked(same.FullName(), same))
+	assert.Error(t, cache.AddChecked(conflict.FullName(), conflict))
+	assert.Equal(t, fixed, cache.Get("org.hamba.avro.test"))
+}
+
+func TestParseWithCache_ReplacesCachedSchemas(t *testing.T) {
+	cache := &avro.SchemaCache{}
+
+	_, err := avro.ParseWithCache(`{"type": "fixed", "name": "org.hamba.avro.test", "size": 4}`, "", cache)
+	require.NoError(t, err)
+	_, err = avro.ParseWithCache(`{"type": "fixed", "name": "org.hamba.avro.test", "size": 8}`, "", cache)
+	require.NoError(t, err)
+
+	assert.Equal(t, 8, cache.Get("org.hamba.avro.test").(*avro.FixedSchema).Size())
+}
+
+func TestParseWithCache_ConflictError(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		conflict string
+	}{
+		{
+			name:     "Fixed",
+			schema:   `{"type": "fixed", "name": "org.hamba.avro.test", "size": 4}`,
+			conflict: `{"type": "fixed", "name": "org.hamba.avro.test", "size": 8}`,
+		},
+		{
+			name:     "Enum",
+			schema:   `{"type": "enum", "name": "org.hamba.avro.test", "symbols": ["A"]}`,
+			conflict: `{"type": "enum", "name": "org.hamba.avro.test", "symbols": ["B"]}`,
+		},
+		{
+			name:     "Record",
+			schema:   `{"type": "record", "name": "org.hamba.avro.test", "fields": [{"name": "a", "type": "int"}]}`,
+			conflict: `{"type": "record", "name": "org.hamba.avro.test", "fields": [{"name": "a", "type": "long"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			cache := &avro.SchemaCache{ConflictError: true}
+			schema, err := avro.ParseWithCache(test.schema, "", cache)
+			require.NoError(t, err)
+
+			_, err = avro.ParseWithCache(test.schema, "", cache)
+			require.NoError(t, err)
+			_, err = avro.ParseWithCache(test.conflict, "", cache)
+
+			assert.Error(t, err)
+			assert.Equal(t, schema.Fingerprint(), cache.Get("org.hamba.avro.test").Fingerprint())
+		})
+	}
+}
+
+func TestParseWithCache_IsolatesCaches(t *testing.T) {
+	cache1 := &avro.SchemaCache{}
+	cache2 := &avro.SchemaCache{}
+
+	_, err := avro.ParseWithCache(`{"type": "fixed", "name": "org.hamba.avro.test", "size": 4}`, "", cache1)
+	require.NoError(t, err)
+	_, err = avro.ParseWithCache(`{"type": "fixed", "name": "org.hamba.avro.test", "size": 8}`, "", cache2)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, cache1.Get("org.hamba.avro.test").(*avro.FixedSchema).Size())
+	assert.Equal(t, 8, cache2.Get("org.hamba.avro.test").(*avro.FixedSchema).Size())
+}
+
 func TestNullSchema(t *testing.T) {
 	schemas := []string{
 		`null`,
@@ -438,6 +536,152 @@ func TestRecordSchema_HandlesProps(t *testing.T) {
 	assert.Equal(t, "bar2", s.(*avro.RecordSchema).Fields()[0].Prop("foo"))
 }
 
+func TestRecordSchema_HandlesDocAndAliases(t *testing.T) {
+	schm := `
+{
+   "type": "record",
+   "name": "valid_name",
+   "namespace": "org.hamba.avro",
+   "doc": "record docs",
+   "aliases": ["old_name", "org.hamba.other.older_name"],
+   "fields": [
+       {"name": "intField", "type": "int", "doc": "field docs", "aliases": ["old_field"]}
+   ]
+}
+`
+
+	s, err := avro.Parse(schm)
+
+	require.NoError(t, err)
+	rec := s.(*avro.RecordSchema)
+	assert.Equal(t, "record docs", rec.Doc())
+	assert.Equal(t, []string{"org.hamba.avro.old_name", "org.hamba.other.older_name"}, rec.Aliases())
+	assert.Equal(t, "field docs", rec.Fields()[0].Doc())
+	assert.Equal(t, []string{"old_field"}, rec.Fields()[0].Aliases())
+}
+
+func TestRecordSchema_HandlesFieldOrder(t *testing.T) {
+	schm := `
+{
+   "type": "record",
+   "name": "test",
+   "fields": [
+       {"name": "a", "type": "int"},
+       {"name": "b", "type": "int", "order": "descending"},
+       {"name": "c", "type": "int", "order": "ignore"}
+   ]
+}
+`
+
+	s, err := avro.ParseWithCache(schm, "", &avro.SchemaCache{})
+
+	require.NoError(t, err)
+	fields := s.(*avro.RecordSchema).Fields()
+	assert.Equal(t, avro.Asc, fields[0].Order())
+	assert.Equal(t, avro.Desc, fields[1].Order())
+	assert.Equal(t, avro.Ignore, fields[2].Order())
+}
+
+func TestParse_OrderPropertyOnNamedSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{
+			name:   "Record",
+			schema: `{"type":"record", "name":"test", "order": 5, "fields":[{"name": "field", "type": "int"}]}`,
+		},
+		{
+			name:   "Enum",
+			schema: `{"type":"enum", "name":"test", "order": 5, "symbols":["A"]}`,
+		},
+		{
+			name:   "Fixed",
+			schema: `{"type":"fixed", "name":"test", "order": 5, "size": 4}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s, err := avro.ParseWithCache(test.schema, "", &avro.SchemaCache{})
+
+			require.NoError(t, err)
+			assert.Equal(t, 5.0, s.(avro.PropertySchema).Prop("order"))
+		})
+	}
+}
+
+func TestRecordSchema_ValidatesDocAndAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{
+			name:   "Invalid Doc",
+			schema: `{"type":"record", "name":"test", "doc": 1, "fields":[{"name": "field", "type": "int"}]}`,
+		},
+		{
+			name:   "Invalid Aliases",
+			schema: `{"type":"record", "name":"test", "aliases": "test", "fields":[{"name": "field", "type": "int"}]}`,
+		},
+		{
+			name:   "Invalid Alias",
+			schema: `{"type":"record", "name":"test", "aliases": [1], "fields":[{"name": "field", "type": "int"}]}`,
+		},
+		{
+			name:   "Invalid Alias Name",
+			schema: `{"type":"record", "name":"test", "aliases": ["0test"], "fields":[{"name": "field", "type": "int"}]}`,
+		},
+		{
+			name:   "Invalid Field Alias Name",
+			schema: `{"type":"record", "name":"test", "fields":[{"name": "field", "type": "int", "aliases": ["a.b"]}]}`,
+		},
+		{
+			name:   "Invalid Field Order Type",
+			schema: `{"type":"record", "name":"test", "fields":[{"name": "field", "type": "int", "order": 1}]}`,
+		},
+		{
+			name:   "Invalid Field Order",
+			schema: `{"type":"record", "name":"test", "fields":[{"name": "field", "type": "int", "order": "up"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := avro.ParseWithCache(test.schema, "", &avro.SchemaCache{})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewEnumSchema_WithDocAndAliases(t *testing.T) {
+	enum, err := avro.NewEnumSchema("test", "org.hamba.avro", []string{"A"}, avro.WithDoc("docs"), avro.WithAliases([]string{"old"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "docs", enum.Doc())
+	assert.Equal(t, []string{"org.hamba.avro.old"}, enum.Aliases())
+}
+
+func TestNewFixedSchema_WithDocAndAliases(t *testing.T) {
+	fixed, err := avro.NewFixedSchema("test", "org.hamba.avro", 4, nil, avro.WithDoc("docs"), avro.WithAliases([]string{"old"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, "docs", fixed.Doc())
+	assert.Equal(t, []string{"org.hamba.avro.old"}, fixed.Aliases())
+}
+
+func TestRecordSchema_FullNameSetsFieldNamespace(t *testing.T) {
+	schm := `{"type": "record", "name": "org.hamba.avro.Node", "fields": [{"name": "next", "type": ["null", "Node"]}]}`
+
+	s, err := avro.ParseWithCache(schm, "", &avro.SchemaCache{})
+
+	require.NoError(t, err)
+	assert.Equal(t, `["null","org.hamba.avro.Node"]`, s.(*avro.RecordSchema).Fields()[0].Type().String())
+}
+
 func TestRecordSchema_WithReference(t *testing.T) {
 	schm := `
 {
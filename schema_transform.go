@@ -0,0 +1,314 @@
+package avro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transformer transforms the schemas and fields rebuilt by Transform.
+//
+// BaseTransformer can be embedded to only implement part of the interface.
+type Transformer interface {
+	// Schema is called for each schema before it is rebuilt. Returning a
+	// different schema replaces the schema, in which case it is used as is.
+	Schema(schema Schema) (Schema, error)
+
+	// Name returns the full name to rebuild a named schema with.
+	Name(name string) string
+
+	// Field is called for each field of a record before it is rebuilt. It returns
+	// the field to rebuild, or nil to drop the field from the record.
+	Field(record *RecordSchema, field *Field) (*Field, error)
+
+	// Props returns the properties to rebuild a schema or field with.
+	Props(props map[string]interface{}) map[string]interface{}
+}
+
+// BaseTransformer is a Transformer that keeps all schemas, names, fields and properties.
+type BaseTransformer struct{}
+
+// Schema returns the schema.
+func (BaseTransformer) Schema(schema Schema) (Schema, error) {
+	return schema, nil
+}
+
+// Name returns the name.
+func (BaseTransformer) Name(name string) string {
+	return name
+}
+
+// Field returns the field.
+func (BaseTransformer) Field(_ *RecordSchema, field *Field) (*Field, error) {
+	return field, nil
+}
+
+// Props returns the properties.
+func (BaseTransformer) Props(props map[string]interface{}) map[string]interface{} {
+	return props
+}
+
+// RenameNamespace returns a transformer that moves the named schemas in the namespace
+// from, or in a namespace nested in it, to the namespace to.
+func RenameNamespace(from, to string) Transformer {
+	return renameNamespace{from: from, to: to}
+}
+
+type renameNamespace struct {
+	BaseTransformer
+
+	from string
+	to   string
+}
+
+func (t renameNamespace) Name(name string) string {
+	if t.from == "" {
+		return fullName(t.to, name)
+	}
+
+	if !strings.HasPrefix(name, t.from+".") {
+		return name
+	}
+
+	rest := strings.TrimPrefix(name, t.from+".")
+	if t.to == "" {
+		return rest
+	}
+	return t.to + "." + rest
+}
+
+// ReplaceSchema returns a transformer that replaces the named schema with the given full name.
+func ReplaceSchema(name string, schema Schema) Transformer {
+	return replaceSchema{name: name, schema: schema}
+}
+
+type replaceSchema struct {
+	BaseTransformer
+
+	name   string
+	schema Schema
+}
+
+func (t replaceSchema) Schema(schema Schema) (Schema, error) {
+	if named, ok := schema.(NamedSchema); ok && named.FullName() == t.name {
+		return t.schema, nil
+	}
+	return schema, nil
+}
+
+// DropFields returns a transformer that drops the named fields from the record with the given full name.
+func DropFields(record string, fields ...string) Transformer {
+	return dropFields{record: record, fields: fields}
+}
+
+type dropFields struct {
+	BaseTransformer
+
+	record string
+	fields []string
+}
+
+func (t dropFields) Field(record *RecordSchema, field *Field) (*Field, error) {
+	if record.FullName() != t.record {
+		return field, nil
+	}
+
+	for _, name := range t.fields {
+		if field.Name() == name {
+			return nil, nil
+		}
+	}
+	return field, nil
+}
+
+// StripProps returns a transformer that removes all properties.
+func StripProps() Transformer {
+	return stripProps{}
+}
+
+type stripProps struct {
+	BaseTransformer
+}
+
+func (stripProps) Props(map[string]interface{}) map[string]interface{} {
+	return nil
+}
+
+// Transform rebuilds the schema tree, applying the transformer to every schema and field.
+//
+// The schema is not modified, the returned schema is a new schema created with the schema
+// constructors. Recursive schemas are rebuilt as recursive schemas. Field defaults are kept as is.
+func Transform(schema Schema, t Transformer) (Schema, error) {
+	tr := transformer{t: t, named: map[Schema]Schema{}}
+	return tr.transform(schema)
+}
+
+type transformer struct {
+	t Transformer
+
+	// named holds the rebuilt or replaced named schemas.
+	named map[Schema]Schema
+}
+
+func (tr transformer) transform(schema Schema) (Schema, error) {
+	if ref, ok := schema.(*RefSchema); ok {
+		schema = ref.Schema()
+	}
+
+	if s, ok := tr.named[schema]; ok {
+		if rec, ok := s.(*RecordSchema); ok {
+			return NewRefSchema(rec), nil
+		}
+		return s, nil
+	}
+
+	repl, err := tr.t.Schema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if repl != schema {
+		if _, ok := schema.(NamedSchema); ok {
+			tr.named[schema] = repl
+		}
+		return repl, nil
+	}
+
+	switch s := schema.(type) {
+	case *NullSchema:
+		return &NullSchema{}, nil
+
+	case *PrimitiveSchema:
+		return NewPrimitiveSchema(s.Type(), s.Logical()), nil
+
+	case *ArraySchema:
+		items, err := tr.transform(s.Items())
+		if err != nil {
+			return nil, err
+		}
+
+		arr := NewArraySchema(items)
+		tr.addProps(arr, s.Props())
+		return arr, nil
+
+	case *MapSchema:
+		values, err := tr.transform(s.Values())
+		if err != nil {
+			return nil, err
+		}
+
+		ms := NewMapSchema(values)
+		tr.addProps(ms, s.Props())
+		return ms, nil
+
+	case *UnionSchema:
+		types := make([]Schema, len(s.Types()))
+		for i, typ := range s.Types() {
+			if types[i], err = tr.transform(typ); err != nil {
+				return nil, err
+			}
+		}
+		return NewUnionSchema(types)
+
+	case *EnumSchema:
+		n, err := tr.name(s)
+		if err != nil {
+			return nil, err
+		}
+
+		enum, err := NewEnumSchema(n.name, n.space, s.Symbols(), WithDoc(s.Doc()), WithAliases(s.Aliases()))
+		if err != nil {
+			return nil, err
+		}
+		enum.def = s.def
+		tr.addProps(enum, s.Props())
+		tr.named[s] = enum
+		return enum, nil
+
+	case *FixedSchema:
+		n, err := tr.name(s)
+		if err != nil {
+			return nil, err
+		}
+
+		fixed, err := NewFixedSchema(n.name, n.space, s.Size(), s.Logical(), WithDoc(s.Doc()), WithAliases(s.Aliases()))
+		if err != nil {
+			return nil, err
+		}
+		tr.addProps(fixed, s.Props())
+		tr.named[s] = fixed
+		return fixed, nil
+
+	case *RecordSchema:
+		return tr.transformRecord(s)
+	}
+
+	return nil, fmt.Errorf("avro: cannot transform schema of type %s", schema.Type())
+}
+
+func (tr transformer) transformRecord(s *RecordSchema) (Schema, error) {
+	n, err := tr.name(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []*Field
+	for _, f := range s.Fields() {
+		field, err := tr.t.Field(s, f)
+		if err != nil {
+			return nil, err
+		}
+		if field != nil {
+			kept = append(kept, field)
+		}
+	}
+
+	fields := make([]*Field, len(kept))
+	opts := []SchemaFunc{WithDoc(s.Doc()), WithAliases(s.Aliases())}
+
+	var rec *RecordSchema
+	if s.IsError() {
+		rec, err = NewErrorRecordSchema(n.name, n.space, fields, opts...)
+	} else {
+		rec, err = NewRecordSchema(n.name, n.space, fields, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tr.addProps(rec, s.Props())
+
+	// The record is known before its fields are rebuilt, so recursive references resolve to it.
+	tr.named[s] = rec
+
+	for i, f := range kept {
+		typ, err := tr.transform(f.Type())
+		if err != nil {
+			return nil, err
+		}
+
+		field, err := NewField(f.Name(), typ, NoDefault, WithDoc(f.Doc()), WithAliases(f.Aliases()), WithOrder(f.Order()))
+		if err != nil {
+			return nil, err
+		}
+		field.hasDef = f.hasDef
+		field.def = f.def
+		tr.addProps(field, f.Props())
+
+		fields[i] = field
+	}
+
+	return rec, nil
+}
+
+func (tr transformer) name(s NamedSchema) (name, error) {
+	full := tr.t.Name(s.FullName())
+	n, err := newName(full, "")
+	if err != nil {
+		return name{}, fmt.Errorf("avro: invalid name %q for %s: %w", full, s.FullName(), err)
+	}
+	return n, nil
+}
+
+func (tr transformer) addProps(schema PropertySchema, props map[string]interface{}) {
+	for k, v := range tr.t.Props(props) {
+		schema.AddProp(k, v)
+	}
+}
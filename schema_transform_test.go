@@ -0,0 +1,147 @@
+package avro_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+const transformSchema = `{
+	"type": "record",
+	"name": "Node",
+	"namespace": "org.hamba.avro",
+	"doc": "A node",
+	"foo": "bar",
+	"fields": [
+		{"name": "value", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}, "default": "A"},
+		{"name": "hash", "type": {"type": "fixed", "name": "org.hamba.other.Hash", "size": 4}},
+		{"name": "other", "type": "Kind"},
+		{"name": "children", "type": {"type": "array", "items": "Node", "foo": "baz"}, "default": []},
+		{"name": "attrs", "type": {"type": "map", "values": {"type": "long", "logicalType": "timestamp-millis"}}},
+		{"name": "next", "type": ["null", "Node"], "aliases": ["prev"]}
+	]
+}`
+
+func TestTransform_Identity(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	got, err := avro.Transform(schema, avro.BaseTransformer{})
+
+	require.NoError(t, err)
+	assert.NotSame(t, schema, got)
+	assert.Equal(t, schema.String(), got.String())
+	rec := got.(*avro.RecordSchema)
+	assert.Equal(t, "A node", rec.Doc())
+	assert.Equal(t, "bar", rec.Prop("foo"))
+	assert.Equal(t, "baz", rec.Fields()[3].Type().(*avro.ArraySchema).Prop("foo"))
+	assert.Equal(t, "A", rec.Fields()[0].Default())
+	assert.Equal(t, []string{"prev"}, rec.Fields()[5].Aliases())
+	assert.Equal(t, rec, rec.Fields()[3].Type().(*avro.ArraySchema).Items().(*avro.RefSchema).Schema())
+	assert.Same(t, rec.Fields()[0].Type(), rec.Fields()[2].Type())
+}
+
+func TestTransform_KeepsFieldOrder(t *testing.T) {
+	schema, err := avro.ParseWithCache(`{
+		"type": "record",
+		"name": "test",
+		"fields": [
+			{"name": "a", "type": "int"},
+			{"name": "b", "type": "int", "order": "descending"},
+			{"name": "c", "type": "int", "order": "ignore"}
+		]
+	}`, "", &avro.SchemaCache{})
+	require.NoError(t, err)
+
+	got, err := avro.Transform(schema, avro.BaseTransformer{})
+
+	require.NoError(t, err)
+	fields := got.(*avro.RecordSchema).Fields()
+	assert.Equal(t, avro.Asc, fields[0].Order())
+	assert.Equal(t, avro.Desc, fields[1].Order())
+	assert.Equal(t, avro.Ignore, fields[2].Order())
+}
+
+func TestTransform_DoesNotModifySchema(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+	want := schema.String()
+
+	_, err := avro.Transform(schema, avro.RenameNamespace("org.hamba", "com.example"))
+
+	require.NoError(t, err)
+	assert.Equal(t, want, schema.String())
+}
+
+func TestTransform_RenameNamespace(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	got, err := avro.Transform(schema, avro.RenameNamespace("org.hamba.avro", "com.example"))
+
+	require.NoError(t, err)
+	rec := got.(*avro.RecordSchema)
+	assert.Equal(t, "com.example.Node", rec.FullName())
+	assert.Equal(t, "com.example.Kind", rec.Fields()[0].Type().(avro.NamedSchema).FullName())
+	assert.Equal(t, "org.hamba.other.Hash", rec.Fields()[1].Type().(avro.NamedSchema).FullName())
+	assert.Equal(t, `["null","com.example.Node"]`, rec.Fields()[5].Type().String())
+}
+
+func TestTransform_ReplaceSchema(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+	hash, _ := avro.NewFixedSchema("Hash", "org.hamba.other", 16, nil)
+
+	got, err := avro.Transform(schema, avro.ReplaceSchema("org.hamba.other.Hash", hash))
+
+	require.NoError(t, err)
+	assert.Same(t, hash, got.(*avro.RecordSchema).Fields()[1].Type())
+}
+
+func TestTransform_DropFields(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	got, err := avro.Transform(schema, avro.DropFields("org.hamba.avro.Node", "hash", "attrs"))
+
+	require.NoError(t, err)
+	var names []string
+	for _, f := range got.(*avro.RecordSchema).Fields() {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"value", "other", "children", "next"}, names)
+	assert.Len(t, schema.(*avro.RecordSchema).Fields(), 6)
+}
+
+func TestTransform_StripProps(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	got, err := avro.Transform(schema, avro.StripProps())
+
+	require.NoError(t, err)
+	rec := got.(*avro.RecordSchema)
+	assert.Nil(t, rec.Prop("foo"))
+	assert.Nil(t, rec.Fields()[3].Type().(*avro.ArraySchema).Prop("foo"))
+}
+
+type errorTransformer struct {
+	avro.BaseTransformer
+}
+
+func (errorTransformer) Field(*avro.RecordSchema, *avro.Field) (*avro.Field, error) {
+	return nil, errors.New("test")
+}
+
+func TestTransform_ReturnsError(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	_, err := avro.Transform(schema, errorTransformer{})
+
+	assert.Error(t, err)
+}
+
+func TestTransform_InvalidName(t *testing.T) {
+	schema := avro.MustParse(transformSchema)
+
+	_, err := avro.Transform(schema, avro.RenameNamespace("org.hamba.avro", "0invalid"))
+
+	assert.Error(t, err)
+}
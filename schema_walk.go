@@ -0,0 +1,96 @@
+package avro
+
+import (
+	"errors"
+)
+
+// SkipSchema is used as a return value from a Visitor to indicate that the
+// children of the visited schema or field should be skipped. It is never
+// returned as an error by Walk.
+var SkipSchema = errors.New("avro: skip schema")
+
+// Visitor visits the schemas and fields of a schema tree.
+type Visitor interface {
+	// Schema is called for each schema. References are resolved, so named
+	// schemas are visited once, where they are first used.
+	Schema(schema Schema) error
+
+	// Field is called for each field of a record, before its type is visited.
+	Field(record *RecordSchema, field *Field) error
+}
+
+// VisitorFunc is a Visitor that only visits schemas.
+type VisitorFunc func(schema Schema) error
+
+// Schema calls f(schema).
+func (f VisitorFunc) Schema(schema Schema) error {
+	return f(schema)
+}
+
+// Field does nothing.
+func (f VisitorFunc) Field(*RecordSchema, *Field) error {
+	return nil
+}
+
+// Walk walks the schema tree, calling the visitor for every schema and field in the tree.
+//
+// Each schema and field is visited exactly once, including in recursive schemas.
+// Walk stops at the first error returned by the visitor.
+func Walk(schema Schema, v Visitor) error {
+	w := walker{v: v, seen: map[Schema]bool{}}
+	return w.walk(schema)
+}
+
+type walker struct {
+	v    Visitor
+	seen map[Schema]bool
+}
+
+func (w walker) walk(schema Schema) error {
+	if ref, ok := schema.(*RefSchema); ok {
+		schema = ref.Schema()
+	}
+
+	if w.seen[schema] {
+		return nil
+	}
+	w.seen[schema] = true
+
+	if err := w.v.Schema(schema); err != nil {
+		if errors.Is(err, SkipSchema) {
+			return nil
+		}
+		return err
+	}
+
+	switch s := schema.(type) {
+	case *RecordSchema:
+		for _, f := range s.Fields() {
+			if err := w.v.Field(s, f); err != nil {
+				if errors.Is(err, SkipSchema) {
+					continue
+				}
+				return err
+			}
+
+			if err := w.walk(f.Type()); err != nil {
+				return err
+			}
+		}
+
+	case *ArraySchema:
+		return w.walk(s.Items())
+
+	case *MapSchema:
+		return w.walk(s.Values())
+
+	case *UnionSchema:
+		for _, typ := range s.Types() {
+			if err := w.walk(typ); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
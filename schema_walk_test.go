@@ -0,0 +1,94 @@
+package avro_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+type recordingVisitor struct {
+	schemas []string
+	fields  []string
+	skip    map[string]bool
+}
+
+func (v *recordingVisitor) Schema(schema avro.Schema) error {
+	name := string(schema.Type())
+	if named, ok := schema.(avro.NamedSchema); ok {
+		name = named.FullName()
+	}
+	v.schemas = append(v.schemas, name)
+
+	if v.skip[name] {
+		return avro.SkipSchema
+	}
+	return nil
+}
+
+func (v *recordingVisitor) Field(record *avro.RecordSchema, field *avro.Field) error {
+	v.fields = append(v.fields, record.Name()+"."+field.Name())
+
+	if v.skip[field.Name()] {
+		return avro.SkipSchema
+	}
+	return nil
+}
+
+func TestWalk(t *testing.T) {
+	schema := avro.MustParse(`{
+		"type": "record",
+		"name": "Node",
+		"namespace": "org.hamba.avro",
+		"fields": [
+			{"name": "value", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+			{"name": "other", "type": "Kind"},
+			{"name": "children", "type": {"type": "array", "items": "Node"}},
+			{"name": "attrs", "type": {"type": "map", "values": "string"}},
+			{"name": "next", "type": ["null", "Node"]}
+		]
+	}`)
+	v := &recordingVisitor{}
+
+	err := avro.Walk(schema, v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org.hamba.avro.Node", "org.hamba.avro.Kind", "array", "map", "string", "union", "null"}, v.schemas)
+	assert.Equal(t, []string{"Node.value", "Node.other", "Node.children", "Node.attrs", "Node.next"}, v.fields)
+}
+
+func TestWalk_SkipSchema(t *testing.T) {
+	schema := avro.MustParse(`{
+		"type": "record",
+		"name": "Outer",
+		"namespace": "org.hamba.avro",
+		"fields": [
+			{"name": "inner", "type": {"type": "record", "name": "Inner", "fields": [{"name": "a", "type": "int"}]}},
+			{"name": "skipped", "type": {"type": "array", "items": "long"}},
+			{"name": "b", "type": "string"}
+		]
+	}`)
+	v := &recordingVisitor{skip: map[string]bool{"org.hamba.avro.Inner": true, "skipped": true}}
+
+	err := avro.Walk(schema, v)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org.hamba.avro.Outer", "org.hamba.avro.Inner", "string"}, v.schemas)
+	assert.Equal(t, []string{"Outer.inner", "Outer.skipped", "Outer.b"}, v.fields)
+}
+
+func TestWalk_ReturnsError(t *testing.T) {
+	schema := avro.MustParse(`{"type": "array", "items": "int"}`)
+	wantErr := errors.New("test")
+	count := 0
+
+	err := avro.Walk(schema, avro.VisitorFunc(func(schema avro.Schema) error {
+		count++
+		return wantErr
+	}))
+
+	assert.Equal(t, wantErr, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,481 @@
+package avro
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modern-go/reflect2"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	ratType           = reflect.TypeOf(big.Rat{})
+	textMarshalerIfce = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringMapType     = reflect.TypeOf(map[string]interface{}{})
+)
+
+// ValidationError is a value that does not match its schema.
+type ValidationError struct {
+	// Path is the path of the value in the validated value, such as ".orders[3].price",
+	// or "." for the validated value itself.
+	Path string
+
+	// Message describes why the value does not match its schema.
+	Message string
+}
+
+// Error returns the error message.
+func (e ValidationError) Error() string {
+	return "avro: " + e.Path + ": " + e.Message
+}
+
+// ValidationErrors holds the values found by Validate not to match their schema.
+type ValidationErrors []ValidationError
+
+// Error returns the messages of all errors.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks that v can be encoded with the schema.
+//
+// If any values do not match their schema, a ValidationErrors holding all of them is returned.
+func Validate(schema Schema, v interface{}) error {
+	return DefaultConfig.(*frozenConfig).validate(schema, v)
+}
+
+func (c *frozenConfig) validate(schema Schema, v interface{}) error {
+	val := &validator{cfg: c}
+	val.validate(schema, reflect.ValueOf(v), "")
+
+	if len(val.errs) > 0 {
+		return val.errs
+	}
+	return nil
+}
+
+type validator struct {
+	cfg  *frozenConfig
+	errs ValidationErrors
+}
+
+func (v *validator) report(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) unsupported(path string, val reflect.Value, schema Schema) {
+	v.report(path, "%s is unsupported for Avro %s", val.Type().String(), schema.Type())
+}
+
+func (v *validator) validate(schema Schema, val reflect.Value, path string) {
+	if ref, ok := schema.(*RefSchema); ok {
+		schema = ref.Schema()
+	}
+
+	if schema.Type() == String && val.IsValid() && val.Type().Implements(textMarshalerIfce) {
+		return
+	}
+
+	val = indirectInterface(val)
+	if schema.Type() == Union {
+		v.validateUnion(schema.(*UnionSchema), val, path)
+		return
+	}
+
+	if isNil(val) {
+		if schema.Type() != Null {
+			v.report(path, "nil is not allowed for Avro %s", schema.Type())
+		}
+		return
+	}
+	// Pointers are encoded as the value they point to.
+	for val.Kind() == reflect.Ptr {
+		val = indirectInterface(val.Elem())
+		if isNil(val) {
+			v.report(path, "nil is not allowed for Avro %s", schema.Type())
+			return
+		}
+	}
+
+	switch schema.Type() {
+	case Null:
+		v.report(path, "%s is not nil", val.Type().String())
+
+	case Boolean:
+		if val.Kind() != reflect.Bool {
+			v.unsupported(path, val, schema)
+		}
+
+	case Int, Long:
+		v.validateInteger(schema, val, path)
+
+	case Float:
+		if val.Kind() != reflect.Float32 {
+			v.unsupported(path, val, schema)
+		}
+
+	case Double:
+		if val.Kind() != reflect.Float32 && val.Kind() != reflect.Float64 {
+			v.unsupported(path, val, schema)
+		}
+
+	case String:
+		if val.Kind() != reflect.String {
+			v.unsupported(path, val, schema)
+		}
+
+	case Bytes:
+		if val.Type() == ratType && isDecimal(schema) {
+			v.validateDecimal(schema, val, path)
+			return
+		}
+		if val.Kind() != reflect.Slice || val.Type().Elem().Kind() != reflect.Uint8 {
+			v.unsupported(path, val, schema)
+		}
+
+	case Fixed:
+		v.validateFixed(schema.(*FixedSchema), val, path)
+
+	case Enum:
+		if val.Kind() != reflect.String {
+			v.unsupported(path, val, schema)
+			return
+		}
+		for _, sym := range schema.(*EnumSchema).Symbols() {
+			if sym == val.String() {
+				return
+			}
+		}
+		v.report(path, "unknown enum symbol %q", val.String())
+
+	case Array:
+		if val.Kind() != reflect.Slice {
+			v.unsupported(path, val, schema)
+			return
+		}
+		items := schema.(*ArraySchema).Items()
+		for i := 0; i < val.Len(); i++ {
+			v.validate(items, val.Index(i), path+indexPath(i))
+		}
+
+	case Map:
+		if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+			v.unsupported(path, val, schema)
+			return
+		}
+		values := schema.(*MapSchema).Values()
+		for _, key := range sortedKeys(val) {
+			v.validate(values, val.MapIndex(key), path+keyPath(key.String()))
+		}
+
+	case Record:
+		v.validateRecord(schema.(*RecordSchema), val, path)
+
+	default:
+		v.report(path, "schema type %s is unsupported", schema.Type())
+	}
+}
+
+func (v *validator) validateInteger(schema Schema, val reflect.Value, path string) {
+	st := schema.Type()
+	lt := getLogicalType(schema)
+
+	switch {
+	case val.Type() == timeType:
+		if !(st == Int && lt == Date) && !(st == Long && (lt == TimestampMillis || lt == TimestampMicros)) {
+			v.unsupported(path, val, schema)
+		}
+
+	case val.Type() == durationType:
+		switch {
+		case st == Int && lt == TimeMillis:
+			v.checkInt32(val.Int()/int64(time.Millisecond), path)
+		case st == Long && lt == TimeMicros:
+		default:
+			v.unsupported(path, val, schema)
+		}
+
+	case val.Kind() == reflect.Float64:
+		f := val.Float()
+		if f != math.Trunc(f) {
+			v.report(path, "%v is not an integer", f)
+			return
+		}
+		if st == Int {
+			v.checkInt32(int64(f), path)
+		} else if f < math.MinInt64 || f >= math.MaxInt64 {
+			v.report(path, "%v is out of range for Avro long", f)
+		}
+
+	case st == Int && (val.Kind() == reflect.Int8 || val.Kind() == reflect.Int16 || val.Kind() == reflect.Int32):
+
+	case st == Int && val.Kind() == reflect.Int:
+		v.checkInt32(val.Int(), path)
+
+	case st == Long && (val.Kind() == reflect.Int32 || val.Kind() == reflect.Int64):
+
+	default:
+		v.unsupported(path, val, schema)
+	}
+}
+
+func (v *validator) checkInt32(i int64, path string) {
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		v.report(path, "%d is out of range for Avro int", i)
+	}
+}
+
+func (v *validator) validateFixed(schema *FixedSchema, val reflect.Value, path string) {
+	if val.Type() == ratType && isDecimal(schema) {
+		v.validateDecimal(schema, val, path)
+		return
+	}
+
+	if val.Kind() != reflect.Array || val.Type().Elem().Kind() != reflect.Uint8 {
+		v.unsupported(path, val, schema)
+		return
+	}
+	if val.Len() != schema.Size() {
+		v.report(path, "fixed %s has size %d, got %d bytes", schema.FullName(), schema.Size(), val.Len())
+	}
+}
+
+func (v *validator) validateDecimal(schema Schema, val reflect.Value, path string) {
+	dec := getLogicalSchema(schema).(*DecimalLogicalSchema)
+
+	r := new(big.Rat)
+	if val.CanAddr() {
+		r = val.Addr().Interface().(*big.Rat)
+	} else {
+		rat := val.Interface().(big.Rat)
+		r.Set(&rat)
+	}
+
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dec.Scale())), nil)))
+	if !scaled.IsInt() {
+		v.report(path, "%s has more than %d decimal places", r.FloatString(dec.Scale()+1), dec.Scale())
+		return
+	}
+
+	digits := len(new(big.Int).Abs(scaled.Num()).String())
+	if digits > dec.Precision() {
+		v.report(path, "%s exceeds the decimal precision of %d digits", r.FloatString(dec.Scale()), dec.Precision())
+	}
+}
+
+func (v *validator) validateRecord(schema *RecordSchema, val reflect.Value, path string) {
+	var lookup func(name string) (reflect.Value, bool)
+	switch {
+	case val.Kind() == reflect.Struct:
+		fields := structFieldIndices(val.Type(), v.cfg.getTagKey())
+		lookup = func(name string) (reflect.Value, bool) {
+			idx, ok := fields[name]
+			if !ok {
+				return reflect.Value{}, false
+			}
+			return fieldByIndex(val, idx)
+		}
+
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String && val.Type().Elem().Kind() == reflect.Interface:
+		lookup = func(name string) (reflect.Value, bool) {
+			f := val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+			return f, f.IsValid()
+		}
+
+	default:
+		v.unsupported(path, val, schema)
+		return
+	}
+
+	for _, field := range schema.Fields() {
+		f, ok := lookup(field.Name())
+		if !ok {
+			if !field.HasDefault() {
+				v.report(path, "record %s is missing required field %q", schema.FullName(), field.Name())
+			}
+			continue
+		}
+		v.validate(field.Type(), f, path+fieldPath(field.Name()))
+	}
+}
+
+func (v *validator) validateUnion(schema *UnionSchema, val reflect.Value, path string) {
+	if isNil(val) {
+		if _, pos := schema.Types().Get(string(Null)); pos < 0 {
+			v.report(path, "nil is not allowed for Avro union %s", schema.String())
+		}
+		return
+	}
+
+	switch {
+	case val.Type() == stringMapType:
+		// A map holds the value keyed by the name of its union type, or nothing for null.
+		if val.Len() == 0 {
+			v.validateUnion(schema, reflect.Value{}, path)
+			return
+		}
+		if val.Len() > 1 && schema.Nullable() {
+			_, typeIdx := schema.Indices()
+			v.validate(schema.Types()[typeIdx], val, path)
+			return
+		}
+		if val.Len() > 1 {
+			v.report(path, "union map has %d entries", val.Len())
+			return
+		}
+		key := val.MapKeys()[0]
+		typ, _ := schema.Types().Get(key.String())
+		if typ == nil {
+			v.report(path, "unknown union type %s", key.String())
+			return
+		}
+		v.validate(typ, val.MapIndex(key), path)
+		return
+
+	case val.Kind() == reflect.Ptr && schema.Nullable():
+		_, typeIdx := schema.Indices()
+		v.validate(schema.Types()[typeIdx], val.Elem(), path)
+		return
+	}
+
+	names, err := v.cfg.resolver.Name(reflect2.Type2(val.Type()))
+	if err != nil {
+		v.report(path, "unable to resolve %s to a union type", val.Type().String())
+		return
+	}
+	for _, name := range names {
+		if idx := strings.Index(name, ":"); idx > 0 {
+			name = name[:idx]
+		}
+		if typ, _ := schema.Types().Get(name); typ != nil {
+			v.validate(typ, val, path)
+			return
+		}
+	}
+	// A double decoded from JSON may hold an int.
+	if val.Kind() == reflect.Float64 {
+		if typ, _ := schema.Types().Get(string(Int)); typ != nil {
+			v.validate(typ, val, path)
+			return
+		}
+	}
+	v.report(path, "%s matches no type of union %s", val.Type().String(), schema.String())
+}
+
+// indirectInterface returns the value held by an interface, or an invalid value for a nil interface.
+func indirectInterface(val reflect.Value) reflect.Value {
+	for val.IsValid() && val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return reflect.Value{}
+		}
+		val = val.Elem()
+	}
+	return val
+}
+
+func isNil(val reflect.Value) bool {
+	if !val.IsValid() {
+		return true
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return val.IsNil()
+	}
+	return false
+}
+
+func isDecimal(schema Schema) bool {
+	ls := getLogicalSchema(schema)
+	return ls != nil && ls.Type() == Decimal
+}
+
+func sortedKeys(val reflect.Value) []reflect.Value {
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+// structFieldIndices returns the indices of the fields of a struct type by the name
+// they are encoded as, resolving embedded structs as the encoder does.
+func structFieldIndices(typ reflect.Type, tagKey string) map[string][]int {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	fields := map[string][]int{}
+	visited := map[reflect.Type]bool{}
+	next := []embedded{{typ: typ}}
+	for len(next) > 0 {
+		curr := next
+		next = nil
+
+		for _, e := range curr {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				if field.Anonymous {
+					t := field.Type
+					if t.Kind() == reflect.Ptr {
+						t = t.Elem()
+					}
+					if t.Kind() == reflect.Struct {
+						next = append(next, embedded{typ: t, index: index})
+					}
+					continue
+				}
+				if field.PkgPath != "" {
+					continue
+				}
+
+				name := field.Name
+				if tag, ok := field.Tag.Lookup(tagKey); ok {
+					name = tag
+				}
+				if _, ok := fields[name]; !ok {
+					fields[name] = index
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field, or false if it is in a nil embedded struct.
+func fieldByIndex(val reflect.Value, index []int) (reflect.Value, bool) {
+	for i, idx := range index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}, false
+			}
+			val = val.Elem()
+		}
+		val = val.Field(idx)
+	}
+	return val, true
+}
@@ -0,0 +1,309 @@
+package avro_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xl4hub/hamba-avro"
+)
+
+type ValidateOrder struct {
+	ID    int32    `avro:"id"`
+	Price *big.Rat `avro:"price"`
+	Kind  string   `avro:"kind"`
+}
+
+type ValidateBase struct {
+	Name string `avro:"name"`
+}
+
+type ValidateRecord struct {
+	ValidateBase
+
+	Orders []ValidateOrder         `avro:"orders"`
+	Tags   map[string]int          `avro:"tags"`
+	Hash   [4]byte                 `avro:"hash"`
+	Note   *string                 `avro:"note"`
+	Extra  interface{}             `avro:"extra"`
+	Attrs  map[string]interface{}  `avro:"attrs"`
+	Nested map[string]ValidateBase `avro:"nested"`
+}
+
+const validateSchema = `{
+	"type": "record",
+	"name": "test",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "orders", "type": {"type": "array", "items": {
+			"type": "record",
+			"name": "order",
+			"fields": [
+				{"name": "id", "type": "int"},
+				{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 6, "scale": 2}},
+				{"name": "kind", "type": {"type": "enum", "name": "kind", "symbols": ["BUY", "SELL"]}}
+			]
+		}}},
+		{"name": "tags", "type": {"type": "map", "values": "int"}},
+		{"name": "hash", "type": {"type": "fixed", "name": "hash", "size": 4}},
+		{"name": "note", "type": ["null", "string"]},
+		{"name": "extra", "type": ["null", "long", "string"]},
+		{"name": "attrs", "type": {"type": "map", "values": ["null", "int", "string"]}},
+		{"name": "nested", "type": {"type": "map", "values": {
+			"type": "record",
+			"name": "base",
+			"fields": [{"name": "name", "type": "string"}]
+		}}},
+		{"name": "opt", "type": "string", "default": ""}
+	]
+}`
+
+func validRecord() ValidateRecord {
+	note := "note"
+	return ValidateRecord{
+		ValidateBase: ValidateBase{Name: "foo"},
+		Orders: []ValidateOrder{
+			{ID: 1, Price: big.NewRat(1234, 100), Kind: "BUY"},
+			{ID: 2, Price: big.NewRat(-5, 1), Kind: "SELL"},
+		},
+		Tags:   map[string]int{"a": 1},
+		Hash:   [4]byte{1, 2, 3, 4},
+		Note:   &note,
+		Extra:  int64(3),
+		Attrs:  map[string]interface{}{"a": nil, "b": "foo"},
+		Nested: map[string]ValidateBase{"x": {Name: "bar"}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(validateSchema)
+
+	err := avro.Validate(schema, validRecord())
+
+	assert.NoError(t, err)
+}
+
+func TestValidate_ReportsAllErrorsWithPaths(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(validateSchema)
+	rec := validRecord()
+	rec.Orders = append(rec.Orders,
+		ValidateOrder{ID: 3, Price: big.NewRat(1, 1000), Kind: "BUY"},
+		ValidateOrder{ID: 4, Price: big.NewRat(12345678, 1), Kind: "HOLD"},
+	)
+	rec.Tags["b"] = math.MaxInt32 + 1
+	rec.Extra = true
+	rec.Attrs["c"] = 1.5
+	rec.Nested = nil
+	rec.Note = nil
+
+	err := avro.Validate(schema, rec)
+
+	require.Error(t, err)
+	var errs avro.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	var paths []string
+	for _, e := range errs {
+		paths = append(paths, e.Path)
+	}
+	assert.Equal(t, []string{
+		".orders[2].price",
+		".orders[3].price",
+		".orders[3].kind",
+		`.tags["b"]`,
+		".extra",
+		`.attrs["c"]`,
+	}, paths)
+	assert.Contains(t, err.Error(), `avro: .orders[3].kind: unknown enum symbol "HOLD"`)
+}
+
+func TestValidate_MissingField(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(validateSchema)
+	v := map[string]interface{}{"name": "foo"}
+
+	err := avro.Validate(schema, v)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `avro: .: record test is missing required field "orders"`)
+	assert.NotContains(t, err.Error(), `"opt"`)
+}
+
+func TestValidate_Values(t *testing.T) {
+	defer ConfigTeardown()
+
+	tests := []struct {
+		name    string
+		schema  string
+		value   interface{}
+		wantErr string
+	}{
+		{
+			name:   "Null",
+			schema: `"null"`,
+			value:  nil,
+		},
+		{
+			name:    "Null Not Nil",
+			schema:  `"null"`,
+			value:   1,
+			wantErr: "avro: .: int is not nil",
+		},
+		{
+			name:   "Int",
+			schema: `"int"`,
+			value:  int16(1),
+		},
+		{
+			name:    "Int Out Of Range",
+			schema:  `"int"`,
+			value:   math.MinInt32 - 1,
+			wantErr: "avro: .: -2147483649 is out of range for Avro int",
+		},
+		{
+			name:   "Int Float",
+			schema: `"int"`,
+			value:  float64(2),
+		},
+		{
+			name:    "Int Fraction",
+			schema:  `"int"`,
+			value:   1.5,
+			wantErr: "avro: .: 1.5 is not an integer",
+		},
+		{
+			name:    "Long Int",
+			schema:  `"long"`,
+			value:   1,
+			wantErr: "avro: .: int is unsupported for Avro long",
+		},
+		{
+			name:   "Long Timestamp",
+			schema: `{"type": "long", "logicalType": "timestamp-millis"}`,
+			value:  time.Now(),
+		},
+		{
+			name:    "Int Time",
+			schema:  `"int"`,
+			value:   time.Now(),
+			wantErr: "avro: .: time.Time is unsupported for Avro int",
+		},
+		{
+			name:    "Float Double",
+			schema:  `"float"`,
+			value:   1.5,
+			wantErr: "avro: .: float64 is unsupported for Avro float",
+		},
+		{
+			name:   "String Pointer",
+			schema: `"string"`,
+			value:  &[]string{"foo"}[0],
+		},
+		{
+			name:   "String TextMarshaler",
+			schema: `"string"`,
+			value:  time.Now(),
+		},
+		{
+			name:    "String Nil Pointer",
+			schema:  `"string"`,
+			value:   (*int)(nil),
+			wantErr: "avro: .: nil is not allowed for Avro string",
+		},
+		{
+			name:    "Fixed Size",
+			schema:  `{"type": "fixed", "name": "test", "size": 4}`,
+			value:   [3]byte{},
+			wantErr: "avro: .: fixed test has size 4, got 3 bytes",
+		},
+		{
+			name:   "Fixed Decimal",
+			schema: `{"type": "fixed", "name": "test", "size": 6, "logicalType": "decimal", "precision": 4, "scale": 2}`,
+			value:  big.NewRat(9999, 100),
+		},
+		{
+			name:    "Fixed Decimal Precision",
+			schema:  `{"type": "fixed", "name": "test", "size": 6, "logicalType": "decimal", "precision": 4, "scale": 2}`,
+			value:   big.NewRat(100, 1),
+			wantErr: "avro: .: 100.00 exceeds the decimal precision of 4 digits",
+		},
+		{
+			name:    "Bytes Decimal Scale",
+			schema:  `{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`,
+			value:   big.NewRat(1, 3),
+			wantErr: "avro: .: 0.333 has more than 2 decimal places",
+		},
+		{
+			name:    "Map Keys",
+			schema:  `{"type": "map", "values": "int"}`,
+			value:   map[int]int{1: 1},
+			wantErr: "avro: .: map[int]int is unsupported for Avro map",
+		},
+		{
+			name:   "Union Map",
+			schema: `["null", "string", "int"]`,
+			value:  map[string]interface{}{"int": 1},
+		},
+		{
+			name:   "Union Empty Map",
+			schema: `["null", "string"]`,
+			value:  map[string]interface{}{},
+		},
+		{
+			name:    "Union Map Unknown Type",
+			schema:  `["null", "string"]`,
+			value:   map[string]interface{}{"long": 1},
+			wantErr: "avro: .: unknown union type long",
+		},
+		{
+			name:    "Union Not Nullable",
+			schema:  `["string", "int"]`,
+			value:   nil,
+			wantErr: `avro: .: nil is not allowed for Avro union ["string","int"]`,
+		},
+		{
+			name:   "Union Float Int",
+			schema: `["null", "int"]`,
+			value:  float64(1),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := avro.Parse(test.schema)
+			require.NoError(t, err)
+
+			err = avro.Validate(schema, test.value)
+
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestValidate_MatchesEncoder(t *testing.T) {
+	defer ConfigTeardown()
+
+	schema := avro.MustParse(validateSchema)
+	rec := validRecord()
+
+	require.NoError(t, avro.Validate(schema, rec))
+	_, err := avro.Marshal(schema, rec)
+	assert.NoError(t, err)
+
+	rec.Orders[0].Kind = "HOLD"
+	assert.Error(t, avro.Validate(schema, rec))
+	_, err = avro.Marshal(schema, rec)
+	assert.Error(t, err)
+}